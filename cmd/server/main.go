@@ -28,6 +28,7 @@ package main
 import (
 	"fmt"
 	"github.com/manetu/temporal-yugabyte/driver"
+	_ "github.com/manetu/temporal-yugabyte/driver/ysql" // needed to load the yugabyte-ysql plugin
 	stdlog "log"
 	"os"
 	"path"
@@ -220,6 +221,7 @@ func buildCLI() *cli.App {
 					temporal.WithConfig(cfg),
 					temporal.WithDynamicConfigClient(dynamicConfigClient),
 					temporal.WithCustomDataStoreFactory(&driver.MetaFactory{}),
+					temporal.WithCustomVisibilityStoreFactory(&driver.VisibilityStoreFactory{}),
 					temporal.WithLogger(logger),
 					temporal.InterruptOn(temporal.InterruptCh()),
 					temporal.WithAuthorizer(authorizer),
@@ -20,6 +20,10 @@ set -eu -o pipefail
 : "${YUGABYTE_CA:=}"
 : "${YUGABYTE_REPLICATION_FACTOR:=1}"
 
+# Visibility: es-visibility or yb-visibility
+: "${VISIBILITY_STORE:=es-visibility}"
+: "${VISIBILITY_KEYSPACE:=temporal_visibility}"
+
 # Elasticsearch
 : "${ENABLE_ES:=false}"
 : "${ES_SCHEME:=http}"
@@ -63,7 +67,7 @@ wait_for_yugabyte() {
 
     export YUGABYTE_PASSWORD=${YUGABYTE_PASSWORD}
 
-    until temporal-cassandra-tool --ep "${YUGABYTE_SEEDS}" validate-health; do
+    until temporal-yugabyte-tool --ep "${YUGABYTE_SEEDS}" validate-health; do
         echo 'Waiting for Yugabyte to start up.'
         sleep 1
     done
@@ -81,12 +85,17 @@ setup_yugabyte_schema() {
 
     export YUGABYTE_PASSWORD=${YUGABYTE_PASSWORD}
 
-    SCHEMA_DIR=${TEMPORAL_HOME}/schema/yugabyte/temporal/versioned
     if [[ ${SKIP_DB_CREATE} != true ]]; then
-        temporal-cassandra-tool --ep "${YUGABYTE_SEEDS}" create -k "${KEYSPACE}" --rf "${YUGABYTE_REPLICATION_FACTOR}"
+        temporal-yugabyte-tool --ep "${YUGABYTE_SEEDS}" -k "${KEYSPACE}" create-keyspace --rf "${YUGABYTE_REPLICATION_FACTOR}"
+    fi
+    temporal-yugabyte-tool --ep "${YUGABYTE_SEEDS}" -k "${KEYSPACE}" setup
+
+    if [[ ${VISIBILITY_STORE} == yb-visibility ]]; then
+        if [[ ${SKIP_DB_CREATE} != true ]]; then
+            temporal-yugabyte-tool --ep "${YUGABYTE_SEEDS}" -k "${VISIBILITY_KEYSPACE}" --schema-name visibility create-keyspace --rf "${YUGABYTE_REPLICATION_FACTOR}"
+        fi
+        temporal-yugabyte-tool --ep "${YUGABYTE_SEEDS}" -k "${VISIBILITY_KEYSPACE}" --schema-name visibility setup
     fi
-    temporal-cassandra-tool --ep "${YUGABYTE_SEEDS}" -k "${KEYSPACE}" setup-schema -v 0.0
-    temporal-cassandra-tool --ep "${YUGABYTE_SEEDS}" -k "${KEYSPACE}" update-schema -d "${SCHEMA_DIR}"
 }
 
 # === Elasticsearch functions ===
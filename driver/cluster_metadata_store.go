@@ -26,15 +26,16 @@
 package driver
 
 import (
+	"bytes"
 	"context"
 	"github.com/manetu/temporal-yugabyte/utils/gocql"
 	"net"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/pborman/uuid"
 	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/server/common/convert"
 	"go.temporal.io/server/common/log"
 	p "go.temporal.io/server/common/persistence"
 )
@@ -53,20 +54,19 @@ const (
 	templateDeleteClusterMetadata = `DELETE FROM cluster_metadata_info WHERE metadata_partition = ? AND cluster_name= ?`
 
 	// ****** CLUSTER_MEMBERSHIP TABLE ******
+	//
+	// Membership lives in a single partition holding one row per host and role, so reads are served by a
+	// partition scan rather than secondary indexes and the remaining filters are applied as rows are read.
 	templateUpsertActiveClusterMembership = `INSERT INTO cluster_membership (membership_partition, host_id, rpc_address, rpc_port, role, session_start, last_heartbeat, expiration) ` +
-		`VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+		`VALUES (?, ?, ?, ?, ?, ?, ?, ?) USING TTL ?`
 	templateDeleteClusterMembership = `DELETE FROM cluster_membership WHERE membership_partition = ? AND role = ? AND host_id = ?`
 
-	templateGetExpiredClusterMembership = `SELECT membership_partition, role, host_id FROM cluster_membership WHERE expiration < ?`
+	templateGetClusterMembershipExpiry = `SELECT role, host_id, expiration FROM cluster_membership WHERE membership_partition = ?`
 
-	templateGetClusterMembership = `SELECT host_id, rpc_address, rpc_port, role, session_start, last_heartbeat, expiration FROM cluster_membership WHERE membership_partition = ? AND expiration >= ?`
+	templateGetClusterMembership = `SELECT host_id, rpc_address, rpc_port, role, session_start, last_heartbeat, expiration FROM cluster_membership WHERE membership_partition = ?`
 
-	templateWithRoleSuffix           = ` AND role = ?`
-	templateWithHeartbeatSinceSuffix = ` AND last_heartbeat > ?`
-	templateWithRPCAddressSuffix     = ` AND rpc_address = ?`
-	templateWithHostIDSuffix         = ` AND host_id = ?`
-	templateAllowFiltering           = ` ALLOW FILTERING`
-	templateWithSessionSuffix        = ` AND session_start > ?`
+	templateWithRoleSuffix   = ` AND role = ?`
+	templateWithHostIDSuffix = ` AND host_id = ?`
 )
 
 type (
@@ -93,6 +93,7 @@ func (m *ClusterMetadataStore) ListClusterMetadata(
 	ctx context.Context,
 	request *p.InternalListClusterMetadataRequest,
 ) (*p.InternalListClusterMetadataResponse, error) {
+	ctx = gocql.WithOperation(ctx, "ListClusterMetadata")
 	query := m.session.Query(templateListClusterMetadata, constMetadataPartition).WithContext(ctx)
 	iter := query.PageSize(request.PageSize).PageState(request.NextPageToken).Iter()
 
@@ -130,6 +131,7 @@ func (m *ClusterMetadataStore) GetClusterMetadata(
 	ctx context.Context,
 	request *p.InternalGetClusterMetadataRequest,
 ) (*p.InternalGetClusterMetadataResponse, error) {
+	ctx = gocql.WithOperation(ctx, "GetClusterMetadata")
 
 	var clusterMetadata []byte
 	var encoding string
@@ -151,6 +153,7 @@ func (m *ClusterMetadataStore) SaveClusterMetadata(
 	ctx context.Context,
 	request *p.InternalSaveClusterMetadataRequest,
 ) (bool, error) {
+	ctx = gocql.WithOperation(ctx, "SaveClusterMetadata")
 	var query gocql.Query
 	if request.Version == 0 {
 		query = m.session.Query(
@@ -188,6 +191,7 @@ func (m *ClusterMetadataStore) DeleteClusterMetadata(
 	ctx context.Context,
 	request *p.InternalDeleteClusterMetadataRequest,
 ) error {
+	ctx = gocql.WithOperation(ctx, "DeleteClusterMetadata")
 	query := m.session.Query(templateDeleteClusterMetadata, constMetadataPartition, request.ClusterName).WithContext(ctx)
 	if err := query.Exec(); err != nil {
 		return gocql.ConvertError("DeleteClusterMetadata", err)
@@ -195,47 +199,38 @@ func (m *ClusterMetadataStore) DeleteClusterMetadata(
 	return nil
 }
 
+// GetClusterMembers reads the membership partition. Role, and host ID when a role is given, narrow the
+// clustering range on the server; the other filters are evaluated per row, so a page may hold fewer members
+// than requested while NextPageToken is still set.
 func (m *ClusterMetadataStore) GetClusterMembers(
 	ctx context.Context,
 	request *p.GetClusterMembersRequest,
 ) (*p.GetClusterMembersResponse, error) {
+	ctx = gocql.WithOperation(ctx, "GetClusterMembers")
 	var queryString strings.Builder
 	var operands []interface{}
 	queryString.WriteString(templateGetClusterMembership)
 	operands = append(operands, constMembershipPartition)
-	operands = append(operands, time.Now().UTC())
-
-	if request.HostIDEquals != nil {
-		queryString.WriteString(templateWithHostIDSuffix)
-		operands = append(operands, []byte(request.HostIDEquals))
-	}
-
-	if request.RPCAddressEquals != nil {
-		queryString.WriteString(templateWithRPCAddressSuffix)
-		operands = append(operands, request.RPCAddressEquals)
-	}
 
 	if request.RoleEquals != p.All {
 		queryString.WriteString(templateWithRoleSuffix)
 		operands = append(operands, request.RoleEquals)
-	}
-
-	if !request.SessionStartedAfter.IsZero() {
-		queryString.WriteString(templateWithSessionSuffix)
-		operands = append(operands, request.SessionStartedAfter)
-	}
 
-	// LastHeartbeat needs to be the last one as it needs AllowFilteringSuffix
-	if request.LastHeartbeatWithin > 0 {
-		queryString.WriteString(templateWithHeartbeatSinceSuffix)
-		operands = append(operands, time.Now().UTC().Add(-request.LastHeartbeatWithin))
+		if request.HostIDEquals != nil {
+			queryString.WriteString(templateWithHostIDSuffix)
+			operands = append(operands, []byte(request.HostIDEquals))
+		}
 	}
 
-	queryString.WriteString(templateAllowFiltering)
 	query := m.session.Query(queryString.String(), operands...).WithContext(ctx)
-
 	iter := query.PageSize(request.PageSize).PageState(request.NextPageToken).Iter()
 
+	now := time.Now().UTC()
+	var heartbeatSince time.Time
+	if request.LastHeartbeatWithin > 0 {
+		heartbeatSince = now.Add(-request.LastHeartbeatWithin)
+	}
+
 	var clusterMembers []*p.ClusterMember
 
 	cqlHostID := make([]byte, 0, 16)
@@ -247,16 +242,23 @@ func (m *ClusterMetadataStore) GetClusterMembers(
 	expiration := time.Time{}
 
 	for iter.Scan(&cqlHostID, &rpcAddress, &rpcPort, &role, &sessionStart, &lastHeartbeat, &expiration) {
-		member := p.ClusterMember{
-			HostID:        uuid.UUID(cqlHostID),
-			RPCAddress:    rpcAddress,
-			RPCPort:       rpcPort,
-			Role:          role,
-			SessionStart:  sessionStart,
-			LastHeartbeat: lastHeartbeat,
-			RecordExpiry:  expiration,
+		switch {
+		case expiration.Before(now):
+		case request.HostIDEquals != nil && !bytes.Equal(cqlHostID, request.HostIDEquals):
+		case request.RPCAddressEquals != nil && !rpcAddress.Equal(request.RPCAddressEquals):
+		case !request.SessionStartedAfter.IsZero() && !sessionStart.After(request.SessionStartedAfter):
+		case !heartbeatSince.IsZero() && !lastHeartbeat.After(heartbeatSince):
+		default:
+			clusterMembers = append(clusterMembers, &p.ClusterMember{
+				HostID:        uuid.UUID(cqlHostID),
+				RPCAddress:    rpcAddress,
+				RPCPort:       rpcPort,
+				Role:          role,
+				SessionStart:  sessionStart,
+				LastHeartbeat: lastHeartbeat,
+				RecordExpiry:  expiration,
+			})
 		}
-		clusterMembers = append(clusterMembers, &member)
 	}
 
 	var pagingToken []byte
@@ -272,11 +274,14 @@ func (m *ClusterMetadataStore) GetClusterMembers(
 	return &p.GetClusterMembersResponse{ActiveMembers: clusterMembers, NextPageToken: pagingToken}, nil
 }
 
+// UpsertClusterMembership writes the member with a TTL matching its expiry, so rows a host stops
+// refreshing disappear from the partition even if PruneClusterMembership never runs.
 func (m *ClusterMetadataStore) UpsertClusterMembership(
 	ctx context.Context,
 	request *p.UpsertClusterMembershipRequest,
 ) error {
-	expiration := time.Now().Add(request.RecordExpiry)
+	ctx = gocql.WithOperation(ctx, "UpsertClusterMembership")
+	now := time.Now().UTC()
 	query := m.session.Query(
 		templateUpsertActiveClusterMembership,
 		constMembershipPartition,
@@ -285,8 +290,9 @@ func (m *ClusterMetadataStore) UpsertClusterMembership(
 		request.RPCPort,
 		request.Role,
 		request.SessionStart,
-		time.Now().UTC(),
-		expiration,
+		now,
+		now.Add(request.RecordExpiry),
+		convert.Int64Ceil(request.RecordExpiry.Seconds()),
 	).WithContext(ctx)
 	err := query.Exec()
 
@@ -297,47 +303,47 @@ func (m *ClusterMetadataStore) UpsertClusterMembership(
 	return nil
 }
 
+// PruneClusterMembership deletes up to MaxRecordsPruned expired members in a single batch
 func (m *ClusterMetadataStore) PruneClusterMembership(
 	ctx context.Context,
 	request *p.PruneClusterMembershipRequest,
 ) error {
+	ctx = gocql.WithOperation(ctx, "PruneClusterMembership")
 	iter := m.session.Query(
-		templateGetExpiredClusterMembership,
-		time.Now().UTC(),
-	).WithContext(ctx).PageSize(request.MaxRecordsPruned).Iter()
+		templateGetClusterMembershipExpiry,
+		constMembershipPartition,
+	).WithContext(ctx).Iter()
+
+	now := time.Now().UTC()
+	batch := m.session.NewBatch(gocql.UnloggedBatch).WithContext(ctx)
+	pruned := 0
 
-	var partition int
 	role := p.All
-	var hostID string
-
-	results := make(chan error, request.MaxRecordsPruned)
-	wg := sync.WaitGroup{}
-
-	for iter.Scan(&partition, &role, &hostID) {
-		wg.Add(1)
-		go func() {
-			results <- m.session.Query(
-				templateDeleteClusterMembership,
-				partition,
-				role,
-				hostID,
-			).WithContext(ctx).Exec()
-			wg.Done()
-		}()
+	var hostID []byte
+	expiration := time.Time{}
+
+	for pruned < request.MaxRecordsPruned && iter.Scan(&role, &hostID, &expiration) {
+		if !expiration.Before(now) {
+			continue
+		}
+		batch.Query(templateDeleteClusterMembership,
+			constMembershipPartition,
+			role,
+			hostID,
+		)
+		pruned++
 	}
 
-	err := iter.Close()
-	if err != nil {
+	if err := iter.Close(); err != nil {
 		return gocql.ConvertError("PruneClusterMembership", err)
 	}
 
-	wg.Wait()
-	close(results)
+	if pruned == 0 {
+		return nil
+	}
 
-	for result := range results {
-		if result != nil {
-			return gocql.ConvertError("PruneClusterMembership", result)
-		}
+	if err := m.session.ExecuteBatch(batch); err != nil {
+		return gocql.ConvertError("PruneClusterMembership", err)
 	}
 
 	return nil
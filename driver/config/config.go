@@ -26,7 +26,9 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"os"
 	"reflect"
 	"strings"
@@ -71,6 +73,37 @@ type (
 		DisableInitialHostLookup bool `yaml:"disableInitialHostLookup"`
 		// AddressTranslator translates Yugabyte IP addresses, used for cases when IP addresses gocql driver returns are not accessible from the server
 		AddressTranslator *YugabyteAddressTranslator `yaml:"addressTranslator"`
+		// Credentials obtains the password for User from a provider so that it may be rotated without a restart.  Password is ignored when set.
+		Credentials *YugabyteCredentials `yaml:"credentials"`
+		// Retry configures the retry of statements failing with transaction conflicts or other transient errors
+		Retry *YugabyteRetryPolicy `yaml:"retry"`
+		// SlowQuery logs statements which exceed any of the configured thresholds
+		SlowQuery *YugabyteSlowQueryLogging `yaml:"slowQuery"`
+		// Pools gives workloads connection pools of their own (defaults to a single pool shared by all stores)
+		Pools *YugabytePools `yaml:"pools"`
+		// TimerBucketWidth spreads the timers and scheduled history tasks of a shard across partitions each covering
+		// this span of fire times, e.g. 1h (default: disabled).  It must not change while bucketed tasks remain.
+		TimerBucketWidth time.Duration `yaml:"timerBucketWidth"`
+		// SystemTaskBucketSize spreads the transfer, visibility and replication tasks of a shard across partitions each
+		// covering this many task IDs, e.g. 65536 (default: disabled).  It must not change while bucketed tasks remain.
+		SystemTaskBucketSize int `yaml:"systemTaskBucketSize"`
+		// ExecutionMapTables keeps the activity, timer, child execution, request cancel and signal infos, requested
+		// signals and buffered events of a workflow in tables of their own rather than in collections of its
+		// executions row (default: false).  It must not be disabled again once enabled.
+		ExecutionMapTables bool `yaml:"executionMapTables"`
+		// SchemaDriftCheck compares the live schema of the keyspace at startup with the one expected of its schema
+		// version, and logs (warn) or fails on (fail) any difference (default: disabled)
+		SchemaDriftCheck YugabyteSchemaDriftCheck `yaml:"schemaDriftCheck"`
+		// SkipSchemaVersionCheck lets the server start on a keyspace whose schema version is older than the one it
+		// expects, logging a warning instead, e.g. while rolling out a release ahead of its schema update (default: false)
+		SkipSchemaVersionCheck bool `yaml:"skipSchemaVersionCheck"`
+		// VisibilityScanLimit bounds the index rows the Yugabyte visibility store reads to answer one list page or
+		// count, so that queries which most rows fail cannot scan a whole namespace (default: 10000)
+		VisibilityScanLimit int `yaml:"visibilityScanLimit"`
+		// TaskQueueUserDataMigrated stops the matching store reading the task_queue_user_data table of schema
+		// versions before 1.2, once temporal-yugabyte-tool migrate-task-queue-user-data has moved its rows to
+		// task_queue_user_data_v2 (default: false)
+		TaskQueueUserDataMigrated bool `yaml:"taskQueueUserDataMigrated"`
 	}
 
 	// YugabyteStoreConsistency enables you to set the consistency settings for each Yugabyte Persistence Store for Temporal
@@ -78,8 +111,30 @@ type (
 		// Default defines the consistency level for ALL stores.
 		// Defaults to LOCAL_QUORUM and LOCAL_SERIAL if not set
 		Default *YugabyteConsistencySettings `yaml:"default"`
+		// Execution overrides Default for the execution (mutable state and task) store
+		Execution *YugabyteConsistencySettings `yaml:"execution"`
+		// History overrides Default for the history branch and node tables
+		History *YugabyteConsistencySettings `yaml:"history"`
+		// MatchingTask overrides Default for the matching task store
+		MatchingTask *YugabyteConsistencySettings `yaml:"matchingTask"`
+		// Shard overrides Default for the shard store
+		Shard *YugabyteConsistencySettings `yaml:"shard"`
+		// Metadata overrides Default for the namespace metadata store
+		Metadata *YugabyteConsistencySettings `yaml:"metadata"`
+		// ClusterMetadata overrides Default for the cluster metadata and membership store
+		ClusterMetadata *YugabyteConsistencySettings `yaml:"clusterMetadata"`
+		// Queue overrides Default for the queue and queue v2 stores
+		Queue *YugabyteConsistencySettings `yaml:"queue"`
+		// Nexus overrides Default for the nexus endpoint store
+		Nexus *YugabyteConsistencySettings `yaml:"nexus"`
 	}
 
+	// YugabyteStore identifies one of the Temporal persistence stores backed by Yugabyte
+	YugabyteStore string
+
+	// YugabyteSchemaDriftCheck selects what happens when the schema of the keyspace has drifted at startup
+	YugabyteSchemaDriftCheck string
+
 	YugabyteAddressTranslator struct {
 		// Translator defines name of translator implementation to use for Yugabyte address translation
 		Translator string `yaml:"translator"`
@@ -87,6 +142,35 @@ type (
 		Options map[string]string `yaml:"options"`
 	}
 
+	YugabyteCredentials struct {
+		// Provider defines name of credential provider implementation to use: env, file or command
+		Provider string `yaml:"provider"`
+		// Options map of options for credential provider implementation
+		Options map[string]string `yaml:"options"`
+	}
+
+	// YugabyteRetryPolicy configures retries with jittered exponential backoff.  Transaction conflicts are retried for
+	// every statement, other transient errors only for idempotent statements.
+	YugabyteRetryPolicy struct {
+		// MaxAttempts is the number of attempts of a statement, including the first (default: 3, 1 disables retries)
+		MaxAttempts int `yaml:"maxAttempts"`
+		// InitialInterval is the backoff before the first retry, doubling for each subsequent retry (default: 20ms)
+		InitialInterval time.Duration `yaml:"initialInterval"`
+		// MaxInterval caps the backoff between retries (default: 1s)
+		MaxInterval time.Duration `yaml:"maxInterval"`
+	}
+
+	// YugabyteSlowQueryLogging configures the thresholds above which a statement, transaction or batch is logged.
+	// A zero threshold is not enforced.
+	YugabyteSlowQueryLogging struct {
+		// Latency is the time taken by a statement, including retries, e.g. 500ms
+		Latency time.Duration `yaml:"latency"`
+		// Statements is the number of statements in a transaction or batch
+		Statements int `yaml:"statements"`
+		// PayloadBytes is the total size of the values bound to a statement, transaction or batch
+		PayloadBytes int `yaml:"payloadBytes"`
+	}
+
 	// YugabyteConsistencySettings sets the default consistency level for regular & serial queries to Yugabyte.
 	YugabyteConsistencySettings struct {
 		// Consistency sets the default consistency level. Values identical to gocql Consistency values. (defaults to LOCAL_QUORUM if not set).
@@ -96,7 +180,38 @@ type (
 	}
 )
 
-// GetConsistency returns the gosql.Consistency setting from the configuration for the given store type
+// Definition of all stores that accept per-store settings
+const (
+	ExecutionStore       YugabyteStore = "execution"
+	HistoryStore         YugabyteStore = "history"
+	MatchingTaskStore    YugabyteStore = "matchingTask"
+	ShardStore           YugabyteStore = "shard"
+	MetadataStore        YugabyteStore = "metadata"
+	ClusterMetadataStore YugabyteStore = "clusterMetadata"
+	QueueStore           YugabyteStore = "queue"
+	NexusStore           YugabyteStore = "nexus"
+)
+
+// Definition of the schema drift checks
+const (
+	SchemaDriftCheckDisabled YugabyteSchemaDriftCheck = ""
+	SchemaDriftCheckWarn     YugabyteSchemaDriftCheck = "warn"
+	SchemaDriftCheckFail     YugabyteSchemaDriftCheck = "fail"
+)
+
+// Stores lists every store that accepts per-store settings
+var Stores = []YugabyteStore{
+	ExecutionStore,
+	HistoryStore,
+	MatchingTaskStore,
+	ShardStore,
+	MetadataStore,
+	ClusterMetadataStore,
+	QueueStore,
+	NexusStore,
+}
+
+// GetConsistency returns the default gosql.Consistency setting from the configuration
 func (c *YugabyteStoreConsistency) GetConsistency() gocql.Consistency {
 	return gocql.ParseConsistency(c.getConsistencySettings().Consistency)
 }
@@ -110,10 +225,64 @@ func (c *YugabyteStoreConsistency) GetSerialConsistency() gocql.SerialConsistenc
 	return res
 }
 
+// GetStoreConsistency returns the gocql.Consistency setting for the given store, falling back to the default
+func (c *YugabyteStoreConsistency) GetStoreConsistency(store YugabyteStore) gocql.Consistency {
+	return gocql.ParseConsistency(c.getStoreConsistencySettings(store).Consistency)
+}
+
+// GetStoreSerialConsistency returns the gocql.SerialConsistency setting for the given store, falling back to the default
+func (c *YugabyteStoreConsistency) GetStoreSerialConsistency(store YugabyteStore) gocql.SerialConsistency {
+	res, err := parseSerialConsistency(c.getStoreConsistencySettings(store).SerialConsistency)
+	if err != nil {
+		panic(fmt.Sprintf("unable to decode driver serial consistency for store %s: %v", store, err))
+	}
+	return res
+}
+
 func (c *YugabyteStoreConsistency) getConsistencySettings() *YugabyteConsistencySettings {
 	return ensureStoreConsistencyNotNil(c).Default
 }
 
+// getStoreConsistencySettings merges the override for the given store, if any, over the defaults
+func (c *YugabyteStoreConsistency) getStoreConsistencySettings(store YugabyteStore) *YugabyteConsistencySettings {
+	c = ensureStoreConsistencyNotNil(c)
+
+	settings := *c.Default
+	if override := c.storeOverride(store); override != nil {
+		if override.Consistency != "" {
+			settings.Consistency = override.Consistency
+		}
+		if override.SerialConsistency != "" {
+			settings.SerialConsistency = override.SerialConsistency
+		}
+	}
+
+	return &settings
+}
+
+func (c *YugabyteStoreConsistency) storeOverride(store YugabyteStore) *YugabyteConsistencySettings {
+	switch store {
+	case ExecutionStore:
+		return c.Execution
+	case HistoryStore:
+		return c.History
+	case MatchingTaskStore:
+		return c.MatchingTask
+	case ShardStore:
+		return c.Shard
+	case MetadataStore:
+		return c.Metadata
+	case ClusterMetadataStore:
+		return c.ClusterMetadata
+	case QueueStore:
+		return c.Queue
+	case NexusStore:
+		return c.Nexus
+	default:
+		return nil
+	}
+}
+
 func ensureStoreConsistencyNotNil(c *YugabyteStoreConsistency) *YugabyteStoreConsistency {
 	if c == nil {
 		c = &YugabyteStoreConsistency{}
@@ -132,6 +301,65 @@ func ensureStoreConsistencyNotNil(c *YugabyteStoreConsistency) *YugabyteStoreCon
 }
 
 func (c *Yugabyte) validate() error {
+	if strings.TrimSpace(c.Hosts) == "" {
+		return errors.New("hosts must be specified")
+	}
+	if c.Keyspace == "" {
+		return errors.New("keyspace must be specified")
+	}
+	if c.Port < 0 || c.Port > math.MaxUint16 {
+		return fmt.Errorf("port %d is out of range", c.Port)
+	}
+	if c.MaxConns < 0 {
+		return fmt.Errorf("maxConns must not be negative, got %d", c.MaxConns)
+	}
+	if c.ConnectTimeout < 0 || c.Timeout < 0 || c.WriteTimeout < 0 {
+		return errors.New("connectTimeout, timeout and writeTimeout must not be negative")
+	}
+	if c.AddressTranslator != nil && c.AddressTranslator.Translator == "" && len(c.AddressTranslator.Options) > 0 {
+		return errors.New("addressTranslator options require a translator name")
+	}
+	if c.Retry != nil {
+		if c.Retry.MaxAttempts < 0 || c.Retry.InitialInterval < 0 || c.Retry.MaxInterval < 0 {
+			return errors.New("retry settings must not be negative")
+		}
+		if c.Retry.MaxInterval > 0 && c.Retry.MaxInterval < c.Retry.InitialInterval {
+			return errors.New("retry maxInterval must not be less than initialInterval")
+		}
+	}
+	if c.SlowQuery != nil && (c.SlowQuery.Latency < 0 || c.SlowQuery.Statements < 0 || c.SlowQuery.PayloadBytes < 0) {
+		return errors.New("slowQuery thresholds must not be negative")
+	}
+	if c.TimerBucketWidth < 0 {
+		return errors.New("timerBucketWidth must not be negative")
+	}
+	if c.TimerBucketWidth > 0 && (c.TimerBucketWidth < time.Minute || c.TimerBucketWidth%time.Second != 0) {
+		return fmt.Errorf("timerBucketWidth must be a whole number of seconds of at least 1m, got %v", c.TimerBucketWidth)
+	}
+	if c.SystemTaskBucketSize < 0 {
+		return errors.New("systemTaskBucketSize must not be negative")
+	}
+	if c.VisibilityScanLimit < 0 {
+		return errors.New("visibilityScanLimit must not be negative")
+	}
+	switch c.SchemaDriftCheck {
+	case SchemaDriftCheckDisabled, SchemaDriftCheckWarn, SchemaDriftCheckFail:
+	default:
+		return fmt.Errorf("schemaDriftCheck must be %q or %q, got %q", SchemaDriftCheckWarn, SchemaDriftCheckFail, c.SchemaDriftCheck)
+	}
+	if c.Credentials != nil {
+		if c.Credentials.Provider == "" {
+			return errors.New("credentials require a provider name")
+		}
+		if c.User == "" {
+			return errors.New("credentials require a user")
+		}
+	}
+
+	if err := c.Pools.validate(); err != nil {
+		return err
+	}
+
 	return c.Consistency.validate()
 }
 
@@ -182,61 +410,162 @@ func parseSerialConsistency(serialConsistency string) (gocql.SerialConsistency,
 	return s, err
 }
 
-func importTls(cfg config.CustomDatastoreConfig) *auth.TLS {
+const (
+	// passwordEnvVar is consulted when a user is configured without an explicit password
+	passwordEnvVar = "YUGABYTE_PASSWORD"
+)
+
+func importTls(d *optionDecoder) *auth.TLS {
 	tls := &auth.TLS{}
 
-	options, ok := cfg.Options["tls"].(map[string]interface{})
+	options, ok := d.Map("tls")
 	if !ok {
 		return tls
 	}
 
-	if enabled, ok := options["enabled"].(bool); ok {
-		tls.Enabled = enabled
-	}
+	options.Bool("enabled", &tls.Enabled)
+	options.Bool("enableHostVerification", &tls.EnableHostVerification)
+	options.String("serverName", &tls.ServerName)
+	options.String("caFile", &tls.CaFile)
+	options.String("caData", &tls.CaData)
+	options.String("certFile", &tls.CertFile)
+	options.String("certData", &tls.CertData)
+	options.String("keyFile", &tls.KeyFile)
+	options.String("keyData", &tls.KeyData)
 
-	if enableHostVerification, ok := options["enableHostVerification"].(bool); ok {
-		tls.EnableHostVerification = enableHostVerification
-	}
-	if serverName, ok := options["serverName"].(string); ok {
-		tls.ServerName = serverName
+	return tls
+}
+
+func importConsistencySettings(d *optionDecoder, key string) *YugabyteConsistencySettings {
+	options, ok := d.Map(key)
+	if !ok {
+		return nil
 	}
 
-	if caFile, ok := options["caFile"].(string); ok {
-		tls.CaFile = caFile
+	settings := &YugabyteConsistencySettings{}
+	options.String("consistency", &settings.Consistency)
+	options.String("serialConsistency", &settings.SerialConsistency)
+
+	return settings
+}
+
+func importConsistency(d *optionDecoder) *YugabyteStoreConsistency {
+	options, ok := d.Map("consistency")
+	if !ok {
+		return nil
 	}
-	if caData, ok := options["caData"].(string); ok {
-		tls.CaData = caData
+
+	return &YugabyteStoreConsistency{
+		Default:         importConsistencySettings(options, "default"),
+		Execution:       importConsistencySettings(options, string(ExecutionStore)),
+		History:         importConsistencySettings(options, string(HistoryStore)),
+		MatchingTask:    importConsistencySettings(options, string(MatchingTaskStore)),
+		Shard:           importConsistencySettings(options, string(ShardStore)),
+		Metadata:        importConsistencySettings(options, string(MetadataStore)),
+		ClusterMetadata: importConsistencySettings(options, string(ClusterMetadataStore)),
+		Queue:           importConsistencySettings(options, string(QueueStore)),
+		Nexus:           importConsistencySettings(options, string(NexusStore)),
 	}
+}
 
-	if certFile, ok := options["certFile"].(string); ok {
-		tls.CertFile = certFile
+func importAddressTranslator(d *optionDecoder) *YugabyteAddressTranslator {
+	options, ok := d.Map("addressTranslator")
+	if !ok {
+		return nil
 	}
-	if certData, ok := options["certData"].(string); ok {
-		tls.CertData = certData
+
+	translator := &YugabyteAddressTranslator{}
+	options.String("translator", &translator.Translator)
+	options.StringMap("options", &translator.Options)
+
+	return translator
+}
+
+func importRetryPolicy(d *optionDecoder) *YugabyteRetryPolicy {
+	options, ok := d.Map("retry")
+	if !ok {
+		return nil
 	}
 
-	if keyFile, ok := options["keyFile"].(string); ok {
-		tls.KeyFile = keyFile
+	retry := &YugabyteRetryPolicy{}
+	options.Int("maxAttempts", &retry.MaxAttempts)
+	options.Duration("initialInterval", &retry.InitialInterval)
+	options.Duration("maxInterval", &retry.MaxInterval)
+
+	return retry
+}
+
+func importSlowQueryLogging(d *optionDecoder) *YugabyteSlowQueryLogging {
+	options, ok := d.Map("slowQuery")
+	if !ok {
+		return nil
 	}
-	if keyData, ok := options["keyData"].(string); ok {
-		tls.KeyData = keyData
+
+	slowQuery := &YugabyteSlowQueryLogging{}
+	options.Duration("latency", &slowQuery.Latency)
+	options.Int("statements", &slowQuery.Statements)
+	options.Int("payloadBytes", &slowQuery.PayloadBytes)
+
+	return slowQuery
+}
+
+func importCredentials(d *optionDecoder) *YugabyteCredentials {
+	options, ok := d.Map("credentials")
+	if !ok {
+		return nil
 	}
 
-	return tls
+	credentials := &YugabyteCredentials{}
+	options.String("provider", &credentials.Provider)
+	options.StringMap("options", &credentials.Options)
+
+	return credentials
 }
 
+// ImportConfig decodes the untyped options of a CustomDatastoreConfig into a validated Yugabyte configuration
 func ImportConfig(cfg config.CustomDatastoreConfig) (Yugabyte, error) {
-	config := Yugabyte{
-		Hosts:    cfg.Options["hosts"].(string),
-		Keyspace: cfg.Options["keyspace"].(string),
-		TLS:      importTls(cfg),
+	d := newOptionDecoder(cfg.Options)
+
+	var config Yugabyte
+	d.String("hosts", &config.Hosts)
+	d.Int("port", &config.Port)
+	d.String("user", &config.User)
+	d.String("password", &config.Password)
+	d.StringSlice("allowedAuthenticators", &config.AllowedAuthenticators)
+	d.String("keyspace", &config.Keyspace)
+	d.String("datacenter", &config.Datacenter)
+	d.Int("maxConns", &config.MaxConns)
+	d.Duration("connectTimeout", &config.ConnectTimeout)
+	d.Duration("timeout", &config.Timeout)
+	d.Duration("writeTimeout", &config.WriteTimeout)
+	config.TLS = importTls(d)
+	config.Consistency = importConsistency(d)
+	d.Bool("disableInitialHostLookup", &config.DisableInitialHostLookup)
+	config.AddressTranslator = importAddressTranslator(d)
+	config.Credentials = importCredentials(d)
+	config.Retry = importRetryPolicy(d)
+	config.SlowQuery = importSlowQueryLogging(d)
+	config.Pools = importPools(d)
+	d.Duration("timerBucketWidth", &config.TimerBucketWidth)
+	d.Int("systemTaskBucketSize", &config.SystemTaskBucketSize)
+	d.Bool("executionMapTables", &config.ExecutionMapTables)
+	var schemaDriftCheck string
+	d.String("schemaDriftCheck", &schemaDriftCheck)
+	config.SchemaDriftCheck = YugabyteSchemaDriftCheck(strings.ToLower(strings.TrimSpace(schemaDriftCheck)))
+	d.Bool("skipSchemaVersionCheck", &config.SkipSchemaVersionCheck)
+	d.Int("visibilityScanLimit", &config.VisibilityScanLimit)
+	d.Bool("taskQueueUserDataMigrated", &config.TaskQueueUserDataMigrated)
+
+	if err := d.Err(); err != nil {
+		return Yugabyte{}, fmt.Errorf("invalid yugabyte configuration: %w", err)
 	}
-	if datacenter, ok := cfg.Options["datacenter"].(string); ok {
-		config.Datacenter = datacenter
+
+	if config.User != "" && config.Password == "" && config.Credentials == nil {
+		config.Password = os.Getenv(passwordEnvVar)
 	}
-	if user, ok := cfg.Options["user"].(string); ok {
-		config.User = user
-		config.Password = os.Getenv("YUGABYTE_PASSWORD")
+
+	if err := config.validate(); err != nil {
+		return Yugabyte{}, fmt.Errorf("invalid yugabyte configuration: %w", err)
 	}
 
 	return config, nil
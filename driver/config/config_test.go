@@ -0,0 +1,485 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yugabyte/gocql"
+	"go.temporal.io/server/common/config"
+)
+
+func TestImportConfig(t *testing.T) {
+	tests := map[string]struct {
+		options map[string]any
+		err     string
+		verify  func(*testing.T, Yugabyte)
+	}{
+		"minimal": {
+			options: map[string]any{
+				"hosts":    "127.0.0.1",
+				"keyspace": "temporal",
+			},
+			verify: func(t *testing.T, cfg Yugabyte) {
+				assert.Equal(t, "127.0.0.1", cfg.Hosts)
+				assert.Equal(t, "temporal", cfg.Keyspace)
+				assert.NotNil(t, cfg.TLS)
+				assert.Nil(t, cfg.Consistency)
+				assert.Nil(t, cfg.AddressTranslator)
+			},
+		},
+		"full": {
+			options: map[string]any{
+				"hosts":                    "yb-1,yb-2",
+				"port":                     float64(9042),
+				"user":                     "yugabyte",
+				"password":                 "secret",
+				"allowedAuthenticators":    []any{"org.apache.cassandra.auth.PasswordAuthenticator"},
+				"keyspace":                 "temporal",
+				"datacenter":               "dc1",
+				"maxConns":                 "20",
+				"connectTimeout":           "2s",
+				"timeout":                  "5s",
+				"writeTimeout":             10 * time.Second,
+				"disableInitialHostLookup": true,
+				"tls": map[string]any{
+					"enabled":                "true",
+					"enableHostVerification": true,
+					"serverName":             "yb.example.com",
+					"caFile":                 "/certs/ca.crt",
+					"certFile":               "/certs/tls.crt",
+					"keyFile":                "/certs/tls.key",
+				},
+				"consistency": map[any]any{
+					"default": map[string]any{
+						"consistency":       "QUORUM",
+						"serialConsistency": "SERIAL",
+					},
+					"clusterMetadata": map[string]any{
+						"consistency": "ONE",
+					},
+				},
+				"addressTranslator": map[string]any{
+					"translator": "fixed-address-translator",
+					"options": map[string]any{
+						"advertised-hostname": "yb.example.com",
+					},
+				},
+			},
+			verify: func(t *testing.T, cfg Yugabyte) {
+				assert.Equal(t, 9042, cfg.Port)
+				assert.Equal(t, "secret", cfg.Password)
+				assert.Equal(t, []string{"org.apache.cassandra.auth.PasswordAuthenticator"}, cfg.AllowedAuthenticators)
+				assert.Equal(t, "dc1", cfg.Datacenter)
+				assert.Equal(t, 20, cfg.MaxConns)
+				assert.Equal(t, 2*time.Second, cfg.ConnectTimeout)
+				assert.Equal(t, 5*time.Second, cfg.Timeout)
+				assert.Equal(t, 10*time.Second, cfg.WriteTimeout)
+				assert.True(t, cfg.DisableInitialHostLookup)
+				assert.True(t, cfg.TLS.Enabled)
+				assert.True(t, cfg.TLS.EnableHostVerification)
+				assert.Equal(t, "yb.example.com", cfg.TLS.ServerName)
+				assert.Equal(t, "/certs/ca.crt", cfg.TLS.CaFile)
+				assert.Equal(t, "/certs/tls.crt", cfg.TLS.CertFile)
+				assert.Equal(t, "/certs/tls.key", cfg.TLS.KeyFile)
+				assert.Equal(t, "QUORUM", cfg.Consistency.Default.Consistency)
+				assert.Equal(t, "SERIAL", cfg.Consistency.Default.SerialConsistency)
+				assert.Equal(t, "ONE", cfg.Consistency.ClusterMetadata.Consistency)
+				assert.Equal(t, "fixed-address-translator", cfg.AddressTranslator.Translator)
+				assert.Equal(t, map[string]string{"advertised-hostname": "yb.example.com"}, cfg.AddressTranslator.Options)
+			},
+		},
+		"passwordFromEnvironment": {
+			options: map[string]any{
+				"hosts":    "127.0.0.1",
+				"keyspace": "temporal",
+				"user":     "yugabyte",
+			},
+			verify: func(t *testing.T, cfg Yugabyte) {
+				assert.Equal(t, "from-env", cfg.Password)
+			},
+		},
+		"nullOptionsIgnored": {
+			options: map[string]any{
+				"hosts":    "127.0.0.1",
+				"keyspace": "temporal",
+				"port":     nil,
+				"tls":      nil,
+			},
+			verify: func(t *testing.T, cfg Yugabyte) {
+				assert.Equal(t, 0, cfg.Port)
+				assert.False(t, cfg.TLS.Enabled)
+			},
+		},
+		"credentials": {
+			options: map[string]any{
+				"hosts":    "127.0.0.1",
+				"keyspace": "temporal",
+				"user":     "yugabyte",
+				"credentials": map[string]any{
+					"provider": "file",
+					"options": map[string]any{
+						"path": "/etc/yugabyte/password",
+					},
+				},
+			},
+			verify: func(t *testing.T, cfg Yugabyte) {
+				assert.Empty(t, cfg.Password)
+				assert.Equal(t, "file", cfg.Credentials.Provider)
+				assert.Equal(t, map[string]string{"path": "/etc/yugabyte/password"}, cfg.Credentials.Options)
+			},
+		},
+		"credentialsWithoutUser": {
+			options: map[string]any{
+				"hosts":    "127.0.0.1",
+				"keyspace": "temporal",
+				"credentials": map[string]any{
+					"provider": "env",
+				},
+			},
+			err: "credentials require a user",
+		},
+		"slowQuery": {
+			options: map[string]any{
+				"hosts":    "127.0.0.1",
+				"keyspace": "temporal",
+				"slowQuery": map[string]any{
+					"latency":      "500ms",
+					"statements":   50,
+					"payloadBytes": "1048576",
+				},
+			},
+			verify: func(t *testing.T, cfg Yugabyte) {
+				assert.Equal(t, &YugabyteSlowQueryLogging{
+					Latency:      500 * time.Millisecond,
+					Statements:   50,
+					PayloadBytes: 1048576,
+				}, cfg.SlowQuery)
+			},
+		},
+		"negativeSlowQuery": {
+			options: map[string]any{
+				"hosts":    "127.0.0.1",
+				"keyspace": "temporal",
+				"slowQuery": map[string]any{
+					"statements": -1,
+				},
+			},
+			err: "slowQuery thresholds must not be negative",
+		},
+		"timerBucketWidth": {
+			options: map[string]any{
+				"hosts":            "127.0.0.1",
+				"keyspace":         "temporal",
+				"timerBucketWidth": "1h",
+			},
+			verify: func(t *testing.T, cfg Yugabyte) {
+				assert.Equal(t, time.Hour, cfg.TimerBucketWidth)
+			},
+		},
+		"narrowTimerBucketWidth": {
+			options: map[string]any{
+				"hosts":            "127.0.0.1",
+				"keyspace":         "temporal",
+				"timerBucketWidth": "10s",
+			},
+			err: "timerBucketWidth must be a whole number of seconds of at least 1m, got 10s",
+		},
+		"systemTaskBucketSize": {
+			options: map[string]any{
+				"hosts":                "127.0.0.1",
+				"keyspace":             "temporal",
+				"systemTaskBucketSize": float64(65536),
+			},
+			verify: func(t *testing.T, cfg Yugabyte) {
+				assert.Equal(t, 65536, cfg.SystemTaskBucketSize)
+			},
+		},
+		"negativeSystemTaskBucketSize": {
+			options: map[string]any{
+				"hosts":                "127.0.0.1",
+				"keyspace":             "temporal",
+				"systemTaskBucketSize": -1,
+			},
+			err: "systemTaskBucketSize must not be negative",
+		},
+		"visibilityScanLimit": {
+			options: map[string]any{
+				"hosts":               "127.0.0.1",
+				"keyspace":            "temporal",
+				"visibilityScanLimit": float64(500),
+			},
+			verify: func(t *testing.T, cfg Yugabyte) {
+				assert.Equal(t, 500, cfg.VisibilityScanLimit)
+			},
+		},
+		"negativeVisibilityScanLimit": {
+			options: map[string]any{
+				"hosts":               "127.0.0.1",
+				"keyspace":            "temporal",
+				"visibilityScanLimit": -1,
+			},
+			err: "visibilityScanLimit must not be negative",
+		},
+		"taskQueueUserDataMigrated": {
+			options: map[string]any{
+				"hosts":                     "127.0.0.1",
+				"keyspace":                  "temporal",
+				"taskQueueUserDataMigrated": true,
+			},
+			verify: func(t *testing.T, cfg Yugabyte) {
+				assert.True(t, cfg.TaskQueueUserDataMigrated)
+			},
+		},
+		"executionMapTables": {
+			options: map[string]any{
+				"hosts":              "127.0.0.1",
+				"keyspace":           "temporal",
+				"executionMapTables": "true",
+			},
+			verify: func(t *testing.T, cfg Yugabyte) {
+				assert.True(t, cfg.ExecutionMapTables)
+			},
+		},
+		"schemaDriftCheck": {
+			options: map[string]any{
+				"hosts":            "127.0.0.1",
+				"keyspace":         "temporal",
+				"schemaDriftCheck": "Warn",
+			},
+			verify: func(t *testing.T, cfg Yugabyte) {
+				assert.Equal(t, SchemaDriftCheckWarn, cfg.SchemaDriftCheck)
+			},
+		},
+		"invalidSchemaDriftCheck": {
+			options: map[string]any{
+				"hosts":            "127.0.0.1",
+				"keyspace":         "temporal",
+				"schemaDriftCheck": "ignore",
+			},
+			err: `schemaDriftCheck must be "warn" or "fail", got "ignore"`,
+		},
+		"skipSchemaVersionCheck": {
+			options: map[string]any{
+				"hosts":                  "127.0.0.1",
+				"keyspace":               "temporal",
+				"skipSchemaVersionCheck": true,
+			},
+			verify: func(t *testing.T, cfg Yugabyte) {
+				assert.True(t, cfg.SkipSchemaVersionCheck)
+			},
+		},
+		"missingHosts": {
+			options: map[string]any{
+				"keyspace": "temporal",
+			},
+			err: "hosts must be specified",
+		},
+		"missingKeyspace": {
+			options: map[string]any{
+				"hosts": "127.0.0.1",
+			},
+			err: "keyspace must be specified",
+		},
+		"hostsWrongType": {
+			options: map[string]any{
+				"hosts":    []any{"127.0.0.1"},
+				"keyspace": "temporal",
+			},
+			err: `option "hosts": expected a string`,
+		},
+		"fractionalPort": {
+			options: map[string]any{
+				"hosts":    "127.0.0.1",
+				"keyspace": "temporal",
+				"port":     9042.5,
+			},
+			err: `option "port": expected an integer`,
+		},
+		"portOutOfRange": {
+			options: map[string]any{
+				"hosts":    "127.0.0.1",
+				"keyspace": "temporal",
+				"port":     70000,
+			},
+			err: "port 70000 is out of range",
+		},
+		"numericDuration": {
+			options: map[string]any{
+				"hosts":    "127.0.0.1",
+				"keyspace": "temporal",
+				"timeout":  10,
+			},
+			err: `option "timeout": expected a duration`,
+		},
+		"badDuration": {
+			options: map[string]any{
+				"hosts":          "127.0.0.1",
+				"keyspace":       "temporal",
+				"connectTimeout": "ten seconds",
+			},
+			err: `option "connectTimeout": expected a duration`,
+		},
+		"badNestedOption": {
+			options: map[string]any{
+				"hosts":    "127.0.0.1",
+				"keyspace": "temporal",
+				"tls": map[string]any{
+					"enabled": "sometimes",
+				},
+			},
+			err: `option "tls.enabled": expected a boolean`,
+		},
+		"badConsistency": {
+			options: map[string]any{
+				"hosts":    "127.0.0.1",
+				"keyspace": "temporal",
+				"consistency": map[string]any{
+					"default": map[string]any{
+						"consistency": "MOSTLY",
+					},
+				},
+			},
+			err: "bad driver consistency",
+		},
+	}
+
+	t.Setenv(passwordEnvVar, "from-env")
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg, err := ImportConfig(config.CustomDatastoreConfig{Name: "yugabyte", Options: tt.options})
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			if tt.verify != nil {
+				tt.verify(t, cfg)
+			}
+		})
+	}
+}
+
+func TestStoreConsistency(t *testing.T) {
+	tests := map[string]struct {
+		cfg               *YugabyteStoreConsistency
+		store             YugabyteStore
+		consistency       gocql.Consistency
+		serialConsistency gocql.SerialConsistency
+	}{
+		"unset": {
+			cfg:               nil,
+			store:             ExecutionStore,
+			consistency:       gocql.LocalQuorum,
+			serialConsistency: gocql.LocalSerial,
+		},
+		"default": {
+			cfg: &YugabyteStoreConsistency{
+				Default: &YugabyteConsistencySettings{Consistency: "QUORUM", SerialConsistency: "SERIAL"},
+			},
+			store:             ShardStore,
+			consistency:       gocql.Quorum,
+			serialConsistency: gocql.Serial,
+		},
+		"override": {
+			cfg: &YugabyteStoreConsistency{
+				Default:         &YugabyteConsistencySettings{Consistency: "QUORUM"},
+				ClusterMetadata: &YugabyteConsistencySettings{Consistency: "ONE"},
+			},
+			store:             ClusterMetadataStore,
+			consistency:       gocql.One,
+			serialConsistency: gocql.LocalSerial,
+		},
+		"overrideOtherStore": {
+			cfg: &YugabyteStoreConsistency{
+				Default:         &YugabyteConsistencySettings{Consistency: "QUORUM"},
+				ClusterMetadata: &YugabyteConsistencySettings{Consistency: "ONE"},
+			},
+			store:             ExecutionStore,
+			consistency:       gocql.Quorum,
+			serialConsistency: gocql.LocalSerial,
+		},
+		"partialOverride": {
+			cfg: &YugabyteStoreConsistency{
+				Nexus: &YugabyteConsistencySettings{SerialConsistency: "SERIAL"},
+			},
+			store:             NexusStore,
+			consistency:       gocql.LocalQuorum,
+			serialConsistency: gocql.Serial,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.consistency, tt.cfg.GetStoreConsistency(tt.store))
+			assert.Equal(t, tt.serialConsistency, tt.cfg.GetStoreSerialConsistency(tt.store))
+		})
+	}
+}
+
+func TestForWorkload(t *testing.T) {
+	cfg, err := ImportConfig(config.CustomDatastoreConfig{
+		Name: "yugabyte",
+		Options: map[string]any{
+			"hosts":    "127.0.0.1",
+			"keyspace": "temporal",
+			"maxConns": 10,
+			"timeout":  "5s",
+			"consistency": map[string]any{
+				"default": map[string]any{"consistency": "QUORUM"},
+				"shard":   map[string]any{"consistency": "ALL"},
+			},
+			"pools": map[string]any{
+				"execution": map[string]any{
+					"maxConns":    20,
+					"consistency": map[string]any{"consistency": "LOCAL_ONE"},
+				},
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	matching, ok := cfg.ForWorkload(MatchingWorkload)
+	assert.False(t, ok)
+	assert.Equal(t, cfg, matching)
+
+	execution, ok := cfg.ForWorkload(ExecutionWorkload)
+	assert.True(t, ok)
+	assert.Equal(t, 20, execution.MaxConns)
+	assert.Equal(t, 5*time.Second, execution.Timeout)
+	assert.Equal(t, gocql.LocalOne, execution.Consistency.GetStoreConsistency(ExecutionStore))
+	assert.Equal(t, gocql.All, execution.Consistency.GetStoreConsistency(ShardStore))
+	assert.Equal(t, gocql.Quorum, cfg.Consistency.GetStoreConsistency(ExecutionStore))
+}
+
+func TestStoreWorkload(t *testing.T) {
+	assert.Equal(t, ExecutionWorkload, StoreWorkload(HistoryStore))
+	assert.Equal(t, ExecutionWorkload, StoreWorkload(ShardStore))
+	assert.Equal(t, MatchingWorkload, StoreWorkload(MatchingTaskStore))
+	assert.Equal(t, MetadataWorkload, StoreWorkload(ClusterMetadataStore))
+	assert.Equal(t, MetadataWorkload, StoreWorkload(NexusStore))
+	assert.Equal(t, QueueWorkload, StoreWorkload(QueueStore))
+}
@@ -0,0 +1,294 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// optionDecoder reads typed values out of the untyped options map supplied through
+	// config.CustomDatastoreConfig. Absent and null options leave the destination untouched.
+	// The first error encountered is retained and every subsequent call becomes a no-op, so
+	// callers may decode a whole struct and check Err() once at the end.
+	optionDecoder struct {
+		prefix string
+		values map[string]any
+		err    *error
+	}
+)
+
+func newOptionDecoder(values map[string]any) *optionDecoder {
+	var err error
+	return &optionDecoder{
+		values: values,
+		err:    &err,
+	}
+}
+
+// Err returns the first error encountered while decoding, if any
+func (d *optionDecoder) Err() error {
+	return *d.err
+}
+
+func (d *optionDecoder) path(key string) string {
+	if d.prefix == "" {
+		return key
+	}
+	return d.prefix + "." + key
+}
+
+func (d *optionDecoder) fail(key string, format string, args ...any) {
+	if *d.err == nil {
+		*d.err = fmt.Errorf("option %q: %s", d.path(key), fmt.Sprintf(format, args...))
+	}
+}
+
+func (d *optionDecoder) lookup(key string) (any, bool) {
+	if *d.err != nil {
+		return nil, false
+	}
+	v, ok := d.values[key]
+	if !ok || v == nil {
+		return nil, false
+	}
+	return v, true
+}
+
+// Has reports whether the option is present and non-null
+func (d *optionDecoder) Has(key string) bool {
+	v, ok := d.values[key]
+	return ok && v != nil
+}
+
+// String decodes a string option
+func (d *optionDecoder) String(key string, dest *string) {
+	v, ok := d.lookup(key)
+	if !ok {
+		return
+	}
+	switch t := v.(type) {
+	case string:
+		*dest = t
+	case fmt.Stringer:
+		*dest = t.String()
+	default:
+		d.fail(key, "expected a string, got %T", v)
+	}
+}
+
+// Bool decodes a boolean option, accepting the string forms produced by templated configuration
+func (d *optionDecoder) Bool(key string, dest *bool) {
+	v, ok := d.lookup(key)
+	if !ok {
+		return
+	}
+	switch t := v.(type) {
+	case bool:
+		*dest = t
+	case string:
+		if strings.TrimSpace(t) == "" {
+			return
+		}
+		b, err := strconv.ParseBool(strings.TrimSpace(t))
+		if err != nil {
+			d.fail(key, "expected a boolean, got %q", t)
+			return
+		}
+		*dest = b
+	default:
+		d.fail(key, "expected a boolean, got %T", v)
+	}
+}
+
+// Int decodes an integer option. YAML decoders may surface whole numbers as floats and templated
+// configuration may surface them as strings; both are accepted as long as the value is integral.
+func (d *optionDecoder) Int(key string, dest *int) {
+	v, ok := d.lookup(key)
+	if !ok {
+		return
+	}
+	switch t := v.(type) {
+	case int:
+		*dest = t
+	case int8:
+		*dest = int(t)
+	case int16:
+		*dest = int(t)
+	case int32:
+		*dest = int(t)
+	case int64:
+		*dest = int(t)
+	case uint:
+		*dest = int(t)
+	case uint8:
+		*dest = int(t)
+	case uint16:
+		*dest = int(t)
+	case uint32:
+		*dest = int(t)
+	case uint64:
+		*dest = int(t)
+	case float32:
+		d.float(key, float64(t), dest)
+	case float64:
+		d.float(key, t, dest)
+	case string:
+		if strings.TrimSpace(t) == "" {
+			return
+		}
+		i, err := strconv.Atoi(strings.TrimSpace(t))
+		if err != nil {
+			d.fail(key, "expected an integer, got %q", t)
+			return
+		}
+		*dest = i
+	default:
+		d.fail(key, "expected an integer, got %T", v)
+	}
+}
+
+func (d *optionDecoder) float(key string, f float64, dest *int) {
+	if f != math.Trunc(f) || f > math.MaxInt32 || f < math.MinInt32 {
+		d.fail(key, "expected an integer, got %v", f)
+		return
+	}
+	*dest = int(f)
+}
+
+// Duration decodes a duration option expressed as a Go duration string such as "600ms" or "10s"
+func (d *optionDecoder) Duration(key string, dest *time.Duration) {
+	v, ok := d.lookup(key)
+	if !ok {
+		return
+	}
+	switch t := v.(type) {
+	case time.Duration:
+		*dest = t
+	case string:
+		if strings.TrimSpace(t) == "" {
+			return
+		}
+		duration, err := time.ParseDuration(strings.TrimSpace(t))
+		if err != nil {
+			d.fail(key, "expected a duration such as \"10s\", got %q", t)
+			return
+		}
+		*dest = duration
+	default:
+		d.fail(key, "expected a duration such as \"10s\", got %T", v)
+	}
+}
+
+// StringSlice decodes a list of strings, also accepting a single comma-separated string
+func (d *optionDecoder) StringSlice(key string, dest *[]string) {
+	v, ok := d.lookup(key)
+	if !ok {
+		return
+	}
+	switch t := v.(type) {
+	case []string:
+		*dest = t
+	case string:
+		var result []string
+		for _, s := range strings.Split(t, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				result = append(result, s)
+			}
+		}
+		*dest = result
+	case []any:
+		result := make([]string, 0, len(t))
+		for i, item := range t {
+			s, ok := item.(string)
+			if !ok {
+				d.fail(key, "expected a list of strings, got %T at index %d", item, i)
+				return
+			}
+			result = append(result, s)
+		}
+		*dest = result
+	default:
+		d.fail(key, "expected a list of strings, got %T", v)
+	}
+}
+
+// StringMap decodes a map of strings to strings
+func (d *optionDecoder) StringMap(key string, dest *map[string]string) {
+	child, ok := d.Map(key)
+	if !ok {
+		return
+	}
+	result := make(map[string]string, len(child.values))
+	for k := range child.values {
+		var s string
+		child.String(k, &s)
+		result[k] = s
+	}
+	if d.Err() == nil {
+		*dest = result
+	}
+}
+
+// Map returns a decoder for a nested map option. The returned decoder shares the error state of its parent.
+func (d *optionDecoder) Map(key string) (*optionDecoder, bool) {
+	v, ok := d.lookup(key)
+	if !ok {
+		return nil, false
+	}
+
+	child := &optionDecoder{
+		prefix: d.path(key),
+		err:    d.err,
+	}
+
+	switch t := v.(type) {
+	case map[string]any:
+		child.values = t
+	case map[any]any:
+		// yaml.v2 style maps
+		child.values = make(map[string]any, len(t))
+		for k, item := range t {
+			name, ok := k.(string)
+			if !ok {
+				d.fail(key, "expected string keys, got %T", k)
+				return nil, false
+			}
+			child.values[name] = item
+		}
+	case map[string]string:
+		child.values = make(map[string]any, len(t))
+		for k, item := range t {
+			child.values[k] = item
+		}
+	default:
+		d.fail(key, "expected a map, got %T", v)
+		return nil, false
+	}
+
+	return child, true
+}
@@ -0,0 +1,209 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+type (
+	// YugabyteWorkload names a class of stores which may be given a connection pool of its own
+	YugabyteWorkload string
+
+	// YugabytePools configures isolated connection pools per workload, so that a burst of traffic from one class of
+	// stores, such as matching polling for tasks, cannot starve another of connections.  Workloads without a pool
+	// share a single pool configured by the top level settings.
+	YugabytePools struct {
+		// Execution is used by the execution, history and shard stores
+		Execution *YugabyteConnectionPool `yaml:"execution"`
+		// Matching is used by the matching task store
+		Matching *YugabyteConnectionPool `yaml:"matching"`
+		// Metadata is used by the namespace metadata, cluster metadata and nexus endpoint stores
+		Metadata *YugabyteConnectionPool `yaml:"metadata"`
+		// Queue is used by the queue and queue v2 stores
+		Queue *YugabyteConnectionPool `yaml:"queue"`
+	}
+
+	// YugabyteConnectionPool overrides the connection settings of the shared pool.  Settings left unset are
+	// inherited from the top level configuration.
+	YugabyteConnectionPool struct {
+		// MaxConns is the max number of connections per host
+		MaxConns int `yaml:"maxConns"`
+		// ConnectTimeout is a timeout for initial dial to Yugabyte server
+		ConnectTimeout time.Duration `yaml:"connectTimeout"`
+		// Timeout is a timeout for reads and, unless otherwise specified, writes
+		Timeout time.Duration `yaml:"timeout"`
+		// WriteTimeout is a timeout for writing a query
+		WriteTimeout time.Duration `yaml:"writeTimeout"`
+		// Consistency overrides the default consistency for the stores of the workload.  Per-store overrides under
+		// consistency still take precedence.
+		Consistency *YugabyteConsistencySettings `yaml:"consistency"`
+	}
+)
+
+// Definition of all workloads that accept a connection pool of their own
+const (
+	ExecutionWorkload YugabyteWorkload = "execution"
+	MatchingWorkload  YugabyteWorkload = "matching"
+	MetadataWorkload  YugabyteWorkload = "metadata"
+	QueueWorkload     YugabyteWorkload = "queue"
+)
+
+// Workloads lists every workload that accepts a connection pool of its own
+var Workloads = []YugabyteWorkload{
+	ExecutionWorkload,
+	MatchingWorkload,
+	MetadataWorkload,
+	QueueWorkload,
+}
+
+// StoreWorkload returns the workload to which the given store belongs
+func StoreWorkload(store YugabyteStore) YugabyteWorkload {
+	switch store {
+	case ExecutionStore, HistoryStore, ShardStore:
+		return ExecutionWorkload
+	case MatchingTaskStore:
+		return MatchingWorkload
+	case QueueStore:
+		return QueueWorkload
+	default:
+		return MetadataWorkload
+	}
+}
+
+// Get returns the pool configured for the given workload, if any
+func (p *YugabytePools) Get(workload YugabyteWorkload) *YugabyteConnectionPool {
+	if p == nil {
+		return nil
+	}
+
+	switch workload {
+	case ExecutionWorkload:
+		return p.Execution
+	case MatchingWorkload:
+		return p.Matching
+	case MetadataWorkload:
+		return p.Metadata
+	case QueueWorkload:
+		return p.Queue
+	default:
+		return nil
+	}
+}
+
+// ForWorkload returns the configuration of the connection pool for the given workload and whether the workload has
+// a pool of its own.  Without one, the configuration is returned unchanged.
+func (c Yugabyte) ForWorkload(workload YugabyteWorkload) (Yugabyte, bool) {
+	pool := c.Pools.Get(workload)
+	if pool == nil {
+		return c, false
+	}
+
+	if pool.MaxConns > 0 {
+		c.MaxConns = pool.MaxConns
+	}
+	if pool.ConnectTimeout > 0 {
+		c.ConnectTimeout = pool.ConnectTimeout
+	}
+	if pool.Timeout > 0 {
+		c.Timeout = pool.Timeout
+	}
+	if pool.WriteTimeout > 0 {
+		c.WriteTimeout = pool.WriteTimeout
+	}
+	if pool.Consistency != nil {
+		c.Consistency = c.Consistency.withDefault(pool.Consistency)
+	}
+
+	return c, true
+}
+
+// withDefault returns a copy of the consistency settings whose default is overridden by settings
+func (c *YugabyteStoreConsistency) withDefault(settings *YugabyteConsistencySettings) *YugabyteStoreConsistency {
+	var merged YugabyteStoreConsistency
+	if c != nil {
+		merged = *c
+	}
+
+	defaults := YugabyteConsistencySettings{}
+	if merged.Default != nil {
+		defaults = *merged.Default
+	}
+	if settings.Consistency != "" {
+		defaults.Consistency = settings.Consistency
+	}
+	if settings.SerialConsistency != "" {
+		defaults.SerialConsistency = settings.SerialConsistency
+	}
+	merged.Default = &defaults
+
+	return &merged
+}
+
+func (p *YugabytePools) validate() error {
+	for _, workload := range Workloads {
+		pool := p.Get(workload)
+		if pool == nil {
+			continue
+		}
+		if pool.MaxConns < 0 || pool.ConnectTimeout < 0 || pool.Timeout < 0 || pool.WriteTimeout < 0 {
+			return fmt.Errorf("pool %q settings must not be negative", workload)
+		}
+		if err := pool.Consistency.validate(); err != nil {
+			return fmt.Errorf("pool %q: %w", workload, err)
+		}
+	}
+
+	return nil
+}
+
+func importConnectionPool(d *optionDecoder, workload YugabyteWorkload) *YugabyteConnectionPool {
+	options, ok := d.Map(string(workload))
+	if !ok {
+		return nil
+	}
+
+	pool := &YugabyteConnectionPool{}
+	options.Int("maxConns", &pool.MaxConns)
+	options.Duration("connectTimeout", &pool.ConnectTimeout)
+	options.Duration("timeout", &pool.Timeout)
+	options.Duration("writeTimeout", &pool.WriteTimeout)
+	pool.Consistency = importConsistencySettings(options, "consistency")
+
+	return pool
+}
+
+func importPools(d *optionDecoder) *YugabytePools {
+	options, ok := d.Map("pools")
+	if !ok {
+		return nil
+	}
+
+	return &YugabytePools{
+		Execution: importConnectionPool(options, ExecutionWorkload),
+		Matching:  importConnectionPool(options, MatchingWorkload),
+		Metadata:  importConnectionPool(options, MetadataWorkload),
+		Queue:     importConnectionPool(options, QueueWorkload),
+	}
+}
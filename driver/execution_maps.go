@@ -0,0 +1,404 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package driver
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/manetu/temporal-yugabyte/utils/gocql"
+	commonpb "go.temporal.io/api/common/v1"
+	p "go.temporal.io/server/common/persistence"
+)
+
+const (
+	templateCreateExecutionSignalRequestedQuery = `INSERT INTO execution_signals_requested (` +
+		`shard_id, namespace_id, workflow_id, run_id, signal_id) ` +
+		`VALUES(?, ?, ?, ?, ?)`
+
+	templateDeleteExecutionSignalRequestedQuery = `DELETE FROM execution_signals_requested ` +
+		`WHERE shard_id = ? ` +
+		`and namespace_id = ? ` +
+		`and workflow_id = ? ` +
+		`and run_id = ? ` +
+		`and signal_id = ? `
+
+	templateDeleteExecutionSignalsRequestedQuery = `DELETE FROM execution_signals_requested ` +
+		`WHERE shard_id = ? ` +
+		`and namespace_id = ? ` +
+		`and workflow_id = ? ` +
+		`and run_id = ? `
+
+	templateGetExecutionSignalsRequestedQuery = `SELECT signal_id ` +
+		`FROM execution_signals_requested ` +
+		`WHERE shard_id = ? ` +
+		`and namespace_id = ? ` +
+		`and workflow_id = ? ` +
+		`and run_id = ? `
+)
+
+var (
+	executionActivities       = newExecutionMapTable("execution_activities", "schedule_id")
+	executionTimers           = newExecutionMapTable("execution_timers", "timer_id")
+	executionChildExecutions  = newExecutionMapTable("execution_child_executions", "initiated_id")
+	executionRequestCancels   = newExecutionMapTable("execution_request_cancels", "initiated_id")
+	executionSignals          = newExecutionMapTable("execution_signals", "initiated_id")
+	executionBufferedEvents   = newExecutionMapTable("execution_buffered_events", "db_record_version")
+	executionMapTableDeletion = []string{
+		executionActivities.clear,
+		executionTimers.clear,
+		executionChildExecutions.clear,
+		executionRequestCancels.clear,
+		executionSignals.clear,
+		executionBufferedEvents.clear,
+		templateDeleteExecutionSignalsRequestedQuery,
+	}
+)
+
+type (
+	// executionMapTable holds the statements of a table keeping one of the maps of a workflow's mutable state, one
+	// row per entry, in place of a collection of its executions row
+	executionMapTable struct {
+		upsert string
+		remove string
+		clear  string
+		list   string
+	}
+
+	// executionMaps holds the entries read from the map tables of a workflow
+	executionMaps struct {
+		activityInfos       map[int64]*commonpb.DataBlob
+		timerInfos          map[string]*commonpb.DataBlob
+		childExecutionInfos map[int64]*commonpb.DataBlob
+		requestCancelInfos  map[int64]*commonpb.DataBlob
+		signalInfos         map[int64]*commonpb.DataBlob
+		signalRequestedIDs  []string
+		bufferedEvents      []*commonpb.DataBlob
+	}
+)
+
+func newExecutionMapTable(table string, key string) executionMapTable {
+	partition := `WHERE shard_id = ? ` +
+		`and namespace_id = ? ` +
+		`and workflow_id = ? ` +
+		`and run_id = ? `
+
+	return executionMapTable{
+		upsert: `INSERT INTO ` + table + ` (` +
+			`shard_id, namespace_id, workflow_id, run_id, ` + key + `, data, encoding) ` +
+			`VALUES(?, ?, ?, ?, ?, ?, ?)`,
+		remove: `DELETE FROM ` + table + ` ` + partition + `and ` + key + ` = ? `,
+		clear:  `DELETE FROM ` + table + ` ` + partition,
+		list:   `SELECT ` + key + `, data, encoding FROM ` + table + ` ` + partition,
+	}
+}
+
+func upsertExecutionMapRows[K int64 | string](
+	txn *gocql.Txn,
+	table executionMapTable,
+	rows map[K]*commonpb.DataBlob,
+	shardID int32,
+	namespaceID string,
+	workflowID string,
+	runID string,
+) {
+	for key, blob := range rows {
+		txn.Query(table.upsert,
+			shardID,
+			namespaceID,
+			workflowID,
+			runID,
+			key,
+			blob.Data,
+			blob.EncodingType.String())
+	}
+}
+
+// deleteExecutionMapRows deletes entries from a map table and from the collection it replaces, which still holds
+// the entries written before the map tables were enabled
+func deleteExecutionMapRows[K int64 | string](
+	txn *gocql.Txn,
+	table executionMapTable,
+	templateDeleteCollectionEntry string,
+	keys map[K]struct{},
+	shardID int32,
+	namespaceID string,
+	workflowID string,
+	runID string,
+) {
+	for key := range keys {
+		txn.Query(table.remove,
+			shardID,
+			namespaceID,
+			workflowID,
+			runID,
+			key)
+		txn.Query(templateDeleteCollectionEntry,
+			key,
+			shardID,
+			namespaceID,
+			workflowID,
+			runID)
+	}
+}
+
+func upsertExecutionSignalsRequested(
+	txn *gocql.Txn,
+	signalRequestedIDs map[string]struct{},
+	shardID int32,
+	namespaceID string,
+	workflowID string,
+	runID string,
+) {
+	for signalID := range signalRequestedIDs {
+		txn.Query(templateCreateExecutionSignalRequestedQuery,
+			shardID,
+			namespaceID,
+			workflowID,
+			runID,
+			signalID)
+	}
+}
+
+// updateExecutionMapTables applies the changes a mutation makes to the maps of a workflow to its map tables
+func updateExecutionMapTables(
+	txn *gocql.Txn,
+	shardID int32,
+	workflowMutation *p.InternalWorkflowMutation,
+) {
+	namespaceID := workflowMutation.NamespaceID
+	workflowID := workflowMutation.WorkflowID
+	runID := workflowMutation.RunID
+
+	upsertExecutionMapRows(txn, executionActivities, workflowMutation.UpsertActivityInfos, shardID, namespaceID, workflowID, runID)
+	deleteExecutionMapRows(txn, executionActivities, templateDeleteActivityInfoQuery, workflowMutation.DeleteActivityInfos, shardID, namespaceID, workflowID, runID)
+	upsertExecutionMapRows(txn, executionTimers, workflowMutation.UpsertTimerInfos, shardID, namespaceID, workflowID, runID)
+	deleteExecutionMapRows(txn, executionTimers, templateDeleteTimerInfoQuery, workflowMutation.DeleteTimerInfos, shardID, namespaceID, workflowID, runID)
+	upsertExecutionMapRows(txn, executionChildExecutions, workflowMutation.UpsertChildExecutionInfos, shardID, namespaceID, workflowID, runID)
+	deleteExecutionMapRows(txn, executionChildExecutions, templateDeleteChildExecutionInfoQuery, workflowMutation.DeleteChildExecutionInfos, shardID, namespaceID, workflowID, runID)
+	upsertExecutionMapRows(txn, executionRequestCancels, workflowMutation.UpsertRequestCancelInfos, shardID, namespaceID, workflowID, runID)
+	deleteExecutionMapRows(txn, executionRequestCancels, templateDeleteRequestCancelInfoQuery, workflowMutation.DeleteRequestCancelInfos, shardID, namespaceID, workflowID, runID)
+	upsertExecutionMapRows(txn, executionSignals, workflowMutation.UpsertSignalInfos, shardID, namespaceID, workflowID, runID)
+	deleteExecutionMapRows(txn, executionSignals, templateDeleteSignalInfoQuery, workflowMutation.DeleteSignalInfos, shardID, namespaceID, workflowID, runID)
+
+	upsertExecutionSignalsRequested(txn, workflowMutation.UpsertSignalRequestedIDs, shardID, namespaceID, workflowID, runID)
+	for signalID := range workflowMutation.DeleteSignalRequestedIDs {
+		txn.Query(templateDeleteExecutionSignalRequestedQuery,
+			shardID,
+			namespaceID,
+			workflowID,
+			runID,
+			signalID)
+	}
+	updateSignalsRequested(txn, nil, workflowMutation.DeleteSignalRequestedIDs, shardID, namespaceID, workflowID, runID)
+
+	if workflowMutation.ClearBufferedEvents {
+		txn.Query(executionBufferedEvents.clear,
+			shardID,
+			namespaceID,
+			workflowID,
+			runID)
+		deleteBufferedEvents(txn, shardID, namespaceID, workflowID, runID)
+	} else if workflowMutation.NewBufferedEvents != nil {
+		// the record version increases with every update of the workflow, so it orders the batches as appended
+		txn.Query(executionBufferedEvents.upsert,
+			shardID,
+			namespaceID,
+			workflowID,
+			runID,
+			workflowMutation.DBRecordVersion,
+			workflowMutation.NewBufferedEvents.Data,
+			workflowMutation.NewBufferedEvents.EncodingType.String())
+	}
+}
+
+// createExecutionMapTables writes the maps of a new workflow to its map tables
+func createExecutionMapTables(
+	txn *gocql.Txn,
+	shardID int32,
+	workflowSnapshot *p.InternalWorkflowSnapshot,
+) {
+	namespaceID := workflowSnapshot.NamespaceID
+	workflowID := workflowSnapshot.WorkflowID
+	runID := workflowSnapshot.RunID
+
+	upsertExecutionMapRows(txn, executionActivities, workflowSnapshot.ActivityInfos, shardID, namespaceID, workflowID, runID)
+	upsertExecutionMapRows(txn, executionTimers, workflowSnapshot.TimerInfos, shardID, namespaceID, workflowID, runID)
+	upsertExecutionMapRows(txn, executionChildExecutions, workflowSnapshot.ChildExecutionInfos, shardID, namespaceID, workflowID, runID)
+	upsertExecutionMapRows(txn, executionRequestCancels, workflowSnapshot.RequestCancelInfos, shardID, namespaceID, workflowID, runID)
+	upsertExecutionMapRows(txn, executionSignals, workflowSnapshot.SignalInfos, shardID, namespaceID, workflowID, runID)
+	upsertExecutionSignalsRequested(txn, workflowSnapshot.SignalRequestedIDs, shardID, namespaceID, workflowID, runID)
+}
+
+// resetExecutionMapTables replaces the maps of a workflow with those of a snapshot.  The collections of the
+// executions row are emptied as well, which completes the migration of the workflow to the map tables.
+func resetExecutionMapTables(
+	txn *gocql.Txn,
+	shardID int32,
+	workflowSnapshot *p.InternalWorkflowSnapshot,
+) error {
+	namespaceID := workflowSnapshot.NamespaceID
+	workflowID := workflowSnapshot.WorkflowID
+	runID := workflowSnapshot.RunID
+
+	if err := resetActivityInfos(txn, nil, shardID, namespaceID, workflowID, runID); err != nil {
+		return err
+	}
+	if err := resetTimerInfos(txn, nil, shardID, namespaceID, workflowID, runID); err != nil {
+		return err
+	}
+	if err := resetChildExecutionInfos(txn, nil, shardID, namespaceID, workflowID, runID); err != nil {
+		return err
+	}
+	if err := resetRequestCancelInfos(txn, nil, shardID, namespaceID, workflowID, runID); err != nil {
+		return err
+	}
+	if err := resetSignalInfos(txn, nil, shardID, namespaceID, workflowID, runID); err != nil {
+		return err
+	}
+	resetSignalRequested(txn, nil, shardID, namespaceID, workflowID, runID)
+	deleteBufferedEvents(txn, shardID, namespaceID, workflowID, runID)
+
+	// the statements of a transaction apply in order, so the rows of the snapshot outlive the partition deletes
+	deleteExecutionMapTables(txn, shardID, namespaceID, workflowID, runID)
+	createExecutionMapTables(txn, shardID, workflowSnapshot)
+	return nil
+}
+
+func deleteExecutionMapTables(
+	txn *gocql.Txn,
+	shardID int32,
+	namespaceID string,
+	workflowID string,
+	runID string,
+) {
+	for _, template := range executionMapTableDeletion {
+		txn.Query(template,
+			shardID,
+			namespaceID,
+			workflowID,
+			runID)
+	}
+}
+
+func listExecutionMapRows[K int64 | string](
+	ctx context.Context,
+	session gocql.Session,
+	table executionMapTable,
+	request *p.GetWorkflowExecutionRequest,
+	rows map[K]*commonpb.DataBlob,
+) error {
+	iter := session.Query(table.list,
+		request.ShardID,
+		request.NamespaceID,
+		request.WorkflowID,
+		request.RunID,
+	).WithContext(ctx).Iter()
+
+	var key K
+	var data []byte
+	var encoding string
+	for iter.Scan(&key, &data, &encoding) {
+		rows[key] = p.NewDataBlob(data, encoding)
+	}
+	return gocql.ConvertError("GetWorkflowExecution", iter.Close())
+}
+
+// readExecutionMapTables adds the reads of the map tables of a workflow to reads, so that they run in parallel with
+// the read of its executions row
+func readExecutionMapTables(
+	ctx context.Context,
+	session gocql.Session,
+	reads *PostQueryValidation,
+	request *p.GetWorkflowExecutionRequest,
+) *executionMaps {
+	m := &executionMaps{
+		activityInfos:       make(map[int64]*commonpb.DataBlob),
+		timerInfos:          make(map[string]*commonpb.DataBlob),
+		childExecutionInfos: make(map[int64]*commonpb.DataBlob),
+		requestCancelInfos:  make(map[int64]*commonpb.DataBlob),
+		signalInfos:         make(map[int64]*commonpb.DataBlob),
+	}
+
+	reads.Add(func() error {
+		return listExecutionMapRows(ctx, session, executionActivities, request, m.activityInfos)
+	})
+	reads.Add(func() error {
+		return listExecutionMapRows(ctx, session, executionTimers, request, m.timerInfos)
+	})
+	reads.Add(func() error {
+		return listExecutionMapRows(ctx, session, executionChildExecutions, request, m.childExecutionInfos)
+	})
+	reads.Add(func() error {
+		return listExecutionMapRows(ctx, session, executionRequestCancels, request, m.requestCancelInfos)
+	})
+	reads.Add(func() error {
+		return listExecutionMapRows(ctx, session, executionSignals, request, m.signalInfos)
+	})
+	reads.Add(func() error {
+		iter := session.Query(templateGetExecutionSignalsRequestedQuery,
+			request.ShardID,
+			request.NamespaceID,
+			request.WorkflowID,
+			request.RunID,
+		).WithContext(ctx).Iter()
+
+		var signalID string
+		for iter.Scan(&signalID) {
+			m.signalRequestedIDs = append(m.signalRequestedIDs, signalID)
+		}
+		return gocql.ConvertError("GetWorkflowExecution", iter.Close())
+	})
+	reads.Add(func() error {
+		batches := make(map[int64]*commonpb.DataBlob)
+		if err := listExecutionMapRows(ctx, session, executionBufferedEvents, request, batches); err != nil {
+			return err
+		}
+		for _, version := range slices.Sorted(maps.Keys(batches)) {
+			m.bufferedEvents = append(m.bufferedEvents, batches[version])
+		}
+		return nil
+	})
+
+	return m
+}
+
+// mergeInto adds the entries of the map tables to the maps read from the collections of the executions row.  An
+// entry in both was updated after the map tables were enabled, so the map table holds its current value, and
+// buffered events in the collection were appended before those in the map table.
+func (m *executionMaps) mergeInto(state *p.InternalWorkflowMutableState) {
+	maps.Copy(state.ActivityInfos, m.activityInfos)
+	maps.Copy(state.TimerInfos, m.timerInfos)
+	maps.Copy(state.ChildExecutionInfos, m.childExecutionInfos)
+	maps.Copy(state.RequestCancelInfos, m.requestCancelInfos)
+	maps.Copy(state.SignalInfos, m.signalInfos)
+
+	for _, signalID := range m.signalRequestedIDs {
+		if !slices.Contains(state.SignalRequestedIDs, signalID) {
+			state.SignalRequestedIDs = append(state.SignalRequestedIDs, signalID)
+		}
+	}
+
+	state.BufferedEvents = append(state.BufferedEvents, m.bufferedEvents...)
+}
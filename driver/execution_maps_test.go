@@ -0,0 +1,69 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package driver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	commonpb "go.temporal.io/api/common/v1"
+	p "go.temporal.io/server/common/persistence"
+)
+
+func TestMergeExecutionMaps(t *testing.T) {
+	legacy := p.NewDataBlob([]byte("legacy"), "Proto3")
+	current := p.NewDataBlob([]byte("current"), "Proto3")
+
+	state := &p.InternalWorkflowMutableState{
+		ActivityInfos:       map[int64]*commonpb.DataBlob{1: legacy, 2: legacy},
+		TimerInfos:          map[string]*commonpb.DataBlob{"a": legacy},
+		ChildExecutionInfos: map[int64]*commonpb.DataBlob{},
+		RequestCancelInfos:  map[int64]*commonpb.DataBlob{},
+		SignalInfos:         map[int64]*commonpb.DataBlob{},
+		SignalRequestedIDs:  []string{"5b2c9a0e-1f0b-4b8e-9c61-3f0f6a7d1e01"},
+		BufferedEvents:      []*commonpb.DataBlob{legacy},
+	}
+	tables := &executionMaps{
+		activityInfos:       map[int64]*commonpb.DataBlob{2: current, 3: current},
+		timerInfos:          map[string]*commonpb.DataBlob{"b": current},
+		childExecutionInfos: map[int64]*commonpb.DataBlob{4: current},
+		requestCancelInfos:  map[int64]*commonpb.DataBlob{},
+		signalInfos:         map[int64]*commonpb.DataBlob{},
+		signalRequestedIDs: []string{
+			"5b2c9a0e-1f0b-4b8e-9c61-3f0f6a7d1e01",
+			"0d7e3f4a-8c2b-4a1e-b5d6-9e8f7a6b5c02",
+		},
+		bufferedEvents: []*commonpb.DataBlob{current},
+	}
+
+	tables.mergeInto(state)
+
+	assert.Equal(t, map[int64]*commonpb.DataBlob{1: legacy, 2: current, 3: current}, state.ActivityInfos)
+	assert.Equal(t, map[string]*commonpb.DataBlob{"a": legacy, "b": current}, state.TimerInfos)
+	assert.Equal(t, map[int64]*commonpb.DataBlob{4: current}, state.ChildExecutionInfos)
+	assert.ElementsMatch(t, []string{
+		"5b2c9a0e-1f0b-4b8e-9c61-3f0f6a7d1e01",
+		"0d7e3f4a-8c2b-4a1e-b5d6-9e8f7a6b5c02",
+	}, state.SignalRequestedIDs)
+	assert.Equal(t, []*commonpb.DataBlob{legacy, current}, state.BufferedEvents)
+}
@@ -30,6 +30,8 @@ import (
 	"github.com/manetu/temporal-yugabyte/utils/gocql"
 	"time"
 
+	ybconfig "github.com/manetu/temporal-yugabyte/driver/config"
+
 	p "go.temporal.io/server/common/persistence"
 )
 
@@ -66,6 +68,15 @@ var (
 )
 
 type (
+	// executionLayout holds the optional table layouts configured for the execution store.  A nil task layout
+	// keeps those tasks in their original table, and mapTables keeps the maps of a workflow's mutable state in
+	// tables of their own rather than in collections of its executions row.
+	executionLayout struct {
+		timers      *timerBuckets
+		systemTasks *systemTaskBuckets
+		mapTables   bool
+	}
+
 	ExecutionStore struct {
 		*HistoryStore
 		*MutableStateStore
@@ -75,11 +86,32 @@ type (
 
 var _ p.ExecutionStore = (*ExecutionStore)(nil)
 
+// newExecutionLayout returns the table layouts enabled by the configuration
+func newExecutionLayout(cfg ybconfig.Yugabyte) executionLayout {
+	return executionLayout{
+		timers:      newTimerBuckets(cfg.TimerBucketWidth),
+		systemTasks: newSystemTaskBuckets(cfg.SystemTaskBucketSize),
+		mapTables:   cfg.ExecutionMapTables,
+	}
+}
+
 func NewExecutionStore(session gocql.Session) *ExecutionStore {
+	return newExecutionStore(session, session, session, ybconfig.Yugabyte{})
+}
+
+// newExecutionStore returns an execution store whose history, mutable state and task stores each use their own
+// session, laying out mutable state and tasks as configured.  Closing the store closes all three sessions.
+func newExecutionStore(
+	historySession gocql.Session,
+	mutableStateSession gocql.Session,
+	taskSession gocql.Session,
+	cfg ybconfig.Yugabyte,
+) *ExecutionStore {
+	layout := newExecutionLayout(cfg)
 	return &ExecutionStore{
-		HistoryStore:          NewHistoryStore(session),
-		MutableStateStore:     NewMutableStateStore(session),
-		MutableStateTaskStore: NewMutableStateTaskStore(session),
+		HistoryStore:          NewHistoryStore(historySession),
+		MutableStateStore:     newMutableStateStore(mutableStateSession, layout),
+		MutableStateTaskStore: newMutableStateTaskStore(taskSession, layout),
 	}
 }
 
@@ -141,6 +173,7 @@ func (d *ExecutionStore) GetName() string {
 	return yugabytePersistenceName
 }
 
+// Close releases the sessions of the history, mutable state and task stores
 func (d *ExecutionStore) Close() {
 	if d.HistoryStore.Session != nil {
 		d.HistoryStore.Session.Close()
@@ -28,6 +28,7 @@ package driver
 import (
 	ybconfig "github.com/manetu/temporal-yugabyte/driver/config"
 	localgocql "github.com/manetu/temporal-yugabyte/utils/gocql"
+	"slices"
 	"sync"
 
 	"github.com/yugabyte/gocql"
@@ -48,10 +49,18 @@ type (
 		cfg         ybconfig.Yugabyte
 		clusterName string
 		logger      log.Logger
-		session     localgocql.Session
+		pools       map[ybconfig.YugabyteWorkload]*sessionPool
+	}
+
+	// sessionPool is a session serving the stores of one or more workloads, with the configuration it was created with
+	sessionPool struct {
+		cfg      ybconfig.Yugabyte
+		sessions *localgocql.SessionOwner
 	}
 )
 
+const poolTagName = "pool"
+
 // NewFactory returns an InstanceFactory object which can be used to create data stores that are backed by yugabyte
 func (f *MetaFactory) NewFactory(
 	cfg config.CustomDatastoreConfig,
@@ -64,78 +73,200 @@ func (f *MetaFactory) NewFactory(
 	if err != nil {
 		logger.Fatal("unable to import configuration", tag.Error(err))
 	}
-	session, err := localgocql.NewSession(
+
+	pools := make(map[ybconfig.YugabyteWorkload]localgocql.Session)
+	for _, workload := range ybconfig.Workloads {
+		wcfg, ok := ccfg.ForWorkload(workload)
+		if !ok {
+			continue
+		}
+		pools[workload], err = newSession(wcfg, r, logger, metricsHandler.WithTags(metrics.StringTag(poolTagName, string(workload))))
+		if err != nil {
+			logger.Fatal("unable to initialize driver session", tag.NewStringTag(poolTagName, string(workload)), tag.Error(err))
+		}
+	}
+
+	// the shared session is only opened when some workload has no pool of its own
+	var session localgocql.Session
+	checkSession := pools[ybconfig.MetadataWorkload]
+	if sharesSession(pools) {
+		session, err = newSession(ccfg, r, logger, metricsHandler)
+		if err != nil {
+			logger.Fatal("unable to initialize driver session", tag.Error(err))
+		}
+		checkSession = session
+	}
+	verifySchemaVersion(ccfg, checkSession, logger)
+	if ccfg.SchemaDriftCheck != ybconfig.SchemaDriftCheckDisabled {
+		checkSchemaDrift(ccfg, checkSession, logger)
+	}
+
+	return newInstanceFactory(ccfg, clusterName, logger, session, pools)
+}
+
+// sharesSession reports whether any workload lacks a session in pools and so falls back to the shared session
+func sharesSession(pools map[ybconfig.YugabyteWorkload]localgocql.Session) bool {
+	for _, workload := range ybconfig.Workloads {
+		if _, ok := pools[workload]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
+func newSession(
+	cfg ybconfig.Yugabyte,
+	r resolver.ServiceResolver,
+	logger log.Logger,
+	metricsHandler metrics.Handler,
+) (localgocql.Session, error) {
+	return localgocql.NewSession(
 		func() (*gocql.ClusterConfig, error) {
-			return localgocql.NewYugabyteCluster(ccfg, r)
+			return localgocql.NewYugabyteCluster(cfg, r)
 		},
 		logger,
 		metricsHandler,
+		localgocql.WithRetryPolicy(localgocql.NewRetryPolicy(cfg.Retry)),
+		localgocql.WithSlowQueryLogging(cfg.SlowQuery),
 	)
-	if err != nil {
-		logger.Fatal("unable to initialize driver session", tag.Error(err))
-	}
-	return NewFactoryFromSession(ccfg, clusterName, logger, session)
 }
 
-// NewFactoryFromSession returns an instance of a factory object from the given session.
+// NewFactoryFromSession returns an instance of a factory object from the given session.  The factory takes ownership
+// of the session: stores it creates hold leases on the session, and only Close closes it.
 func NewFactoryFromSession(
 	cfg ybconfig.Yugabyte,
 	clusterName string,
 	logger log.Logger,
 	session localgocql.Session,
 ) p.DataStoreFactory {
-	return &InstanceFactory{
+	return newInstanceFactory(cfg, clusterName, logger, session, nil)
+}
+
+// newInstanceFactory returns a factory whose stores use the session of their workload in pools, if any, or the
+// shared session otherwise.  shared may be nil when every workload has a session in pools.
+func newInstanceFactory(
+	cfg ybconfig.Yugabyte,
+	clusterName string,
+	logger log.Logger,
+	shared localgocql.Session,
+	pools map[ybconfig.YugabyteWorkload]localgocql.Session,
+) *InstanceFactory {
+	var sharedPool *sessionPool
+	if shared != nil {
+		sharedPool = &sessionPool{
+			cfg:      cfg,
+			sessions: localgocql.NewSessionOwner(shared),
+		}
+	}
+
+	f := &InstanceFactory{
 		cfg:         cfg,
 		clusterName: clusterName,
 		logger:      logger,
-		session:     session,
+		pools:       make(map[ybconfig.YugabyteWorkload]*sessionPool, len(ybconfig.Workloads)),
 	}
+	for _, workload := range ybconfig.Workloads {
+		session, ok := pools[workload]
+		if !ok {
+			f.pools[workload] = sharedPool
+			continue
+		}
+		wcfg, _ := cfg.ForWorkload(workload)
+		f.pools[workload] = &sessionPool{
+			cfg:      wcfg,
+			sessions: localgocql.NewSessionOwner(session),
+		}
+	}
+	return f
 }
 
 // NewTaskStore returns a new task store
 func (f *InstanceFactory) NewTaskStore() (p.TaskStore, error) {
-	return NewMatchingTaskStore(f.session, f.logger), nil
+	return newMatchingTaskStore(f.storeSession(ybconfig.MatchingTaskStore), f.logger, f.cfg.TaskQueueUserDataMigrated), nil
 }
 
 // NewShardStore returns a new shard store
 func (f *InstanceFactory) NewShardStore() (p.ShardStore, error) {
-	return NewShardStore(f.clusterName, f.session, f.logger), nil
+	return NewShardStore(f.clusterName, f.storeSession(ybconfig.ShardStore), f.logger), nil
 }
 
 // NewMetadataStore returns a metadata store
 func (f *InstanceFactory) NewMetadataStore() (p.MetadataStore, error) {
-	return NewMetadataStore(f.clusterName, f.session, f.logger)
+	return NewMetadataStore(f.clusterName, f.storeSession(ybconfig.MetadataStore), f.logger)
 }
 
 // NewClusterMetadataStore returns a metadata store
 func (f *InstanceFactory) NewClusterMetadataStore() (p.ClusterMetadataStore, error) {
-	return NewClusterMetadataStore(f.session, f.logger)
+	return NewClusterMetadataStore(f.storeSession(ybconfig.ClusterMetadataStore), f.logger)
 }
 
 // NewExecutionStore returns a new ExecutionStore.
 func (f *InstanceFactory) NewExecutionStore() (p.ExecutionStore, error) {
-	return NewExecutionStore(f.session), nil
+	return newExecutionStore(
+		f.storeSession(ybconfig.HistoryStore),
+		f.storeSession(ybconfig.ExecutionStore),
+		f.storeSession(ybconfig.ExecutionStore),
+		f.cfg,
+	), nil
 }
 
 // NewQueue returns a new queue backed by driver
 func (f *InstanceFactory) NewQueue(queueType p.QueueType) (p.Queue, error) {
-	return NewQueueStore(queueType, f.session, f.logger)
+	return NewQueueStore(queueType, f.storeSession(ybconfig.QueueStore), f.logger)
 }
 
 // NewQueueV2 returns a new data-access object for queues and messages stored in driver . It will never return an
 // error.
 func (f *InstanceFactory) NewQueueV2() (p.QueueV2, error) {
-	return NewQueueV2Store(f.session, f.logger), nil
+	return NewQueueV2Store(f.storeSession(ybconfig.QueueStore), f.logger), nil
 }
 
 // NewNexusEndpointStore returns a new NexusEndpointStore
 func (f *InstanceFactory) NewNexusEndpointStore() (p.NexusEndpointStore, error) {
-	return NewNexusEndpointStore(f.session, f.logger), nil
+	return NewNexusEndpointStore(f.storeSession(ybconfig.NexusStore), f.logger), nil
 }
 
-// Close closes the factory
+// storeSession returns a lease on a view of the session of the store's workload that applies the consistency
+// configured for the store and attributes statement metrics to it.  Closing the store releases the lease.
+func (f *InstanceFactory) storeSession(store ybconfig.YugabyteStore) localgocql.Session {
+	pool := f.pools[ybconfig.StoreWorkload(store)]
+	return pool.sessions.Lease(localgocql.ForStore(
+		pool.sessions.Session(),
+		string(store),
+		pool.cfg.Consistency.GetStoreConsistency(store),
+		pool.cfg.Consistency.GetStoreSerialConsistency(store),
+	))
+}
+
+// leases returns the number of stores which have not been closed
+func (f *InstanceFactory) leases() int {
+	var leases int
+	for _, owner := range f.sessionOwners() {
+		leases += owner.Leases()
+	}
+	return leases
+}
+
+// sessionOwners returns the owner of each distinct session of the factory
+func (f *InstanceFactory) sessionOwners() []*localgocql.SessionOwner {
+	var owners []*localgocql.SessionOwner
+	for _, workload := range ybconfig.Workloads {
+		owner := f.pools[workload].sessions
+		if !slices.Contains(owners, owner) {
+			owners = append(owners, owner)
+		}
+	}
+	return owners
+}
+
+// Close closes the factory and the sessions shared by its stores
 func (f *InstanceFactory) Close() {
 	f.Lock()
 	defer f.Unlock()
-	f.session.Close()
+	if leases := f.leases(); leases > 0 {
+		f.logger.Debug("closing sessions with stores still open", tag.NewInt("leases", leases))
+	}
+	for _, owner := range f.sessionOwners() {
+		owner.Close()
+	}
 }
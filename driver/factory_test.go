@@ -0,0 +1,133 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package driver
+
+import (
+	"context"
+	"testing"
+
+	ybconfig "github.com/manetu/temporal-yugabyte/driver/config"
+	"github.com/manetu/temporal-yugabyte/utils/gocql"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/server/common/log"
+)
+
+type (
+	// fakeSession is a [gocql.Session] which records the statements issued and the number of times it was closed
+	fakeSession struct {
+		statements []string
+		closed     int
+	}
+)
+
+func (s *fakeSession) Query(stmt string, _ ...interface{}) gocql.Query {
+	s.statements = append(s.statements, stmt)
+	return nil
+}
+
+func (s *fakeSession) NewTxn() *gocql.Txn                         { return nil }
+func (s *fakeSession) NewBatch(gocql.BatchType) *gocql.Batch      { return nil }
+func (s *fakeSession) ExecuteBatch(*gocql.Batch) error            { return nil }
+func (s *fakeSession) AwaitSchemaAgreement(context.Context) error { return nil }
+func (s *fakeSession) Close()                                     { s.closed++ }
+func (s *fakeSession) MapExecuteBatchCAS(*gocql.Batch, map[string]interface{}) (bool, gocql.Iter, error) {
+	return false, nil, nil
+}
+
+func TestClosingStoreLeavesSessionOpen(t *testing.T) {
+	session := &fakeSession{}
+	factory := NewFactoryFromSession(ybconfig.Yugabyte{}, "active", log.NewNoopLogger(), session).(*InstanceFactory)
+
+	shardStore, err := factory.NewShardStore()
+	require.NoError(t, err)
+	executionStore, err := factory.NewExecutionStore()
+	require.NoError(t, err)
+	taskStore, err := factory.NewTaskStore()
+	require.NoError(t, err)
+	require.Equal(t, 5, factory.leases())
+
+	shardStore.Close()
+	executionStore.Close()
+	executionStore.Close()
+	require.Zero(t, session.closed)
+	require.Equal(t, 1, factory.leases())
+
+	// the remaining store still issues statements on the shared session
+	taskStore.(*MatchingTaskStore).Session.Query(templateGetTaskQueueQuery)
+	require.Equal(t, []string{templateGetTaskQueueQuery}, session.statements)
+
+	factory.Close()
+	factory.Close()
+	require.Equal(t, 1, session.closed)
+}
+
+func TestWorkloadPools(t *testing.T) {
+	shared := &fakeSession{}
+	matching := &fakeSession{}
+	cfg := ybconfig.Yugabyte{
+		Pools: &ybconfig.YugabytePools{
+			Matching: &ybconfig.YugabyteConnectionPool{MaxConns: 4},
+		},
+	}
+	factory := newInstanceFactory(cfg, "active", log.NewNoopLogger(), shared, map[ybconfig.YugabyteWorkload]gocql.Session{
+		ybconfig.MatchingWorkload: matching,
+	})
+
+	shardStore, err := factory.NewShardStore()
+	require.NoError(t, err)
+	taskStore, err := factory.NewTaskStore()
+	require.NoError(t, err)
+
+	shardStore.(*ShardStore).Session.Query(templateGetShardQuery)
+	taskStore.(*MatchingTaskStore).Session.Query(templateGetTaskQueueQuery)
+	require.Equal(t, []string{templateGetShardQuery}, shared.statements)
+	require.Equal(t, []string{templateGetTaskQueueQuery}, matching.statements)
+
+	factory.Close()
+	require.Equal(t, 1, shared.closed)
+	require.Equal(t, 1, matching.closed)
+}
+
+func TestWorkloadPoolsWithoutSharedSession(t *testing.T) {
+	sessions := make(map[ybconfig.YugabyteWorkload]gocql.Session)
+	pools := make(map[ybconfig.YugabyteWorkload]*fakeSession)
+	for _, workload := range ybconfig.Workloads {
+		pools[workload] = &fakeSession{}
+		sessions[workload] = pools[workload]
+	}
+	require.False(t, sharesSession(sessions))
+	delete(sessions, ybconfig.QueueWorkload)
+	require.True(t, sharesSession(sessions))
+	sessions[ybconfig.QueueWorkload] = pools[ybconfig.QueueWorkload]
+
+	factory := newInstanceFactory(ybconfig.Yugabyte{}, "active", log.NewNoopLogger(), nil, sessions)
+	taskStore, err := factory.NewTaskStore()
+	require.NoError(t, err)
+	taskStore.(*MatchingTaskStore).Session.Query(templateGetTaskQueueQuery)
+	require.Equal(t, []string{templateGetTaskQueueQuery}, pools[ybconfig.MatchingWorkload].statements)
+
+	factory.Close()
+	for workload, session := range pools {
+		require.Equal(t, 1, session.closed, workload)
+	}
+}
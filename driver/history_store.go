@@ -32,6 +32,7 @@ import (
 
 	commonpb "go.temporal.io/api/common/v1"
 	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/server/common/log/tag"
 	p "go.temporal.io/server/common/persistence"
 
 	"go.temporal.io/server/common/primitives"
@@ -87,6 +88,8 @@ func (h *HistoryStore) AppendHistoryNodes(
 	ctx context.Context,
 	request *p.InternalAppendHistoryNodesRequest,
 ) error {
+	ctx = gocql.WithOperation(ctx, "AppendHistoryNodes")
+	ctx = gocql.WithLogTags(ctx, tag.ShardID(request.ShardID))
 	branchInfo := request.BranchInfo
 	node := request.Node
 
@@ -134,6 +137,7 @@ func (h *HistoryStore) DeleteHistoryNodes(
 	ctx context.Context,
 	request *p.InternalDeleteHistoryNodesRequest,
 ) error {
+	ctx = gocql.WithOperation(ctx, "DeleteHistoryNodes")
 	branchInfo := request.BranchInfo
 	treeID := branchInfo.TreeId
 	branchID := branchInfo.BranchId
@@ -164,6 +168,8 @@ func (h *HistoryStore) ReadHistoryBranch(
 	ctx context.Context,
 	request *p.InternalReadHistoryBranchRequest,
 ) (*p.InternalReadHistoryBranchResponse, error) {
+	ctx = gocql.WithOperation(ctx, "ReadHistoryBranch")
+	ctx = gocql.WithLogTags(ctx, tag.ShardID(request.ShardID))
 	branch, err := h.GetHistoryBranchUtil().ParseHistoryBranchInfo(request.BranchToken)
 	if err != nil {
 		return nil, err
@@ -264,6 +270,7 @@ func (h *HistoryStore) ForkHistoryBranch(
 	ctx context.Context,
 	request *p.InternalForkHistoryBranchRequest,
 ) error {
+	ctx = gocql.WithOperation(ctx, "ForkHistoryBranch")
 
 	forkB := request.ForkBranchInfo
 	datablob := request.TreeInfo
@@ -291,6 +298,7 @@ func (h *HistoryStore) DeleteHistoryBranch(
 	ctx context.Context,
 	request *p.InternalDeleteHistoryBranchRequest,
 ) error {
+	ctx = gocql.WithOperation(ctx, "DeleteHistoryBranch")
 
 	txn := h.Session.NewTxn().WithContext(ctx)
 	txn.Query(v2templateDeleteBranch, request.BranchInfo.TreeId, request.BranchInfo.BranchId)
@@ -324,6 +332,7 @@ func (h *HistoryStore) GetAllHistoryTreeBranches(
 	ctx context.Context,
 	request *p.GetAllHistoryTreeBranchesRequest,
 ) (*p.InternalGetAllHistoryTreeBranchesResponse, error) {
+	ctx = gocql.WithOperation(ctx, "GetAllHistoryTreeBranches")
 
 	query := h.Session.Query(v2templateScanAllTreeBranches).WithContext(ctx)
 
@@ -372,6 +381,7 @@ func (h *HistoryStore) GetHistoryTreeContainingBranch(
 	ctx context.Context,
 	request *p.InternalGetHistoryTreeContainingBranchRequest,
 ) (*p.InternalGetHistoryTreeContainingBranchResponse, error) {
+	ctx = gocql.WithOperation(ctx, "GetHistoryTreeContainingBranch")
 
 	branch, err := h.GetHistoryBranchUtil().ParseHistoryBranchInfo(request.BranchToken)
 	if err != nil {
@@ -126,32 +126,61 @@ const (
 		`AND task_id = ? ` +
 		`IF range_id = ?`
 
+	templateListTaskQueuePartitionsQuery = `SELECT DISTINCT ` +
+		`namespace_id, ` +
+		`task_queue_name, ` +
+		`task_queue_type ` +
+		`FROM tasks`
+
 	templateGetTaskQueueUserDataQuery = `SELECT data, data_encoding, version
+	    FROM task_queue_user_data_v2
+		WHERE namespace_id = ? AND bucket = ? AND build_id = ''
+		AND task_queue_name = ?`
+	templateGetLegacyTaskQueueUserDataQuery = `SELECT data, data_encoding, version
 	    FROM task_queue_user_data
 		WHERE namespace_id = ? AND build_id = ''
 		AND task_queue_name = ?`
 
-	templateUpdateTaskQueueUserDataQuery = `UPDATE task_queue_user_data SET
+	templateUpdateTaskQueueUserDataQuery = `UPDATE task_queue_user_data_v2 SET
 		data = ?,
 		data_encoding = ?,
 		version = ?
 		WHERE namespace_id = ?
+		AND bucket = ?
 		AND build_id = ''
 		AND task_queue_name = ?
 		IF version = ? ELSE ERROR`
 
-	templateInsertTaskQueueUserDataQuery = `INSERT INTO task_queue_user_data
-		(namespace_id, build_id, task_queue_name, data, data_encoding, version) VALUES
-		(?           , ''      , ?              , ?   , ?            , 1      ) IF NOT EXISTS ELSE ERROR`
+	templateGetTaskQueueUserDataVersionQuery = `SELECT version
+	    FROM task_queue_user_data_v2
+		WHERE namespace_id = ? AND bucket = ? AND build_id = ''
+		AND task_queue_name = ?`
+	templateGetLegacyTaskQueueUserDataVersionQuery = `SELECT version
+	    FROM task_queue_user_data
+		WHERE namespace_id = ? AND build_id = ''
+		AND task_queue_name = ?`
+
+	templateInsertTaskQueueUserDataQuery = `INSERT INTO task_queue_user_data_v2
+		(namespace_id, bucket, build_id, task_queue_name, data, data_encoding, version) VALUES
+		(?           , ?     , ''      , ?              , ?   , ?            , ?      ) IF NOT EXISTS ELSE ERROR`
+	templateDeleteLegacyTaskQueueUserDataQuery = `DELETE FROM task_queue_user_data
+		WHERE namespace_id = ? AND build_id = '' AND task_queue_name = ?
+		IF version = ? ELSE ERROR`
 
-	templateInsertBuildIdTaskQueueMappingQuery = `INSERT INTO task_queue_user_data
-	(namespace_id, build_id, task_queue_name) VALUES
-	(?           , ?       , ?)`
-	templateDeleteBuildIdTaskQueueMappingQuery = `DELETE FROM task_queue_user_data
+	templateInsertBuildIdTaskQueueMappingQuery = `INSERT INTO task_queue_user_data_v2
+	(namespace_id, bucket, build_id, task_queue_name) VALUES
+	(?           , ?     , ?       , ?)`
+	templateDeleteBuildIdTaskQueueMappingQuery = `DELETE FROM task_queue_user_data_v2
+	WHERE namespace_id = ? AND bucket = ? AND build_id = ? AND task_queue_name = ?`
+	templateDeleteLegacyBuildIdTaskQueueMappingQuery = `DELETE FROM task_queue_user_data
 	WHERE namespace_id = ? AND build_id = ? AND task_queue_name = ?`
-	templateListTaskQueueUserDataQuery       = `SELECT task_queue_name, data, data_encoding, version FROM task_queue_user_data WHERE namespace_id = ? AND build_id = ''`
-	templateListTaskQueueNamesByBuildIdQuery = `SELECT task_queue_name FROM task_queue_user_data WHERE namespace_id = ? AND build_id = ?`
-	templateCountTaskQueueByBuildIdQuery     = `SELECT COUNT(*) FROM task_queue_user_data WHERE namespace_id = ? AND build_id = ?`
+
+	templateListTaskQueueUserDataQuery             = `SELECT task_queue_name, data, data_encoding, version FROM task_queue_user_data_v2 WHERE namespace_id = ? AND bucket = ? AND build_id = ''`
+	templateListLegacyTaskQueueUserDataQuery       = `SELECT task_queue_name, data, data_encoding, version FROM task_queue_user_data WHERE namespace_id = ? AND build_id = ''`
+	templateListTaskQueueNamesByBuildIdQuery       = `SELECT task_queue_name FROM task_queue_user_data_v2 WHERE namespace_id = ? AND bucket = ? AND build_id = ?`
+	templateListLegacyTaskQueueNamesByBuildIdQuery = `SELECT task_queue_name FROM task_queue_user_data WHERE namespace_id = ? AND build_id = ?`
+	templateCountTaskQueueByBuildIdQuery           = `SELECT COUNT(*) FROM task_queue_user_data_v2 WHERE namespace_id = ? AND bucket = ? AND build_id = ?`
+	templateCountLegacyTaskQueueByBuildIdQuery     = `SELECT COUNT(*) FROM task_queue_user_data WHERE namespace_id = ? AND build_id = ?`
 
 	// Not much of a need to make this configurable, we're just reading some strings
 	listTaskQueueNamesByBuildIdPageSize = 100
@@ -161,16 +190,30 @@ type (
 	MatchingTaskStore struct {
 		Session gocql.Session
 		Logger  log.Logger
+
+		// readLegacyUserData is set until the rows of the legacy task_queue_user_data table have been migrated
+		readLegacyUserData bool
 	}
 )
 
 func NewMatchingTaskStore(
 	session gocql.Session,
 	logger log.Logger,
+) *MatchingTaskStore {
+	return newMatchingTaskStore(session, logger, false)
+}
+
+// newMatchingTaskStore returns a matching task store which reads task queue user data from the legacy
+// task_queue_user_data table as well as task_queue_user_data_v2 unless legacyMigrated is set
+func newMatchingTaskStore(
+	session gocql.Session,
+	logger log.Logger,
+	legacyMigrated bool,
 ) *MatchingTaskStore {
 	return &MatchingTaskStore{
-		Session: session,
-		Logger:  logger,
+		Session:            session,
+		Logger:             logger,
+		readLegacyUserData: !legacyMigrated,
 	}
 }
 
@@ -178,6 +221,7 @@ func (d *MatchingTaskStore) CreateTaskQueue(
 	ctx context.Context,
 	request *p.InternalCreateTaskQueueRequest,
 ) error {
+	ctx = gocql.WithOperation(ctx, "CreateTaskQueue")
 	query := d.Session.Query(templateInsertTaskQueueQuery,
 		request.NamespaceID,
 		request.TaskQueue,
@@ -210,6 +254,7 @@ func (d *MatchingTaskStore) GetTaskQueue(
 	ctx context.Context,
 	request *p.InternalGetTaskQueueRequest,
 ) (*p.InternalGetTaskQueueResponse, error) {
+	ctx = gocql.WithOperation(ctx, "GetTaskQueue")
 	query := d.Session.Query(templateGetTaskQueueQuery,
 		request.NamespaceID,
 		request.TaskQueue,
@@ -236,6 +281,7 @@ func (d *MatchingTaskStore) UpdateTaskQueue(
 	ctx context.Context,
 	request *p.InternalUpdateTaskQueueRequest,
 ) (*p.UpdateTaskQueueResponse, error) {
+	ctx = gocql.WithOperation(ctx, "UpdateTaskQueue")
 	var err error
 	if request.TaskQueueKind == enumspb.TASK_QUEUE_KIND_STICKY { // if task_queue is sticky, then update with TTL
 		if request.ExpiryTime == nil {
@@ -296,17 +342,71 @@ func (d *MatchingTaskStore) UpdateTaskQueue(
 	return &p.UpdateTaskQueueResponse{}, nil
 }
 
+// ListTaskQueue pages through the partition keys of the tasks table in token order, a page of at most PageSize
+// task queues at a time, and reads the task queue row of each.  Partitions whose task queue row has been deleted
+// while tasks remain are skipped, so a page may hold fewer items than requested while NextPageToken is still set.
 func (d *MatchingTaskStore) ListTaskQueue(
-	_ context.Context,
-	_ *p.ListTaskQueueRequest,
+	ctx context.Context,
+	request *p.ListTaskQueueRequest,
 ) (*p.InternalListTaskQueueResponse, error) {
-	return nil, serviceerror.NewUnavailable("unsupported operation")
+	ctx = gocql.WithOperation(ctx, "ListTaskQueue")
+	query := d.Session.Query(templateListTaskQueuePartitionsQuery).WithContext(ctx)
+	iter := query.PageSize(request.PageSize).PageState(request.PageToken).Iter()
+
+	var keys []p.TaskQueueKey
+	var namespaceID string
+	var taskQueueName string
+	var taskQueueType int32
+	for iter.Scan(&namespaceID, &taskQueueName, &taskQueueType) {
+		keys = append(keys, p.TaskQueueKey{
+			NamespaceID:   namespaceID,
+			TaskQueueName: taskQueueName,
+			TaskQueueType: enumspb.TaskQueueType(taskQueueType),
+		})
+	}
+
+	response := &p.InternalListTaskQueueResponse{}
+	// contract of this API expect nil as pagination token instead of empty byte array
+	if len(iter.PageState()) > 0 {
+		response.NextPageToken = iter.PageState()
+	}
+
+	if err := iter.Close(); err != nil {
+		return nil, gocql.ConvertError("ListTaskQueue", err)
+	}
+
+	for _, key := range keys {
+		query := d.Session.Query(templateGetTaskQueueQuery,
+			key.NamespaceID,
+			key.TaskQueueName,
+			key.TaskQueueType,
+			rowTypeTaskQueue,
+			taskQueueTaskID,
+		).WithContext(ctx)
+
+		var rangeID int64
+		var tlBytes []byte
+		var tlEncoding string
+		if err := query.Scan(&rangeID, &tlBytes, &tlEncoding); err != nil {
+			if gocql.IsNotFoundError(err) {
+				continue
+			}
+			return nil, gocql.ConvertError("ListTaskQueue", err)
+		}
+		response.Items = append(response.Items, &p.InternalListTaskQueueItem{
+			TaskQueue: p.NewDataBlob(tlBytes, tlEncoding),
+			RangeID:   rangeID,
+		})
+	}
+
+	return response, nil
 }
 
 func (d *MatchingTaskStore) DeleteTaskQueue(
 	ctx context.Context,
 	request *p.DeleteTaskQueueRequest,
 ) error {
+	ctx = gocql.WithOperation(ctx, "DeleteTaskQueue")
 	query := d.Session.Query(
 		templateDeleteTaskQueueQuery,
 		request.TaskQueue.NamespaceID,
@@ -334,6 +434,7 @@ func (d *MatchingTaskStore) CreateTasks(
 	ctx context.Context,
 	request *p.InternalCreateTasksRequest,
 ) (*p.CreateTasksResponse, error) {
+	ctx = gocql.WithOperation(ctx, "CreateTasks")
 	txn := d.Session.NewTxn().WithContext(ctx)
 	namespaceID := request.NamespaceID
 	taskQueue := request.TaskQueue
@@ -413,6 +514,7 @@ func (d *MatchingTaskStore) GetTasks(
 	ctx context.Context,
 	request *p.GetTasksRequest,
 ) (*p.InternalGetTasksResponse, error) {
+	ctx = gocql.WithOperation(ctx, "GetTasks")
 	// Reading taskqueue tasks need to be quorum level consistent, otherwise we could lose tasks
 	query := d.Session.Query(templateGetTasksQuery,
 		request.NamespaceID,
@@ -473,6 +575,7 @@ func (d *MatchingTaskStore) CompleteTasksLessThan(
 	ctx context.Context,
 	request *p.CompleteTasksLessThanRequest,
 ) (int, error) {
+	ctx = gocql.WithOperation(ctx, "CompleteTasksLessThan")
 	query := d.Session.Query(
 		templateCompleteTasksLessThanQuery,
 		request.NamespaceID,
@@ -488,18 +591,30 @@ func (d *MatchingTaskStore) CompleteTasksLessThan(
 	return p.UnknownNumRowsAffected, nil
 }
 
+// GetTaskQueueUserData reads the user data from the task queue's bucket, falling back to the legacy
+// partition for task queues which have not been updated or migrated since task_queue_user_data_v2 was introduced
 func (d *MatchingTaskStore) GetTaskQueueUserData(
 	ctx context.Context,
 	request *p.GetTaskQueueUserDataRequest,
 ) (*p.InternalGetTaskQueueUserDataResponse, error) {
-	query := d.Session.Query(templateGetTaskQueueUserDataQuery,
-		request.NamespaceID,
-		request.TaskQueue,
-	).WithContext(ctx)
+	ctx = gocql.WithOperation(ctx, "GetTaskQueueUserData")
 	var version int64
 	var userDataBytes []byte
 	var encoding string
-	if err := query.Scan(&userDataBytes, &encoding, &version); err != nil {
+
+	var err error
+	for _, bucket := range d.userDataBuckets(request.TaskQueue) {
+		query := d.userDataQuery(ctx, bucket,
+			templateGetTaskQueueUserDataQuery,
+			templateGetLegacyTaskQueueUserDataQuery,
+			request.NamespaceID,
+			request.TaskQueue,
+		)
+		if err = query.Scan(&userDataBytes, &encoding, &version); !gocql.IsNotFoundError(err) {
+			break
+		}
+	}
+	if err != nil {
 		return nil, gocql.ConvertError("GetTaskQueueData", err)
 	}
 
@@ -509,108 +624,241 @@ func (d *MatchingTaskStore) GetTaskQueueUserData(
 	}, nil
 }
 
+// UpdateTaskQueueUserData applies every update in a single transaction, each conditioned on the version the caller
+// expects.  User data still held in the legacy partition fails that condition; when the versions re-read after the
+// failure show that nothing else conflicted, the transaction is retried moving those task queues into their bucket,
+// with the conditional delete of the legacy row standing in for the version check.
 func (d *MatchingTaskStore) UpdateTaskQueueUserData(
 	ctx context.Context,
 	request *p.InternalUpdateTaskQueueUserDataRequest,
+) error {
+	ctx = gocql.WithOperation(ctx, "UpdateTaskQueueUserData")
+	var legacy map[string]bool
+	for {
+		err := d.applyTaskQueueUserDataUpdates(ctx, request, legacy)
+		for _, update := range request.Updates {
+			if update.Applied != nil {
+				*update.Applied = err == nil
+			}
+		}
+		if err == nil {
+			return nil
+		}
+		if !gocql.ConflictError(err) {
+			return gocql.ConvertError("UpdateTaskQueueUserData", err)
+		}
+
+		conflicting, inLegacy, err := d.markConflictingUserData(ctx, request)
+		if err != nil {
+			return err
+		}
+		if len(conflicting) > 0 || len(inLegacy) == 0 || legacy != nil {
+			return &p.ConditionFailedError{
+				Msg: fmt.Sprintf("Failed to update task queues: conflicting task queues %v", conflicting),
+			}
+		}
+		legacy = inLegacy
+	}
+}
+
+// applyTaskQueueUserDataUpdates executes the updates of a request in a single transaction, moving the task queues
+// in legacy out of the legacy partition
+func (d *MatchingTaskStore) applyTaskQueueUserDataUpdates(
+	ctx context.Context,
+	request *p.InternalUpdateTaskQueueUserDataRequest,
+	legacy map[string]bool,
 ) error {
 	txn := d.Session.NewTxn().WithContext(ctx)
 
 	for taskQueue, update := range request.Updates {
-		if update.Version == 0 {
+		bucket := taskQueueUserDataBucket(taskQueue)
+
+		switch {
+		case update.Version == 0:
 			txn.Query(templateInsertTaskQueueUserDataQuery,
 				request.NamespaceID,
+				bucket,
 				taskQueue,
 				update.UserData.Data,
 				update.UserData.EncodingType.String(),
+				1,
 			)
-		} else {
+		case legacy[taskQueue]:
+			txn.Query(templateDeleteLegacyTaskQueueUserDataQuery,
+				request.NamespaceID,
+				taskQueue,
+				update.Version,
+			)
+			txn.Query(templateInsertTaskQueueUserDataQuery,
+				request.NamespaceID,
+				bucket,
+				taskQueue,
+				update.UserData.Data,
+				update.UserData.EncodingType.String(),
+				update.Version+1,
+			)
+		default:
 			txn.Query(templateUpdateTaskQueueUserDataQuery,
 				update.UserData.Data,
 				update.UserData.EncodingType.String(),
 				update.Version+1,
 				request.NamespaceID,
+				bucket,
 				taskQueue,
 				update.Version,
 			)
 		}
+
+		// legacy mappings are dropped as their task queue is touched so a mapping is never counted twice
 		for _, buildId := range update.BuildIdsAdded {
-			txn.Query(templateInsertBuildIdTaskQueueMappingQuery, request.NamespaceID, buildId, taskQueue)
+			txn.Query(templateInsertBuildIdTaskQueueMappingQuery, request.NamespaceID, bucket, buildId, taskQueue)
+			if d.readLegacyUserData {
+				txn.Query(templateDeleteLegacyBuildIdTaskQueueMappingQuery, request.NamespaceID, buildId, taskQueue)
+			}
 		}
 		for _, buildId := range update.BuildIdsRemoved {
-			txn.Query(templateDeleteBuildIdTaskQueueMappingQuery, request.NamespaceID, buildId, taskQueue)
+			txn.Query(templateDeleteBuildIdTaskQueueMappingQuery, request.NamespaceID, bucket, buildId, taskQueue)
+			if d.readLegacyUserData {
+				txn.Query(templateDeleteLegacyBuildIdTaskQueueMappingQuery, request.NamespaceID, buildId, taskQueue)
+			}
 		}
 	}
 
-	err := txn.Exec()
-	for _, update := range request.Updates {
-		if update.Applied != nil {
-			*update.Applied = err == nil
+	return txn.Exec()
+}
+
+// markConflictingUserData re-reads the version of every task queue in a failed update and flags the ones
+// whose stored version no longer matches the version the caller expected. The names of the conflicting
+// task queues are returned, along with the task queues whose expected version is held in the legacy partition.
+func (d *MatchingTaskStore) markConflictingUserData(
+	ctx context.Context,
+	request *p.InternalUpdateTaskQueueUserDataRequest,
+) ([]string, map[string]bool, error) {
+	var conflicting []string
+	inLegacy := make(map[string]bool)
+	for taskQueue, update := range request.Updates {
+		version, legacy, err := d.getTaskQueueUserDataVersion(ctx, request.NamespaceID, taskQueue)
+		if err != nil {
+			return nil, nil, gocql.ConvertError("UpdateTaskQueueUserData", err)
+		}
+
+		conflict := version != update.Version
+		if update.Conflicting != nil {
+			*update.Conflicting = conflict
+		}
+		if conflict {
+			conflicting = append(conflicting, taskQueue)
+		} else if legacy {
+			inLegacy[taskQueue] = true
 		}
 	}
 
-	if err != nil {
-		if gocql.ConflictError(err) {
-			for _, update := range request.Updates {
-				if update.Conflicting != nil {
-					*update.Conflicting = true
-				}
-			}
+	return conflicting, inLegacy, nil
+}
 
-			return &p.ConditionFailedError{Msg: "Failed to update task queues: unknown conflict"}
+// getTaskQueueUserDataVersion returns the stored version of a task queue's user data, or 0 if it has none,
+// and whether the row is still held in the legacy partition
+func (d *MatchingTaskStore) getTaskQueueUserDataVersion(
+	ctx context.Context,
+	namespaceID string,
+	taskQueue string,
+) (int64, bool, error) {
+	for _, bucket := range d.userDataBuckets(taskQueue) {
+		var version int64
+		err := d.userDataQuery(ctx, bucket,
+			templateGetTaskQueueUserDataVersionQuery,
+			templateGetLegacyTaskQueueUserDataVersionQuery,
+			namespaceID,
+			taskQueue,
+		).Scan(&version)
+		if err == nil {
+			return version, bucket == legacyTaskQueueUserDataBucket, nil
+		}
+		if !gocql.IsNotFoundError(err) {
+			return 0, false, err
 		}
-
-		return err
 	}
 
-	return nil
+	return 0, false, nil
 }
 
+// ListTaskQueueUserDataEntries reads the buckets of the namespace in order, starting with the legacy
+// partition unless it has been migrated. The page token records the bucket and the page state within it.
 func (d *MatchingTaskStore) ListTaskQueueUserDataEntries(ctx context.Context, request *p.ListTaskQueueUserDataEntriesRequest) (*p.InternalListTaskQueueUserDataEntriesResponse, error) {
-	query := d.Session.Query(templateListTaskQueueUserDataQuery, request.NamespaceID).WithContext(ctx)
-	iter := query.PageSize(request.PageSize).PageState(request.NextPageToken).Iter()
+	ctx = gocql.WithOperation(ctx, "ListTaskQueueUserDataEntries")
+	token, err := decodeUserDataPageToken(request.NextPageToken)
+	if err != nil {
+		return nil, err
+	}
+	if token.Bucket == legacyTaskQueueUserDataBucket && !d.readLegacyUserData {
+		token = userDataPageToken{Bucket: 0}
+	}
 
 	response := &p.InternalListTaskQueueUserDataEntriesResponse{}
-	row := make(map[string]interface{})
-	for iter.MapScan(row) {
-		taskQueue, err := getTypedFieldFromRow[string]("task_queue_name", row)
-		if err != nil {
-			return nil, err
-		}
-		data, err := getTypedFieldFromRow[[]byte]("data", row)
-		if err != nil {
-			return nil, err
-		}
-		dataEncoding, err := getTypedFieldFromRow[string]("data_encoding", row)
-		if err != nil {
-			return nil, err
-		}
-		version, err := getTypedFieldFromRow[int64]("version", row)
-		if err != nil {
-			return nil, err
+	for token.Bucket < taskQueueUserDataBuckets && len(response.Entries) < request.PageSize {
+		query := d.userDataQuery(ctx, token.Bucket,
+			templateListTaskQueueUserDataQuery,
+			templateListLegacyTaskQueueUserDataQuery,
+			request.NamespaceID,
+		)
+		iter := query.PageSize(request.PageSize - len(response.Entries)).PageState(token.PageState).Iter()
+
+		row := make(map[string]interface{})
+		for iter.MapScan(row) {
+			taskQueue, err := getTypedFieldFromRow[string]("task_queue_name", row)
+			if err != nil {
+				return nil, err
+			}
+			data, err := getTypedFieldFromRow[[]byte]("data", row)
+			if err != nil {
+				return nil, err
+			}
+			dataEncoding, err := getTypedFieldFromRow[string]("data_encoding", row)
+			if err != nil {
+				return nil, err
+			}
+			version, err := getTypedFieldFromRow[int64]("version", row)
+			if err != nil {
+				return nil, err
+			}
+
+			response.Entries = append(response.Entries, p.InternalTaskQueueUserDataEntry{TaskQueue: taskQueue, Data: p.NewDataBlob(data, dataEncoding), Version: version})
+
+			row = make(map[string]interface{}) // Reinitialize map as initialized fails on unmarshalling
 		}
+		pageState := iter.PageState()
 
-		response.Entries = append(response.Entries, p.InternalTaskQueueUserDataEntry{TaskQueue: taskQueue, Data: p.NewDataBlob(data, dataEncoding), Version: version})
+		if err := iter.Close(); err != nil {
+			return nil, gocql.ConvertError("ListTaskQueueUserDataEntries", err)
+		}
 
-		row = make(map[string]interface{}) // Reinitialize map as initialized fails on unmarshalling
-	}
-	if len(iter.PageState()) > 0 {
-		response.NextPageToken = iter.PageState()
+		if len(pageState) > 0 {
+			token.PageState = pageState
+		} else {
+			token = userDataPageToken{Bucket: token.Bucket + 1}
+		}
 	}
 
-	if err := iter.Close(); err != nil {
-		return nil, serviceerror.NewUnavailable(fmt.Sprintf("ListTaskQueueUserDataEntries operation failed. Error: %v", err))
+	if response.NextPageToken, err = token.encode(); err != nil {
+		return nil, serviceerror.NewInternal(fmt.Sprintf("ListTaskQueueUserDataEntries failed to encode page token: %v", err))
 	}
 	return response, nil
 }
 
 func (d *MatchingTaskStore) GetTaskQueuesByBuildId(ctx context.Context, request *p.GetTaskQueuesByBuildIdRequest) ([]string, error) {
-	query := d.Session.Query(templateListTaskQueueNamesByBuildIdQuery, request.NamespaceID, request.BuildID).WithContext(ctx)
-	iter := query.PageSize(listTaskQueueNamesByBuildIdPageSize).Iter()
-
+	ctx = gocql.WithOperation(ctx, "GetTaskQueuesByBuildId")
 	var taskQueues []string
-	row := make(map[string]interface{})
+	for _, bucket := range d.namespaceUserDataBuckets() {
+		query := d.userDataQuery(ctx, bucket,
+			templateListTaskQueueNamesByBuildIdQuery,
+			templateListLegacyTaskQueueNamesByBuildIdQuery,
+			request.NamespaceID,
+			request.BuildID,
+		)
+		// the iterator fetches subsequent pages itself, the page size only bounds each round trip
+		iter := query.PageSize(listTaskQueueNamesByBuildIdPageSize).Iter()
 
-	for {
+		row := make(map[string]interface{})
 		for iter.MapScan(row) {
 			taskQueueRaw, ok := row["task_queue_name"]
 			if !ok {
@@ -626,22 +874,65 @@ func (d *MatchingTaskStore) GetTaskQueuesByBuildId(ctx context.Context, request
 
 			row = make(map[string]interface{}) // Reinitialize map as initialized fails on unmarshalling
 		}
-		if len(iter.PageState()) == 0 {
-			break
-		}
-	}
 
-	if err := iter.Close(); err != nil {
-		return nil, serviceerror.NewUnavailable(fmt.Sprintf("GetTaskQueuesByBuildId operation failed. Error: %v", err))
+		if err := iter.Close(); err != nil {
+			return nil, gocql.ConvertError("GetTaskQueuesByBuildId", err)
+		}
 	}
 	return taskQueues, nil
 }
 
 func (d *MatchingTaskStore) CountTaskQueuesByBuildId(ctx context.Context, request *p.CountTaskQueuesByBuildIdRequest) (int, error) {
-	var count int
-	query := d.Session.Query(templateCountTaskQueueByBuildIdQuery, request.NamespaceID, request.BuildID).WithContext(ctx)
-	err := query.Scan(&count)
-	return count, err
+	ctx = gocql.WithOperation(ctx, "CountTaskQueuesByBuildId")
+	total := 0
+	for _, bucket := range d.namespaceUserDataBuckets() {
+		var count int
+		query := d.userDataQuery(ctx, bucket,
+			templateCountTaskQueueByBuildIdQuery,
+			templateCountLegacyTaskQueueByBuildIdQuery,
+			request.NamespaceID,
+			request.BuildID,
+		)
+		if err := query.Scan(&count); err != nil {
+			return 0, gocql.ConvertError("CountTaskQueuesByBuildId", err)
+		}
+		total += count
+	}
+	return total, nil
+}
+
+// userDataBuckets returns the partitions which may hold the user data of a task queue, in the order to read them
+func (d *MatchingTaskStore) userDataBuckets(taskQueue string) []int {
+	if d.readLegacyUserData {
+		return []int{taskQueueUserDataBucket(taskQueue), legacyTaskQueueUserDataBucket}
+	}
+	return []int{taskQueueUserDataBucket(taskQueue)}
+}
+
+// namespaceUserDataBuckets returns the partitions holding the task queue user data of a namespace in listing order
+func (d *MatchingTaskStore) namespaceUserDataBuckets() []int {
+	buckets := taskQueueUserDataBucketsWithLegacy()
+	if d.readLegacyUserData {
+		return buckets
+	}
+	return buckets[1:]
+}
+
+// userDataQuery addresses a statement to one partition of a namespace's task queue user data. Statements
+// for a bucket take the namespace and bucket as their first operands, statements for the legacy partition
+// take only the namespace.
+func (d *MatchingTaskStore) userDataQuery(
+	ctx context.Context,
+	bucket int,
+	template string,
+	legacyTemplate string,
+	namespaceID string,
+	args ...interface{},
+) gocql.Query {
+	if bucket == legacyTaskQueueUserDataBucket {
+		return d.Session.Query(legacyTemplate, append([]interface{}{namespaceID}, args...)...).WithContext(ctx)
+	}
+	return d.Session.Query(template, append([]interface{}{namespaceID, bucket}, args...)...).WithContext(ctx)
 }
 
 func (d *MatchingTaskStore) GetName() string {
@@ -97,6 +97,7 @@ func (m *MetadataStore) CreateNamespace(
 	ctx context.Context,
 	request *p.InternalCreateNamespaceRequest,
 ) (*p.CreateNamespaceResponse, error) {
+	ctx = gocql.WithOperation(ctx, "CreateNamespace")
 	metadata, err := m.GetMetadata(ctx)
 	if err != nil {
 		return nil, err
@@ -130,6 +131,7 @@ func (m *MetadataStore) UpdateNamespace(
 	ctx context.Context,
 	request *p.InternalUpdateNamespaceRequest,
 ) error {
+	ctx = gocql.WithOperation(ctx, "UpdateNamespace")
 	txn := m.session.NewTxn().WithContext(ctx)
 	txn.Query(templateUpdateNamespace,
 		request.Namespace.Data,
@@ -155,6 +157,7 @@ func (m *MetadataStore) RenameNamespace(
 	ctx context.Context,
 	request *p.InternalRenameNamespaceRequest,
 ) error {
+	ctx = gocql.WithOperation(ctx, "RenameNamespace")
 	txn := m.session.NewTxn().WithContext(ctx)
 	txn.Query(templateDeleteNamespaceWithGuard, request.PreviousName)
 	txn.Query(templateCreateNamespace,
@@ -181,6 +184,7 @@ func (m *MetadataStore) GetNamespace(
 	ctx context.Context,
 	request *p.GetNamespaceRequest,
 ) (*p.InternalGetNamespaceResponse, error) {
+	ctx = gocql.WithOperation(ctx, "GetNamespace")
 	var query gocql.Query
 	var err error
 	var detail []byte
@@ -239,6 +243,7 @@ func (m *MetadataStore) ListNamespaces(
 	ctx context.Context,
 	request *p.InternalListNamespacesRequest,
 ) (*p.InternalListNamespacesResponse, error) {
+	ctx = gocql.WithOperation(ctx, "ListNamespaces")
 	query := m.session.Query(templateListNamespaces).WithContext(ctx)
 	pageSize := request.PageSize
 	nextPageToken := request.NextPageToken
@@ -304,6 +309,7 @@ func (m *MetadataStore) DeleteNamespace(
 	ctx context.Context,
 	request *p.DeleteNamespaceRequest,
 ) error {
+	ctx = gocql.WithOperation(ctx, "DeleteNamespace")
 	var name string
 	query := m.session.Query(templateGetNamespaceNameById, request.ID).WithContext(ctx)
 	err := query.Scan(&name)
@@ -322,6 +328,7 @@ func (m *MetadataStore) DeleteNamespaceByName(
 	ctx context.Context,
 	request *p.DeleteNamespaceByNameRequest,
 ) error {
+	ctx = gocql.WithOperation(ctx, "DeleteNamespaceByName")
 	query := m.session.Query(templateDeleteNamespace, request.Name).WithContext(ctx)
 	err := query.Exec()
 	if err != nil {
@@ -336,6 +343,7 @@ func (m *MetadataStore) DeleteNamespaceByName(
 func (m *MetadataStore) GetMetadata(
 	ctx context.Context,
 ) (*p.GetMetadataResponse, error) {
+	ctx = gocql.WithOperation(ctx, "GetMetadata")
 	var notificationVersion int64
 	query := m.session.Query(templateGetMetadata, namespaceMetadataRecordName).WithContext(ctx)
 	err := query.Scan(&notificationVersion)
@@ -295,12 +295,20 @@ const (
 type (
 	MutableStateStore struct {
 		Session gocql.Session
+		layout  executionLayout
 	}
 )
 
 func NewMutableStateStore(session gocql.Session) *MutableStateStore {
+	return newMutableStateStore(session, executionLayout{})
+}
+
+// newMutableStateStore returns a mutable state store writing tasks to the given bucketed layouts, if any, rather than
+// to the timers and system_tasks tables, and the maps of workflows to their map tables if enabled
+func newMutableStateStore(session gocql.Session, layout executionLayout) *MutableStateStore {
 	return &MutableStateStore{
 		Session: session,
+		layout:  layout,
 	}
 }
 
@@ -447,6 +455,8 @@ func (d *MutableStateStore) CreateWorkflowExecution(
 	ctx context.Context,
 	request *p.InternalCreateWorkflowExecutionRequest,
 ) (*p.InternalCreateWorkflowExecutionResponse, error) {
+	ctx = gocql.WithOperation(ctx, "CreateWorkflowExecution")
+	ctx = workflowLogContext(ctx, request.ShardID, request.NewWorkflowSnapshot.NamespaceID, request.NewWorkflowSnapshot.WorkflowID)
 	txn := d.Session.NewTxn().WithContext(ctx)
 	validator := NewPostQueryValidation()
 
@@ -504,6 +514,7 @@ func (d *MutableStateStore) CreateWorkflowExecution(
 	}
 
 	if err := applyWorkflowSnapshotTxnAsNew(txn,
+		d.layout,
 		request.ShardID,
 		&newWorkflow,
 	); err != nil {
@@ -545,6 +556,8 @@ func (d *MutableStateStore) GetWorkflowExecution(
 	ctx context.Context,
 	request *p.GetWorkflowExecutionRequest,
 ) (*p.InternalGetWorkflowExecutionResponse, error) {
+	ctx = gocql.WithOperation(ctx, "GetWorkflowExecution")
+	ctx = workflowLogContext(ctx, request.ShardID, request.NamespaceID, request.WorkflowID)
 	query := d.Session.Query(templateGetWorkflowExecutionQuery,
 		request.ShardID,
 		request.NamespaceID,
@@ -553,8 +566,18 @@ func (d *MutableStateStore) GetWorkflowExecution(
 	).WithContext(ctx)
 
 	result := make(map[string]interface{})
-	if err := query.MapScan(result); err != nil {
-		return nil, gocql.ConvertError("GetWorkflowExecution", err)
+	reads := NewPostQueryValidation()
+	reads.Add(func() error {
+		return gocql.ConvertError("GetWorkflowExecution", query.MapScan(result))
+	})
+
+	var tables *executionMaps
+	if d.layout.mapTables {
+		tables = readExecutionMapTables(ctx, d.Session, reads, request)
+	}
+
+	if err := reads.Validate(); err != nil {
+		return nil, err
 	}
 
 	state, err := mutableStateFromRow(result)
@@ -611,6 +634,10 @@ func (d *MutableStateStore) GetWorkflowExecution(
 	}
 	state.BufferedEvents = bufferedEventsBlobs
 
+	if tables != nil {
+		tables.mergeInto(state)
+	}
+
 	state.Checksum = p.NewDataBlob(result["checksum"].([]byte), result["checksum_encoding"].(string))
 
 	dbVersion := int64(0)
@@ -630,6 +657,8 @@ func (d *MutableStateStore) UpdateWorkflowExecution(
 	ctx context.Context,
 	request *p.InternalUpdateWorkflowExecutionRequest,
 ) error {
+	ctx = gocql.WithOperation(ctx, "UpdateWorkflowExecution")
+	ctx = workflowLogContext(ctx, request.ShardID, request.UpdateWorkflowMutation.NamespaceID, request.UpdateWorkflowMutation.WorkflowID)
 	txn := d.Session.NewTxn().WithContext(ctx)
 	validator := NewPostQueryValidation()
 
@@ -717,11 +746,12 @@ func (d *MutableStateStore) UpdateWorkflowExecution(
 		return serviceerror.NewInternal(fmt.Sprintf("UpdateWorkflowExecution: unknown mode: %v", request.Mode))
 	}
 
-	if err := applyWorkflowMutationTxn(txn, shardID, &updateWorkflow); err != nil {
+	if err := applyWorkflowMutationTxn(txn, d.layout, shardID, &updateWorkflow); err != nil {
 		return err
 	}
 	if newWorkflow != nil {
 		if err := applyWorkflowSnapshotTxnAsNew(txn,
+			d.layout,
 			request.ShardID,
 			newWorkflow,
 		); err != nil {
@@ -764,6 +794,8 @@ func (d *MutableStateStore) ConflictResolveWorkflowExecution(
 	ctx context.Context,
 	request *p.InternalConflictResolveWorkflowExecutionRequest,
 ) error {
+	ctx = gocql.WithOperation(ctx, "ConflictResolveWorkflowExecution")
+	ctx = workflowLogContext(ctx, request.ShardID, request.ResetWorkflowSnapshot.NamespaceID, request.ResetWorkflowSnapshot.WorkflowID)
 	txn := d.Session.NewTxn().WithContext(ctx)
 	validator := NewPostQueryValidation()
 
@@ -836,17 +868,17 @@ func (d *MutableStateStore) ConflictResolveWorkflowExecution(
 		return serviceerror.NewInternal(fmt.Sprintf("ConflictResolveWorkflowExecution: unknown mode: %v", request.Mode))
 	}
 
-	if err := applyWorkflowSnapshotTxnAsReset(txn, shardID, &resetWorkflow); err != nil {
+	if err := applyWorkflowSnapshotTxnAsReset(txn, d.layout, shardID, &resetWorkflow); err != nil {
 		return err
 	}
 
 	if currentWorkflow != nil {
-		if err := applyWorkflowMutationTxn(txn, shardID, currentWorkflow); err != nil {
+		if err := applyWorkflowMutationTxn(txn, d.layout, shardID, currentWorkflow); err != nil {
 			return err
 		}
 	}
 	if newWorkflow != nil {
-		if err := applyWorkflowSnapshotTxnAsNew(txn, shardID, newWorkflow); err != nil {
+		if err := applyWorkflowSnapshotTxnAsNew(txn, d.layout, shardID, newWorkflow); err != nil {
 			return err
 		}
 	}
@@ -930,6 +962,19 @@ func (d *MutableStateStore) DeleteWorkflowExecution(
 	ctx context.Context,
 	request *p.DeleteWorkflowExecutionRequest,
 ) error {
+	ctx = gocql.WithOperation(ctx, "DeleteWorkflowExecution")
+	if d.layout.mapTables {
+		txn := d.Session.NewTxn().WithContext(ctx)
+		txn.Query(templateDeleteWorkflowExecutionMutableStateQuery,
+			request.ShardID,
+			request.NamespaceID,
+			request.WorkflowID,
+			request.RunID,
+		)
+		deleteExecutionMapTables(txn, request.ShardID, request.NamespaceID, request.WorkflowID, request.RunID)
+		return gocql.ConvertError("DeleteWorkflowExecution", txn.Exec())
+	}
+
 	query := d.Session.Query(templateDeleteWorkflowExecutionMutableStateQuery,
 		request.ShardID,
 		request.NamespaceID,
@@ -945,6 +990,7 @@ func (d *MutableStateStore) DeleteCurrentWorkflowExecution(
 	ctx context.Context,
 	request *p.DeleteCurrentWorkflowExecutionRequest,
 ) error {
+	ctx = gocql.WithOperation(ctx, "DeleteCurrentWorkflowExecution")
 	query := d.Session.Query(templateDeleteCurrentWorkflowExecutionQuery,
 		request.ShardID,
 		request.NamespaceID,
@@ -960,6 +1006,7 @@ func (d *MutableStateStore) GetCurrentExecution(
 	ctx context.Context,
 	request *p.GetCurrentExecutionRequest,
 ) (*p.InternalGetCurrentExecutionResponse, error) {
+	ctx = gocql.WithOperation(ctx, "GetCurrentExecution")
 	query := d.Session.Query(templateGetCurrentExecutionQuery,
 		request.ShardID,
 		request.NamespaceID,
@@ -993,13 +1040,15 @@ func (d *MutableStateStore) SetWorkflowExecution(
 	ctx context.Context,
 	request *p.InternalSetWorkflowExecutionRequest,
 ) error {
+	ctx = gocql.WithOperation(ctx, "SetWorkflowExecution")
+	ctx = workflowLogContext(ctx, request.ShardID, request.SetWorkflowSnapshot.NamespaceID, request.SetWorkflowSnapshot.WorkflowID)
 	txn := d.Session.NewTxn().WithContext(ctx)
 	validator := NewPostQueryValidation()
 
 	shardID := request.ShardID
 	setSnapshot := request.SetWorkflowSnapshot
 
-	if err := applyWorkflowSnapshotTxnAsReset(txn, shardID, &setSnapshot); err != nil {
+	if err := applyWorkflowSnapshotTxnAsReset(txn, d.layout, shardID, &setSnapshot); err != nil {
 		return err
 	}
 
@@ -1039,6 +1088,7 @@ func (d *MutableStateStore) ListConcreteExecutions(
 	ctx context.Context,
 	request *p.ListConcreteExecutionsRequest,
 ) (*p.InternalListConcreteExecutionsResponse, error) {
+	ctx = gocql.WithOperation(ctx, "ListConcreteExecutions")
 	query := d.Session.Query(templateListWorkflowExecutionQuery,
 		request.ShardID,
 	).WithContext(ctx)
@@ -31,6 +31,7 @@ import (
 	"github.com/manetu/temporal-yugabyte/utils/gocql"
 	"time"
 
+	"go.temporal.io/api/serviceerror"
 	p "go.temporal.io/server/common/persistence"
 
 	"go.temporal.io/server/common/persistence/serialization"
@@ -146,12 +147,20 @@ const (
 type (
 	MutableStateTaskStore struct {
 		Session gocql.Session
+		layout  executionLayout
 	}
 )
 
 func NewMutableStateTaskStore(session gocql.Session) *MutableStateTaskStore {
+	return newMutableStateTaskStore(session, executionLayout{})
+}
+
+// newMutableStateTaskStore returns a task store reading tasks from the given bucketed layouts, if any, as well as
+// from the timers and system_tasks tables
+func newMutableStateTaskStore(session gocql.Session, layout executionLayout) *MutableStateTaskStore {
 	return &MutableStateTaskStore{
 		Session: session,
+		layout:  layout,
 	}
 }
 
@@ -159,10 +168,12 @@ func (d *MutableStateTaskStore) AddHistoryTasks(
 	ctx context.Context,
 	request *p.InternalAddHistoryTasksRequest,
 ) error {
+	ctx = gocql.WithOperation(ctx, "AddHistoryTasks")
 	txn := d.Session.NewTxn().WithContext(ctx)
 
 	if err := applyTasks(
 		txn,
+		d.layout,
 		request.ShardID,
 		request.Tasks,
 	); err != nil {
@@ -206,6 +217,7 @@ func (d *MutableStateTaskStore) GetHistoryTasks(
 	ctx context.Context,
 	request *p.GetHistoryTasksRequest,
 ) (*p.InternalGetHistoryTasksResponse, error) {
+	ctx = gocql.WithOperation(ctx, "GetHistoryTasks")
 	switch request.TaskCategory.ID() {
 	case tasks.CategoryIDTransfer:
 		return d.getTransferTasks(ctx, request)
@@ -224,6 +236,7 @@ func (d *MutableStateTaskStore) CompleteHistoryTask(
 	ctx context.Context,
 	request *p.CompleteHistoryTaskRequest,
 ) error {
+	ctx = gocql.WithOperation(ctx, "CompleteHistoryTask")
 	switch request.TaskCategory.ID() {
 	case tasks.CategoryIDTransfer:
 		return d.completeTransferTask(ctx, request)
@@ -242,6 +255,7 @@ func (d *MutableStateTaskStore) RangeCompleteHistoryTasks(
 	ctx context.Context,
 	request *p.RangeCompleteHistoryTasksRequest,
 ) error {
+	ctx = gocql.WithOperation(ctx, "RangeCompleteHistoryTasks")
 	switch request.TaskCategory.ID() {
 	case tasks.CategoryIDTransfer:
 		return d.rangeCompleteTransferTasks(ctx, request)
@@ -262,6 +276,10 @@ func (d *MutableStateTaskStore) getTransferTasks(
 ) (*p.InternalGetHistoryTasksResponse, error) {
 
 	// Reading transfer tasks need to be quorum level consistent, otherwise we could lose task
+	if d.layout.systemTasks != nil {
+		return d.getBucketedSystemTasks(ctx, request, systemTaskTransfer, "GetTransferTasks")
+	}
+
 	query := d.Session.Query(templateGetTransferTasksQuery,
 		request.ShardID,
 		systemTaskTransfer,
@@ -307,6 +325,9 @@ func (d *MutableStateTaskStore) completeTransferTask(
 	).WithContext(ctx)
 
 	err := query.Exec()
+	if err == nil && d.layout.systemTasks != nil {
+		err = d.completeBucketedSystemTask(ctx, request.ShardID, systemTaskTransfer, request.TaskKey.TaskID)
+	}
 	return gocql.ConvertError("CompleteTransferTask", err)
 }
 
@@ -322,6 +343,9 @@ func (d *MutableStateTaskStore) rangeCompleteTransferTasks(
 	).WithContext(ctx)
 
 	err := query.Exec()
+	if err == nil && d.layout.systemTasks != nil {
+		err = d.rangeCompleteBucketedSystemTasks(ctx, request.ShardID, systemTaskTransfer, request.InclusiveMinTaskKey.TaskID, request.ExclusiveMaxTaskKey.TaskID)
+	}
 	return gocql.ConvertError("RangeCompleteTransferTask", err)
 }
 
@@ -330,6 +354,10 @@ func (d *MutableStateTaskStore) getTimerTasks(
 	request *p.GetHistoryTasksRequest,
 ) (*p.InternalGetHistoryTasksResponse, error) {
 	// Reading timer tasks need to be quorum level consistent, otherwise we could lose tasks
+	if d.layout.timers != nil {
+		return d.getBucketedTimerTasks(ctx, request, rowTypeTimerTask, templateGetTimerTasksQuery, "GetTimerTasks")
+	}
+
 	minTimestamp := p.UnixMilliseconds(request.InclusiveMinTaskKey.FireTime)
 	maxTimestamp := p.UnixMilliseconds(request.ExclusiveMaxTaskKey.FireTime)
 	query := d.Session.Query(templateGetTimerTasksQuery,
@@ -381,6 +409,9 @@ func (d *MutableStateTaskStore) completeTimerTask(
 	).WithContext(ctx)
 
 	err := query.Exec()
+	if err == nil && d.layout.timers != nil {
+		err = d.completeBucketedTimerTask(ctx, request.ShardID, rowTypeTimerTask, ts, request.TaskKey.TaskID)
+	}
 	return gocql.ConvertError("CompleteTimerTask", err)
 }
 
@@ -398,6 +429,9 @@ func (d *MutableStateTaskStore) rangeCompleteTimerTasks(
 	).WithContext(ctx)
 
 	err := query.Exec()
+	if err == nil && d.layout.timers != nil {
+		err = d.rangeCompleteBucketedTimerTasks(ctx, request.ShardID, rowTypeTimerTask, start, end)
+	}
 	return gocql.ConvertError("RangeCompleteTimerTask", err)
 }
 
@@ -407,6 +441,10 @@ func (d *MutableStateTaskStore) getReplicationTasks(
 ) (*p.InternalGetHistoryTasksResponse, error) {
 
 	// Reading replication tasks need to be quorum level consistent, otherwise we could lose task
+	if d.layout.systemTasks != nil {
+		return d.getBucketedSystemTasks(ctx, request, systemTaskReplication, "GetReplicationTasks")
+	}
+
 	query := d.Session.Query(templateGetReplicationTasksQuery,
 		request.ShardID,
 		systemTaskReplication,
@@ -428,6 +466,9 @@ func (d *MutableStateTaskStore) completeReplicationTask(
 	).WithContext(ctx)
 
 	err := query.Exec()
+	if err == nil && d.layout.systemTasks != nil {
+		err = d.completeBucketedSystemTask(ctx, request.ShardID, systemTaskReplication, request.TaskKey.TaskID)
+	}
 	return gocql.ConvertError("CompleteReplicationTask", err)
 }
 
@@ -443,6 +484,9 @@ func (d *MutableStateTaskStore) rangeCompleteReplicationTasks(
 	).WithContext(ctx)
 
 	err := query.Exec()
+	if err == nil && d.layout.systemTasks != nil {
+		err = d.rangeCompleteBucketedSystemTasks(ctx, request.ShardID, systemTaskReplication, request.InclusiveMinTaskKey.TaskID, request.ExclusiveMaxTaskKey.TaskID)
+	}
 	return gocql.ConvertError("RangeCompleteReplicationTask", err)
 }
 
@@ -450,6 +494,7 @@ func (d *MutableStateTaskStore) PutReplicationTaskToDLQ(
 	ctx context.Context,
 	request *p.PutReplicationTaskToDLQRequest,
 ) error {
+	ctx = gocql.WithOperation(ctx, "PutReplicationTaskToDLQ")
 	task := request.TaskInfo
 	datablob, err := serialization.ReplicationTaskInfoToBlob(task)
 	if err != nil {
@@ -477,6 +522,7 @@ func (d *MutableStateTaskStore) GetReplicationTasksFromDLQ(
 	ctx context.Context,
 	request *p.GetReplicationTasksFromDLQRequest,
 ) (*p.InternalGetHistoryTasksResponse, error) {
+	ctx = gocql.WithOperation(ctx, "GetReplicationTasksFromDLQ")
 	// Reading replication tasks need to be quorum level consistent, otherwise we could lose tasks
 	query := d.Session.Query(templateGetReplicationTasksQuery,
 		request.ShardID,
@@ -492,6 +538,7 @@ func (d *MutableStateTaskStore) DeleteReplicationTaskFromDLQ(
 	ctx context.Context,
 	request *p.DeleteReplicationTaskFromDLQRequest,
 ) error {
+	ctx = gocql.WithOperation(ctx, "DeleteReplicationTaskFromDLQ")
 
 	query := d.Session.Query(templateCompleteReplicationTaskQuery,
 		request.ShardID,
@@ -507,6 +554,7 @@ func (d *MutableStateTaskStore) RangeDeleteReplicationTaskFromDLQ(
 	ctx context.Context,
 	request *p.RangeDeleteReplicationTaskFromDLQRequest,
 ) error {
+	ctx = gocql.WithOperation(ctx, "RangeDeleteReplicationTaskFromDLQ")
 
 	query := d.Session.Query(templateRangeCompleteReplicationTaskQuery,
 		request.ShardID,
@@ -523,6 +571,7 @@ func (d *MutableStateTaskStore) IsReplicationDLQEmpty(
 	ctx context.Context,
 	request *p.GetReplicationTasksFromDLQRequest,
 ) (bool, error) {
+	ctx = gocql.WithOperation(ctx, "IsReplicationDLQEmpty")
 
 	query := d.Session.Query(templateIsQueueEmptyQuery,
 		request.ShardID,
@@ -545,6 +594,10 @@ func (d *MutableStateTaskStore) getVisibilityTasks(
 ) (*p.InternalGetHistoryTasksResponse, error) {
 
 	// Reading Visibility tasks need to be quorum level consistent, otherwise we could lose task
+	if d.layout.systemTasks != nil {
+		return d.getBucketedSystemTasks(ctx, request, systemTaskVisibility, "GetVisibilityTasks")
+	}
+
 	query := d.Session.Query(templateGetVisibilityTasksQuery,
 		request.ShardID,
 		systemTaskVisibility,
@@ -590,6 +643,9 @@ func (d *MutableStateTaskStore) completeVisibilityTask(
 	).WithContext(ctx)
 
 	err := query.Exec()
+	if err == nil && d.layout.systemTasks != nil {
+		err = d.completeBucketedSystemTask(ctx, request.ShardID, systemTaskVisibility, request.TaskKey.TaskID)
+	}
 	return gocql.ConvertError("CompleteVisibilityTask", err)
 }
 
@@ -605,6 +661,9 @@ func (d *MutableStateTaskStore) rangeCompleteVisibilityTasks(
 	).WithContext(ctx)
 
 	err := query.Exec()
+	if err == nil && d.layout.systemTasks != nil {
+		err = d.rangeCompleteBucketedSystemTasks(ctx, request.ShardID, systemTaskVisibility, request.InclusiveMinTaskKey.TaskID, request.ExclusiveMaxTaskKey.TaskID)
+	}
 	return gocql.ConvertError("RangeCompleteVisibilityTask", err)
 }
 
@@ -703,6 +762,9 @@ func (d *MutableStateTaskStore) getHistoryScheduledTasks(
 ) (*p.InternalGetHistoryTasksResponse, error) {
 	// execution manager should already validated the request
 	// Reading history tasks need to be quorum level consistent, otherwise we could lose task
+	if d.layout.timers != nil {
+		return d.getBucketedTimerTasks(ctx, request, int(request.TaskCategory.ID()), templateGetHistoryScheduledTasksQuery, "GetHistoryScheduledTasks")
+	}
 
 	minTimestamp := p.UnixMilliseconds(request.InclusiveMinTaskKey.FireTime)
 	maxTimestamp := p.UnixMilliseconds(request.ExclusiveMaxTaskKey.FireTime)
@@ -759,6 +821,9 @@ func (d *MutableStateTaskStore) completeHistoryTask(
 	).WithContext(ctx)
 
 	err := query.Exec()
+	if err == nil && d.layout.timers != nil && request.TaskCategory.Type() == tasks.CategoryTypeScheduled {
+		err = d.completeBucketedTimerTask(ctx, request.ShardID, int(request.TaskCategory.ID()), ts, request.TaskKey.TaskID)
+	}
 	return gocql.ConvertError("CompleteHistoryTask", err)
 }
 
@@ -785,8 +850,440 @@ func (d *MutableStateTaskStore) rangeCompleteHistoryTasks(
 			minTimestamp,
 			maxTimestamp,
 		).WithContext(ctx)
+		if d.layout.timers != nil {
+			if err := d.rangeCompleteBucketedTimerTasks(ctx, request.ShardID, int(request.TaskCategory.ID()), minTimestamp, maxTimestamp); err != nil {
+				return gocql.ConvertError("RangeCompleteHistoryTasks", err)
+			}
+		}
 	}
 
 	err := query.Exec()
 	return gocql.ConvertError("RangeCompleteHistoryTasks", err)
 }
+
+// getBucketedTimerTasks reads the scheduled tasks of the given type, ordered by key, from both the timers table and
+// the buckets of timers_by_bucket.  Each source is read up to a full page and the two are merged, so the page
+// holds the earliest tasks of either.
+func (d *MutableStateTaskStore) getBucketedTimerTasks(
+	ctx context.Context,
+	request *p.GetHistoryTasksRequest,
+	taskType int,
+	legacyTemplate string,
+	operation string,
+) (*p.InternalGetHistoryTasksResponse, error) {
+	after, err := decodeTaskPageToken(request.NextPageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	minTimestamp := p.UnixMilliseconds(request.InclusiveMinTaskKey.FireTime)
+	maxTimestamp := p.UnixMilliseconds(request.ExclusiveMaxTaskKey.FireTime)
+	if after != nil && after.FireTime > minTimestamp {
+		minTimestamp = after.FireTime
+	}
+
+	query := d.Session.Query(legacyTemplate,
+		request.ShardID,
+		taskType,
+		minTimestamp,
+		maxTimestamp,
+	).WithContext(ctx)
+	legacy, legacyMore, err := scanTimerTasks(query, after, request.BatchSize)
+	if err != nil {
+		return nil, gocql.ConvertError(operation, err)
+	}
+
+	buckets, err := d.listTimerBuckets(ctx, templateListTimerBucketsQuery,
+		request.ShardID,
+		taskType,
+		d.layout.timers.bucketOf(minTimestamp),
+		maxTimestamp,
+	)
+	if err != nil {
+		return nil, gocql.ConvertError(operation, err)
+	}
+
+	var bucketed []p.InternalHistoryTask
+	bucketedMore := false
+	for _, bucket := range buckets {
+		limit := 0
+		if request.BatchSize > 0 {
+			limit = request.BatchSize - len(bucketed)
+		}
+		query := d.Session.Query(templateGetBucketedTimerTasksQuery,
+			request.ShardID,
+			taskType,
+			bucket,
+			minTimestamp,
+			maxTimestamp,
+		).WithContext(ctx)
+		result, more, err := scanTimerTasks(query, after, limit)
+		if err != nil {
+			return nil, gocql.ConvertError(operation, err)
+		}
+		bucketed = append(bucketed, result...)
+		if more {
+			bucketedMore = true
+			break
+		}
+	}
+
+	response := &p.InternalGetHistoryTasksResponse{
+		Tasks: mergeHistoryTasks(legacy, bucketed),
+	}
+	more := legacyMore || bucketedMore
+	if request.BatchSize > 0 && len(response.Tasks) > request.BatchSize {
+		response.Tasks = response.Tasks[:request.BatchSize]
+		more = true
+	}
+	if more && len(response.Tasks) > 0 {
+		response.NextPageToken, err = newTaskPageToken(response.Tasks[len(response.Tasks)-1].Key)
+		if err != nil {
+			return nil, serviceerror.NewInternal(fmt.Sprintf("%s: unable to encode page token: %v", operation, err))
+		}
+	}
+
+	return response, nil
+}
+
+// scanTimerTasks returns the tasks of the query which follow the page token, stopping once limit tasks have been
+// read.  more reports whether the limit was reached.
+func scanTimerTasks(query gocql.Query, after *taskPageToken, limit int) (result []p.InternalHistoryTask, more bool, err error) {
+	if limit > 0 {
+		query = query.PageSize(limit)
+	}
+	iter := query.Iter()
+
+	var timestamp time.Time
+	var taskID int64
+	var data []byte
+	var encoding string
+
+	for iter.Scan(&timestamp, &taskID, &data, &encoding) {
+		if after.precedes(p.UnixMilliseconds(timestamp), taskID) {
+			result = append(result, p.InternalHistoryTask{
+				Key:  tasks.NewKey(timestamp, taskID),
+				Blob: p.NewDataBlob(data, encoding),
+			})
+		}
+
+		timestamp = time.Time{}
+		taskID = 0
+		data = nil
+		encoding = ""
+
+		if limit > 0 && len(result) >= limit {
+			more = true
+			break
+		}
+	}
+
+	if err := iter.Close(); err != nil {
+		return nil, false, err
+	}
+	return result, more, nil
+}
+
+// listTimerBuckets returns, in order, the start of each bucket recorded in timer_buckets which the query selects
+func (d *MutableStateTaskStore) listTimerBuckets(
+	ctx context.Context,
+	template string,
+	args ...interface{},
+) ([]int64, error) {
+	iter := d.Session.Query(template, args...).WithContext(ctx).Iter()
+
+	var buckets []int64
+	var bucket time.Time
+	for iter.Scan(&bucket) {
+		buckets = append(buckets, p.UnixMilliseconds(bucket))
+		bucket = time.Time{}
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	return buckets, nil
+}
+
+func (d *MutableStateTaskStore) completeBucketedTimerTask(
+	ctx context.Context,
+	shardID int32,
+	taskType int,
+	timestamp int64,
+	taskID int64,
+) error {
+	return d.Session.Query(templateCompleteBucketedTimerTaskQuery,
+		shardID,
+		taskType,
+		d.layout.timers.bucketOf(timestamp),
+		timestamp,
+		taskID,
+	).WithContext(ctx).Exec()
+}
+
+// rangeCompleteBucketedTimerTasks deletes the tasks of the given type firing before maxTimestamp.  Buckets which lie
+// entirely before maxTimestamp are deleted as a whole, along with their entry in timer_buckets, leaving only the
+// bucket holding maxTimestamp to be range deleted.
+func (d *MutableStateTaskStore) rangeCompleteBucketedTimerTasks(
+	ctx context.Context,
+	shardID int32,
+	taskType int,
+	minTimestamp int64,
+	maxTimestamp int64,
+) error {
+	buckets, err := d.listTimerBuckets(ctx, templateListTimerBucketsBeforeQuery,
+		shardID,
+		taskType,
+		maxTimestamp,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, bucket := range buckets {
+		if !d.layout.timers.covers(bucket, maxTimestamp) {
+			err = d.Session.Query(templateRangeCompleteBucketedTimerTaskQuery,
+				shardID,
+				taskType,
+				bucket,
+				minTimestamp,
+				maxTimestamp,
+			).WithContext(ctx).Exec()
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		err = d.Session.Query(templateDeleteTimerBucketTasksQuery,
+			shardID,
+			taskType,
+			bucket,
+		).WithContext(ctx).Exec()
+		if err != nil {
+			return err
+		}
+		// no task is written to a bucket the queue has moved past, so its entry can be removed
+		err = d.Session.Query(templateDeleteTimerBucketQuery,
+			shardID,
+			taskType,
+			bucket,
+		).WithContext(ctx).Exec()
+		if err != nil {
+			return err
+		}
+		d.layout.timers.forget(taskBucket{shardID: shardID, taskType: taskType, start: bucket})
+	}
+
+	return nil
+}
+
+// getBucketedSystemTasks reads the tasks of the queue identified by id, ordered by task ID, from both the
+// system_tasks table and the buckets of system_tasks_by_bucket.  Each source is read up to a full page and the two
+// are merged, so the page holds the earliest tasks of either.
+func (d *MutableStateTaskStore) getBucketedSystemTasks(
+	ctx context.Context,
+	request *p.GetHistoryTasksRequest,
+	id string,
+	operation string,
+) (*p.InternalGetHistoryTasksResponse, error) {
+	after, err := decodeTaskPageToken(request.NextPageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	minTaskID := request.InclusiveMinTaskKey.TaskID
+	maxTaskID := request.ExclusiveMaxTaskKey.TaskID
+	if after != nil && after.TaskID >= minTaskID {
+		minTaskID = after.TaskID + 1
+	}
+
+	query := d.Session.Query(templateGetSystemTasksQuery,
+		request.ShardID,
+		id,
+		minTaskID,
+		maxTaskID,
+	).WithContext(ctx)
+	legacy, legacyMore, err := scanSystemTasks(query, request.BatchSize)
+	if err != nil {
+		return nil, gocql.ConvertError(operation, err)
+	}
+
+	buckets, err := d.listSystemTaskBuckets(ctx, templateListSystemTaskBucketsQuery,
+		request.ShardID,
+		id,
+		d.layout.systemTasks.bucketOf(minTaskID),
+		maxTaskID,
+	)
+	if err != nil {
+		return nil, gocql.ConvertError(operation, err)
+	}
+
+	var bucketed []p.InternalHistoryTask
+	bucketedMore := false
+	for _, bucket := range buckets {
+		limit := 0
+		if request.BatchSize > 0 {
+			limit = request.BatchSize - len(bucketed)
+		}
+		query := d.Session.Query(templateGetBucketedSystemTasksQuery,
+			request.ShardID,
+			id,
+			bucket,
+			minTaskID,
+			maxTaskID,
+		).WithContext(ctx)
+		result, more, err := scanSystemTasks(query, limit)
+		if err != nil {
+			return nil, gocql.ConvertError(operation, err)
+		}
+		bucketed = append(bucketed, result...)
+		if more {
+			bucketedMore = true
+			break
+		}
+	}
+
+	response := &p.InternalGetHistoryTasksResponse{
+		Tasks: mergeHistoryTasks(legacy, bucketed),
+	}
+	more := legacyMore || bucketedMore
+	if request.BatchSize > 0 && len(response.Tasks) > request.BatchSize {
+		response.Tasks = response.Tasks[:request.BatchSize]
+		more = true
+	}
+	if more && len(response.Tasks) > 0 {
+		response.NextPageToken, err = newTaskPageToken(response.Tasks[len(response.Tasks)-1].Key)
+		if err != nil {
+			return nil, serviceerror.NewInternal(fmt.Sprintf("%s: unable to encode page token: %v", operation, err))
+		}
+	}
+
+	return response, nil
+}
+
+// scanSystemTasks returns the tasks of the query, stopping once limit tasks have been read.  more reports whether
+// the limit was reached.
+func scanSystemTasks(query gocql.Query, limit int) (result []p.InternalHistoryTask, more bool, err error) {
+	if limit > 0 {
+		query = query.PageSize(limit)
+	}
+	iter := query.Iter()
+
+	var taskID int64
+	var data []byte
+	var encoding string
+
+	for iter.Scan(&taskID, &data, &encoding) {
+		result = append(result, p.InternalHistoryTask{
+			Key:  tasks.NewImmediateKey(taskID),
+			Blob: p.NewDataBlob(data, encoding),
+		})
+
+		taskID = 0
+		data = nil
+		encoding = ""
+
+		if limit > 0 && len(result) >= limit {
+			more = true
+			break
+		}
+	}
+
+	if err := iter.Close(); err != nil {
+		return nil, false, err
+	}
+	return result, more, nil
+}
+
+// listSystemTaskBuckets returns, in order, the first task ID of each bucket recorded in system_task_buckets which
+// the query selects
+func (d *MutableStateTaskStore) listSystemTaskBuckets(
+	ctx context.Context,
+	template string,
+	args ...interface{},
+) ([]int64, error) {
+	iter := d.Session.Query(template, args...).WithContext(ctx).Iter()
+
+	var buckets []int64
+	var bucket int64
+	for iter.Scan(&bucket) {
+		buckets = append(buckets, bucket)
+		bucket = 0
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	return buckets, nil
+}
+
+func (d *MutableStateTaskStore) completeBucketedSystemTask(
+	ctx context.Context,
+	shardID int32,
+	id string,
+	taskID int64,
+) error {
+	return d.Session.Query(templateCompleteBucketedSystemTaskQuery,
+		shardID,
+		id,
+		d.layout.systemTasks.bucketOf(taskID),
+		taskID,
+	).WithContext(ctx).Exec()
+}
+
+// rangeCompleteBucketedSystemTasks deletes the tasks of the queue identified by id below maxTaskID.  Buckets which
+// lie entirely below maxTaskID are deleted as a whole, along with their entry in system_task_buckets, leaving only
+// the bucket holding maxTaskID to be range deleted.
+func (d *MutableStateTaskStore) rangeCompleteBucketedSystemTasks(
+	ctx context.Context,
+	shardID int32,
+	id string,
+	minTaskID int64,
+	maxTaskID int64,
+) error {
+	buckets, err := d.listSystemTaskBuckets(ctx, templateListSystemTaskBucketsBeforeQuery,
+		shardID,
+		id,
+		maxTaskID,
+	)
+	if err != nil {
+		return err
+	}
+
+	taskType := systemTaskCategories[id]
+	for _, bucket := range buckets {
+		if !d.layout.systemTasks.covers(bucket, maxTaskID) {
+			err = d.Session.Query(templateRangeCompleteBucketedSystemTaskQuery,
+				shardID,
+				id,
+				bucket,
+				minTaskID,
+				maxTaskID,
+			).WithContext(ctx).Exec()
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		err = d.Session.Query(templateDeleteSystemTaskBucketTasksQuery,
+			shardID,
+			id,
+			bucket,
+		).WithContext(ctx).Exec()
+		if err != nil {
+			return err
+		}
+		// task IDs are allocated in increasing order, so no task is written to a bucket the queue has moved past
+		err = d.Session.Query(templateDeleteSystemTaskBucketQuery,
+			shardID,
+			id,
+			bucket,
+		).WithContext(ctx).Exec()
+		if err != nil {
+			return err
+		}
+		d.layout.systemTasks.forget(taskBucket{shardID: shardID, taskType: taskType, start: bucket})
+	}
+
+	return nil
+}
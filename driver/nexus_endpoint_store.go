@@ -46,15 +46,16 @@ const (
 
 const (
 	// table templates
-	templateCreateTableVersion = `INSERT INTO nexus_endpoints(partition, type, id, version) VALUES (0, ?, ?, ?) IF NOT EXISTS`
+	templateCreateTableVersion = `INSERT INTO nexus_endpoints(partition, type, id, version) VALUES (0, ?, ?, ?) IF NOT EXISTS ELSE ERROR`
 	templateGetTableVersion    = `SELECT version FROM nexus_endpoints WHERE partition = 0 AND type = ? AND id = ?`
-	templateUpdateTableVersion = `UPDATE nexus_endpoints SET version = ? WHERE partition = 0 AND type = ? AND id = ? IF version = ?`
+	templateUpdateTableVersion = `UPDATE nexus_endpoints SET version = ? WHERE partition = 0 AND type = ? AND id = ? IF version = ? ELSE ERROR`
 
 	// endpoint templates
-	templateCreateEndpointQuery         = `INSERT INTO nexus_endpoints(partition, type, id, data, data_encoding, version) VALUES(0, ?, ?, ?, ?, ?) IF NOT EXISTS`
-	templateUpdateEndpointQuery         = `UPDATE nexus_endpoints SET data = ?, data_encoding = ?, version = ? WHERE partition = 0 AND type = ? AND id = ? IF version = ?`
-	templateDeleteEndpointQuery         = `DELETE FROM nexus_endpoints WHERE partition = 0 AND type = ? AND id = ? IF EXISTS`
+	templateCreateEndpointQuery         = `INSERT INTO nexus_endpoints(partition, type, id, data, data_encoding, version) VALUES(0, ?, ?, ?, ?, ?) IF NOT EXISTS ELSE ERROR`
+	templateUpdateEndpointQuery         = `UPDATE nexus_endpoints SET data = ?, data_encoding = ?, version = ? WHERE partition = 0 AND type = ? AND id = ? IF version = ? ELSE ERROR`
+	templateDeleteEndpointQuery         = `DELETE FROM nexus_endpoints WHERE partition = 0 AND type = ? AND id = ? IF EXISTS ELSE ERROR`
 	templateGetEndpointByIdQuery        = `SELECT data, data_encoding, version FROM nexus_endpoints WHERE partition = 0 AND type = ? AND id = ? LIMIT 1`
+	templateGetEndpointVersionQuery     = `SELECT version FROM nexus_endpoints WHERE partition = 0 AND type = ? AND id = ?`
 	templateBaseListEndpointsQuery      = `SELECT id, data, data_encoding, version FROM nexus_endpoints WHERE partition = 0`
 	templateListEndpointsQuery          = templateBaseListEndpointsQuery + ` AND type = ?`
 	templateListEndpointsFirstPageQuery = templateBaseListEndpointsQuery + ` ORDER BY type ASC`
@@ -87,14 +88,18 @@ func (s *NexusEndpointStore) Close() {
 	}
 }
 
+// CreateOrUpdateNexusEndpoint writes the endpoint and advances the table version in a single transaction.
+// Both writes are conditional, so the transaction aborts unless the caller's view of the table version
+// and the endpoint version are both current.
 func (s *NexusEndpointStore) CreateOrUpdateNexusEndpoint(
 	ctx context.Context,
 	request *p.InternalCreateOrUpdateNexusEndpointRequest,
 ) error {
-	batch := s.session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	ctx = gocql.WithOperation(ctx, "CreateOrUpdateNexusEndpoint")
+	txn := s.session.NewTxn().WithContext(ctx)
 
 	if request.Endpoint.Version == 0 {
-		batch.Query(templateCreateEndpointQuery,
+		txn.Query(templateCreateEndpointQuery,
 			rowTypeNexusEndpoint,
 			request.Endpoint.ID,
 			request.Endpoint.Data.Data,
@@ -102,7 +107,7 @@ func (s *NexusEndpointStore) CreateOrUpdateNexusEndpoint(
 			1,
 		)
 	} else {
-		batch.Query(templateUpdateEndpointQuery,
+		txn.Query(templateUpdateEndpointQuery,
 			request.Endpoint.Data.Data,
 			request.Endpoint.Data.EncodingType.String(),
 			request.Endpoint.Version+1,
@@ -111,50 +116,21 @@ func (s *NexusEndpointStore) CreateOrUpdateNexusEndpoint(
 			request.Endpoint.Version,
 		)
 	}
+	s.updateTableVersionTxn(txn, request.LastKnownTableVersion)
 
-	if request.LastKnownTableVersion == 0 {
-		batch.Query(templateCreateTableVersion,
-			rowTypePartitionStatus,
-			tableVersionEndpointID,
-			1)
-	} else {
-		batch.Query(templateUpdateTableVersion,
-			request.LastKnownTableVersion+1,
-			rowTypePartitionStatus,
-			tableVersionEndpointID,
-			request.LastKnownTableVersion)
-	}
-
-	previousPartitionStatus := make(map[string]interface{})
-	applied, iter, err := s.session.MapExecuteBatchCAS(batch, previousPartitionStatus)
-
+	err := txn.Exec()
 	if err != nil {
-		return gocql.ConvertError("CreateOrUpdateNexusEndpoint", err)
-	}
-
-	previousEndpoint := make(map[string]interface{})
-	iter.MapScan(previousEndpoint)
-
-	err = iter.Close()
-	if err != nil {
-		return gocql.ConvertError("CreateOrUpdateNexusEndpoint", err)
-	}
-
-	if !applied {
-		currentTableVersion, err := getTypedFieldFromRow[int64]("version", previousPartitionStatus)
-		if err != nil {
-			return fmt.Errorf("error retrieving current table version: %w", err)
+		if !gocql.ConflictError(err) && !gocql.IsTransactionConflict(err) {
+			return gocql.ConvertError("CreateOrUpdateNexusEndpoint", err)
 		}
-		if currentTableVersion != request.LastKnownTableVersion {
-			return fmt.Errorf("%w. provided table version: %v current table version: %v",
-				p.ErrNexusTableVersionConflict,
-				request.LastKnownTableVersion,
-				currentTableVersion)
+
+		if verr := s.checkTableVersion(ctx, request.LastKnownTableVersion); verr != nil {
+			return verr
 		}
 
-		currentVersion, err := getTypedFieldFromRow[int64]("version", previousEndpoint)
-		if err != nil {
-			return fmt.Errorf("error retrieving current endpoint version: %w", err)
+		currentVersion, verr := s.getEndpointVersion(ctx, request.Endpoint.ID)
+		if verr != nil {
+			return verr
 		}
 		if currentVersion != request.Endpoint.Version {
 			return fmt.Errorf("%w. provided endpoint version: %v current endpoint version: %v",
@@ -163,8 +139,14 @@ func (s *NexusEndpointStore) CreateOrUpdateNexusEndpoint(
 				currentVersion)
 		}
 
-		// This should never happen. This means the request had the correct versions and gocql did not
-		// return an error but for some reason the update was not applied.
+		if gocql.IsTransactionConflict(err) {
+			// Both versions still match, so the conflicting transaction did not change anything we
+			// depend on and the caller may safely retry.
+			return gocql.ConvertError("CreateOrUpdateNexusEndpoint", err)
+		}
+
+		// This should never happen. This means the request had the correct versions but the
+		// transaction reported a failed condition anyway.
 		return serviceerror.NewInternal("CreateOrUpdateNexusEndpoint failed.")
 	}
 
@@ -175,6 +157,7 @@ func (s *NexusEndpointStore) GetNexusEndpoint(
 	ctx context.Context,
 	request *p.GetNexusEndpointRequest,
 ) (*p.InternalNexusEndpoint, error) {
+	ctx = gocql.WithOperation(ctx, "GetNexusEndpoint")
 	query := s.session.Query(templateGetEndpointByIdQuery, rowTypeNexusEndpoint, request.ID).WithContext(ctx)
 
 	var data []byte
@@ -200,6 +183,7 @@ func (s *NexusEndpointStore) ListNexusEndpoints(
 	ctx context.Context,
 	request *p.ListNexusEndpointsRequest,
 ) (*p.InternalListNexusEndpointsResponse, error) {
+	ctx = gocql.WithOperation(ctx, "ListNexusEndpoints")
 	if request.LastKnownTableVersion == 0 && request.NextPageToken == nil {
 		return s.listFirstPageWithVersion(ctx, request)
 	}
@@ -244,49 +228,72 @@ func (s *NexusEndpointStore) ListNexusEndpoints(
 	return response, nil
 }
 
+// DeleteNexusEndpoint removes the endpoint and advances the table version in a single transaction
 func (s *NexusEndpointStore) DeleteNexusEndpoint(
 	ctx context.Context,
 	request *p.DeleteNexusEndpointRequest,
 ) error {
-	batch := s.session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	ctx = gocql.WithOperation(ctx, "DeleteNexusEndpoint")
+	txn := s.session.NewTxn().WithContext(ctx)
 
-	batch.Query(templateDeleteEndpointQuery,
+	txn.Query(templateDeleteEndpointQuery,
 		rowTypeNexusEndpoint,
 		request.ID)
+	s.updateTableVersionTxn(txn, request.LastKnownTableVersion)
 
-	batch.Query(templateUpdateTableVersion,
-		request.LastKnownTableVersion+1,
-		rowTypePartitionStatus,
-		tableVersionEndpointID,
-		request.LastKnownTableVersion)
+	err := txn.Exec()
+	if err != nil {
+		if !gocql.ConflictError(err) && !gocql.IsTransactionConflict(err) {
+			return gocql.ConvertError("DeleteNexusEndpoint", err)
+		}
 
-	previousPartitionStatus := make(map[string]interface{})
-	applied, iter, err := s.session.MapExecuteBatchCAS(batch, previousPartitionStatus)
+		if verr := s.checkTableVersion(ctx, request.LastKnownTableVersion); verr != nil {
+			return verr
+		}
 
-	if err != nil {
-		return gocql.ConvertError("DeleteNexusEndpoint", err)
-	}
+		currentVersion, verr := s.getEndpointVersion(ctx, request.ID)
+		if verr != nil {
+			return verr
+		}
+		if currentVersion == 0 {
+			return serviceerror.NewNotFound(fmt.Sprintf("nexus endpoint not found for ID: %v", request.ID))
+		}
 
-	err = iter.Close()
-	if err != nil {
 		return gocql.ConvertError("DeleteNexusEndpoint", err)
 	}
 
-	if !applied {
-		currentTableVersion, err := getTypedFieldFromRow[int64]("version", previousPartitionStatus)
-		if err != nil {
-			return fmt.Errorf("error retrieving current table version: %w", err)
-		}
-		if currentTableVersion != request.LastKnownTableVersion {
-			return fmt.Errorf("%w. provided table version: %v current table version: %v",
-				p.ErrNexusTableVersionConflict,
-				request.LastKnownTableVersion,
-				currentTableVersion)
-		}
+	return nil
+}
 
-		return serviceerror.NewNotFound(fmt.Sprintf("nexus endpoint not found for ID: %v", request.ID))
+// updateTableVersionTxn adds the statement advancing the table version past lastKnownTableVersion,
+// creating the partition status row when the table has never been written
+func (s *NexusEndpointStore) updateTableVersionTxn(txn *gocql.Txn, lastKnownTableVersion int64) {
+	if lastKnownTableVersion == 0 {
+		txn.Query(templateCreateTableVersion,
+			rowTypePartitionStatus,
+			tableVersionEndpointID,
+			1)
+	} else {
+		txn.Query(templateUpdateTableVersion,
+			lastKnownTableVersion+1,
+			rowTypePartitionStatus,
+			tableVersionEndpointID,
+			lastKnownTableVersion)
 	}
+}
 
+// checkTableVersion returns ErrNexusTableVersionConflict if the table has moved past lastKnownTableVersion
+func (s *NexusEndpointStore) checkTableVersion(ctx context.Context, lastKnownTableVersion int64) error {
+	currentTableVersion, err := s.getTableVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if currentTableVersion != lastKnownTableVersion {
+		return fmt.Errorf("%w. provided table version: %v current table version: %v",
+			p.ErrNexusTableVersionConflict,
+			lastKnownTableVersion,
+			currentTableVersion)
+	}
 	return nil
 }
 
@@ -338,13 +345,34 @@ func (s *NexusEndpointStore) getTableVersion(ctx context.Context) (int64, error)
 	query := s.session.Query(templateGetTableVersion, rowTypePartitionStatus, tableVersionEndpointID).WithContext(ctx)
 
 	var version int64
-	if err := query.Scan(&version); err != nil {
+	err := query.Scan(&version)
+	if gocql.IsNotFoundError(err) {
+		// the partition status row is only written along with the first endpoint
+		return 0, nil
+	}
+	if err != nil {
 		return 0, gocql.ConvertError("GetNexusEndpointsTableVersion", err)
 	}
 
 	return version, nil
 }
 
+// getEndpointVersion returns the current version of the endpoint, or 0 if it does not exist
+func (s *NexusEndpointStore) getEndpointVersion(ctx context.Context, id string) (int64, error) {
+	query := s.session.Query(templateGetEndpointVersionQuery, rowTypeNexusEndpoint, id).WithContext(ctx)
+
+	var version int64
+	err := query.Scan(&version)
+	if gocql.IsNotFoundError(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, gocql.ConvertError("GetNexusEndpointVersion", err)
+	}
+
+	return version, nil
+}
+
 func (s *NexusEndpointStore) getEndpointList(iter gocql.Iter) ([]p.InternalNexusEndpoint, error) {
 	var endpoints []p.InternalNexusEndpoint
 
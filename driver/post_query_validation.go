@@ -27,6 +27,8 @@ import (
 )
 
 type (
+	// PostQueryValidation runs functions concurrently, such as validations or independent reads, and reports the
+	// highest priority of their errors
 	PostQueryValidation struct {
 		validators []func() error
 	}
@@ -0,0 +1,58 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package driver
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	p "go.temporal.io/server/common/persistence"
+)
+
+func TestPostQueryValidation(t *testing.T) {
+	var done atomic.Int32
+	reads := NewPostQueryValidation()
+	for i := 0; i < 5; i++ {
+		reads.Add(func() error {
+			done.Add(1)
+			return nil
+		})
+	}
+	require.NoError(t, reads.Validate())
+	assert.Equal(t, int32(5), done.Load())
+
+	require.NoError(t, NewPostQueryValidation().Validate())
+}
+
+func TestPostQueryValidationReturnsHighestPriorityError(t *testing.T) {
+	reads := NewPostQueryValidation()
+	reads.Add(func() error { return errors.New("unavailable") })
+	reads.Add(func() error { return &p.ShardOwnershipLostError{ShardID: 1} })
+	reads.Add(func() error { return nil })
+
+	var ownershipLost *p.ShardOwnershipLostError
+	require.ErrorAs(t, reads.Validate(), &ownershipLost)
+}
@@ -78,6 +78,7 @@ func (q *QueueStore) Init(
 	ctx context.Context,
 	blob *commonpb.DataBlob,
 ) error {
+	ctx = gocql.WithOperation(ctx, "Init")
 	if err := q.initializeQueueMetadata(ctx, blob); err != nil {
 		return err
 	}
@@ -88,6 +89,7 @@ func (q *QueueStore) EnqueueMessage(
 	ctx context.Context,
 	blob *commonpb.DataBlob,
 ) error {
+	ctx = gocql.WithOperation(ctx, "EnqueueMessage")
 	lastMessageID, err := q.getLastMessageID(ctx, q.queueType)
 	if err != nil {
 		return err
@@ -101,6 +103,7 @@ func (q *QueueStore) EnqueueMessageToDLQ(
 	ctx context.Context,
 	blob *commonpb.DataBlob,
 ) (int64, error) {
+	ctx = gocql.WithOperation(ctx, "EnqueueMessageToDLQ")
 	// Use negative queue type as the dlq type
 	lastMessageID, err := q.getLastMessageID(ctx, q.getDLQTypeFromQueueType())
 	if err != nil {
@@ -153,6 +156,7 @@ func (q *QueueStore) ReadMessages(
 	lastMessageID int64,
 	maxCount int,
 ) ([]*persistence.QueueMessage, error) {
+	ctx = gocql.WithOperation(ctx, "ReadMessages")
 	// Reading replication tasks need to be quorum level consistent, otherwise we could lose tasks
 	query := q.session.Query(templateGetMessagesQuery,
 		q.queueType,
@@ -184,6 +188,7 @@ func (q *QueueStore) ReadMessagesFromDLQ(
 	pageSize int,
 	pageToken []byte,
 ) ([]*persistence.QueueMessage, []byte, error) {
+	ctx = gocql.WithOperation(ctx, "ReadMessagesFromDLQ")
 	// Reading replication tasks need to be quorum level consistent, otherwise we could lose tasks
 	// Use negative queue type as the dlq type
 	query := q.session.Query(templateGetMessagesFromDLQQuery,
@@ -216,6 +221,7 @@ func (q *QueueStore) DeleteMessagesBefore(
 	ctx context.Context,
 	messageID int64,
 ) error {
+	ctx = gocql.WithOperation(ctx, "DeleteMessagesBefore")
 
 	query := q.session.Query(templateDeleteMessagesBeforeQuery, q.queueType, messageID).WithContext(ctx)
 	if err := query.Exec(); err != nil {
@@ -228,6 +234,7 @@ func (q *QueueStore) DeleteMessageFromDLQ(
 	ctx context.Context,
 	messageID int64,
 ) error {
+	ctx = gocql.WithOperation(ctx, "DeleteMessageFromDLQ")
 
 	// Use negative queue type as the dlq type
 	query := q.session.Query(templateDeleteMessageQuery, q.getDLQTypeFromQueueType(), messageID).WithContext(ctx)
@@ -243,6 +250,7 @@ func (q *QueueStore) RangeDeleteMessagesFromDLQ(
 	firstMessageID int64,
 	lastMessageID int64,
 ) error {
+	ctx = gocql.WithOperation(ctx, "RangeDeleteMessagesFromDLQ")
 
 	// Use negative queue type as the dlq type
 	query := q.session.Query(templateDeleteMessagesQuery, q.getDLQTypeFromQueueType(), firstMessageID, lastMessageID).WithContext(ctx)
@@ -257,12 +265,14 @@ func (q *QueueStore) UpdateAckLevel(
 	ctx context.Context,
 	metadata *persistence.InternalQueueMetadata,
 ) error {
+	ctx = gocql.WithOperation(ctx, "UpdateAckLevel")
 	return q.updateAckLevel(ctx, metadata, q.queueType)
 }
 
 func (q *QueueStore) GetAckLevels(
 	ctx context.Context,
 ) (*persistence.InternalQueueMetadata, error) {
+	ctx = gocql.WithOperation(ctx, "GetAckLevels")
 	queueMetadata, err := q.getQueueMetadata(ctx, q.queueType)
 	if err != nil {
 		return nil, gocql.ConvertError("GetAckLevels", err)
@@ -275,12 +285,14 @@ func (q *QueueStore) UpdateDLQAckLevel(
 	ctx context.Context,
 	metadata *persistence.InternalQueueMetadata,
 ) error {
+	ctx = gocql.WithOperation(ctx, "UpdateDLQAckLevel")
 	return q.updateAckLevel(ctx, metadata, q.getDLQTypeFromQueueType())
 }
 
 func (q *QueueStore) GetDLQAckLevels(
 	ctx context.Context,
 ) (*persistence.InternalQueueMetadata, error) {
+	ctx = gocql.WithOperation(ctx, "GetDLQAckLevels")
 	// Use negative queue type as the dlq type
 	queueMetadata, err := q.getQueueMetadata(ctx, q.getDLQTypeFromQueueType())
 	if err != nil {
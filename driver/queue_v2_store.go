@@ -138,6 +138,7 @@ func (s *queueV2Store) EnqueueMessage(
 	ctx context.Context,
 	request *persistence.InternalEnqueueMessageRequest,
 ) (*persistence.InternalEnqueueMessageResponse, error) {
+	ctx = gocql.WithOperation(ctx, "EnqueueMessage")
 	// TODO: add concurrency control around this method to avoid things like QueueMessageIDConflict.
 	// TODO: cache the queue in memory to avoid querying the database every time.
 	_, err := s.getQueue(ctx, request.QueueType, request.QueueName)
@@ -161,6 +162,7 @@ func (s *queueV2Store) ReadMessages(
 	ctx context.Context,
 	request *persistence.InternalReadMessagesRequest,
 ) (*persistence.InternalReadMessagesResponse, error) {
+	ctx = gocql.WithOperation(ctx, "ReadMessages")
 	q, err := s.getQueue(ctx, request.QueueType, request.QueueName)
 	if err != nil {
 		return nil, err
@@ -228,6 +230,7 @@ func (s *queueV2Store) CreateQueue(
 	ctx context.Context,
 	request *persistence.InternalCreateQueueRequest,
 ) (*persistence.InternalCreateQueueResponse, error) {
+	ctx = gocql.WithOperation(ctx, "CreateQueue")
 	queueType := request.QueueType
 	queueName := request.QueueName
 	q := persistencespb.Queue{
@@ -265,6 +268,7 @@ func (s *queueV2Store) RangeDeleteMessages(
 	ctx context.Context,
 	request *persistence.InternalRangeDeleteMessagesRequest,
 ) (*persistence.InternalRangeDeleteMessagesResponse, error) {
+	ctx = gocql.WithOperation(ctx, "RangeDeleteMessages")
 	if request.InclusiveMaxMessageMetadata.ID < persistence.FirstQueueMessageID {
 		return nil, fmt.Errorf(
 			"%w: id is %d but must be >= %d",
@@ -483,6 +487,7 @@ func (s *queueV2Store) ListQueues(
 	ctx context.Context,
 	request *persistence.InternalListQueuesRequest,
 ) (*persistence.InternalListQueuesResponse, error) {
+	ctx = gocql.WithOperation(ctx, "ListQueues")
 	if request.PageSize <= 0 {
 		return nil, persistence.ErrNonPositiveListQueuesPageSize
 	}
@@ -0,0 +1,268 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package driver
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	temporal_yugabyte "github.com/manetu/temporal-yugabyte"
+	ybconfig "github.com/manetu/temporal-yugabyte/driver/config"
+	ybschema "github.com/manetu/temporal-yugabyte/schema/yugabyte"
+	"github.com/manetu/temporal-yugabyte/utils/gocql"
+	"go.temporal.io/server/common/log"
+	"go.temporal.io/server/common/log/tag"
+)
+
+const (
+	// the tables and indexes are read with SELECT * as the YCQL specific columns, such as transactions, are not
+	// reported by every release
+	readLiveTablesCQL  = `SELECT * FROM system_schema.tables WHERE keyspace_name = ?`
+	readLiveIndexesCQL = `SELECT * FROM system_schema.indexes WHERE keyspace_name = ?`
+
+	readLiveColumnsCQL = `SELECT table_name, column_name, kind, position, type FROM system_schema.columns ` +
+		`WHERE keyspace_name = ?`
+
+	readLiveTypesCQL = `SELECT type_name, field_names, field_types FROM system_schema.types WHERE keyspace_name = ?`
+)
+
+const temporalVersionedSchemaDir = "schema/yugabyte/temporal/versioned"
+
+type (
+	// liveKeyColumn is a key column of a live table with its position within the partition or clustering key
+	liveKeyColumn struct {
+		name     string
+		position int
+	}
+)
+
+// TemporalVersionedSchema returns the versioned schema of the temporal keyspace embedded in the driver
+func TemporalVersionedSchema() fs.FS {
+	fsys, err := fs.Sub(temporal_yugabyte.SchemaFs, temporalVersionedSchemaDir)
+	if err != nil {
+		panic(err)
+	}
+	return fsys
+}
+
+// CheckSchemaDrift compares the live schema of keyspace with the one the versioned schema of fsys expects of the
+// schema version recorded for it, returning that version and the differences found
+func CheckSchemaDrift(
+	session gocql.Session,
+	keyspace string,
+	fsys fs.FS,
+) (string, []ybschema.Difference, error) {
+	version, err := NewSchemaVersionReader(session).ReadSchemaVersion(keyspace)
+	if err != nil {
+		return "", nil, err
+	}
+	expected, err := ybschema.ExpectedSchema(fsys, version)
+	if err != nil {
+		return version, nil, fmt.Errorf("unable to build the expected schema of version %s: %w", version, err)
+	}
+	live, err := ReadLiveSchema(session, keyspace)
+	if err != nil {
+		return version, nil, err
+	}
+	return version, ybschema.Compare(expected, live), nil
+}
+
+// checkSchemaDrift logs each difference between the live and expected schema of the configured keyspace, and fails
+// on them when so configured
+func checkSchemaDrift(cfg ybconfig.Yugabyte, session gocql.Session, logger log.Logger) {
+	report := logger.Warn
+	if cfg.SchemaDriftCheck == ybconfig.SchemaDriftCheckFail {
+		report = logger.Fatal
+	}
+
+	keyspaceTag := tag.NewStringTag("keyspace", cfg.Keyspace)
+	version, differences, err := CheckSchemaDrift(session, cfg.Keyspace, TemporalVersionedSchema())
+	if err != nil {
+		report("unable to check the schema for drift", keyspaceTag, tag.Error(err))
+		return
+	}
+	versionTag := tag.NewStringTag("schema-version", version)
+	if len(differences) == 0 {
+		logger.Info("schema matches its version", keyspaceTag, versionTag)
+		return
+	}
+	for _, d := range differences {
+		logger.Warn("schema drift", keyspaceTag, versionTag, tag.NewStringTag("difference", d.String()))
+	}
+	report(fmt.Sprintf("schema has drifted from version %s in %d ways", version, len(differences)), keyspaceTag, versionTag)
+}
+
+// ReadLiveSchema reads the schema of keyspace from the system_schema tables
+func ReadLiveSchema(
+	session gocql.Session,
+	keyspace string,
+) (*ybschema.Schema, error) {
+	s := ybschema.NewSchema()
+	if err := readLiveTables(session, keyspace, s); err != nil {
+		return nil, gocql.ConvertError("ReadLiveSchema", err)
+	}
+	if err := readLiveColumns(session, keyspace, s); err != nil {
+		return nil, gocql.ConvertError("ReadLiveSchema", err)
+	}
+	if err := readLiveIndexes(session, keyspace, s); err != nil {
+		return nil, gocql.ConvertError("ReadLiveSchema", err)
+	}
+	if err := readLiveTypes(session, keyspace, s); err != nil {
+		return nil, gocql.ConvertError("ReadLiveSchema", err)
+	}
+	return s, nil
+}
+
+func readLiveTables(session gocql.Session, keyspace string, s *ybschema.Schema) error {
+	iter := session.Query(readLiveTablesCQL, keyspace).Iter()
+	row := make(map[string]interface{})
+	for iter.MapScan(row) {
+		name, err := getTypedFieldFromRow[string]("table_name", row)
+		if err != nil {
+			_ = iter.Close()
+			return err
+		}
+		s.Tables[name] = &ybschema.Table{
+			Name:          name,
+			Columns:       make(map[string]string),
+			Transactional: transactionsEnabled(row["transactions"]),
+		}
+		row = make(map[string]interface{})
+	}
+	return iter.Close()
+}
+
+func readLiveColumns(session gocql.Session, keyspace string, s *ybschema.Schema) error {
+	partitionKeys := make(map[string][]liveKeyColumn)
+	clusteringKeys := make(map[string][]liveKeyColumn)
+
+	iter := session.Query(readLiveColumnsCQL, keyspace).Iter()
+	var tableName, columnName, kind, typ string
+	var position int
+	for iter.Scan(&tableName, &columnName, &kind, &position, &typ) {
+		table, ok := s.Tables[tableName]
+		if !ok {
+			// the columns of index tables
+			continue
+		}
+		table.Columns[columnName] = ybschema.NormalizeType(typ)
+		switch kind {
+		case "partition_key":
+			partitionKeys[tableName] = append(partitionKeys[tableName], liveKeyColumn{name: columnName, position: position})
+		case "clustering":
+			clusteringKeys[tableName] = append(clusteringKeys[tableName], liveKeyColumn{name: columnName, position: position})
+		}
+	}
+	if err := iter.Close(); err != nil {
+		return err
+	}
+
+	for name, table := range s.Tables {
+		table.PartitionKey = orderKeyColumns(partitionKeys[name])
+		table.ClusteringKey = orderKeyColumns(clusteringKeys[name])
+	}
+	return nil
+}
+
+func readLiveIndexes(session gocql.Session, keyspace string, s *ybschema.Schema) error {
+	iter := session.Query(readLiveIndexesCQL, keyspace).Iter()
+	row := make(map[string]interface{})
+	for iter.MapScan(row) {
+		name, err := getTypedFieldFromRow[string]("index_name", row)
+		if err != nil {
+			_ = iter.Close()
+			return err
+		}
+		table, err := getTypedFieldFromRow[string]("table_name", row)
+		if err != nil {
+			_ = iter.Close()
+			return err
+		}
+		index := &ybschema.Index{Name: name, Table: table}
+		if options, ok := row["options"].(map[string]string); ok {
+			index.Columns = splitIndexColumns(options["target"])
+			index.Include = splitIndexColumns(options["include"])
+		}
+		s.Indexes[name] = index
+		row = make(map[string]interface{})
+	}
+	return iter.Close()
+}
+
+func readLiveTypes(session gocql.Session, keyspace string, s *ybschema.Schema) error {
+	iter := session.Query(readLiveTypesCQL, keyspace).Iter()
+	var name string
+	var fieldNames, fieldTypes []string
+	for iter.Scan(&name, &fieldNames, &fieldTypes) {
+		t := &ybschema.Type{Name: name}
+		for i, field := range fieldNames {
+			var typ string
+			if i < len(fieldTypes) {
+				typ = ybschema.NormalizeType(fieldTypes[i])
+			}
+			t.Fields = append(t.Fields, ybschema.Field{Name: field, Type: typ})
+		}
+		s.Types[name] = t
+		fieldNames, fieldTypes = nil, nil
+	}
+	return iter.Close()
+}
+
+// transactionsEnabled interprets the transactions column YCQL reports for a table.  A table is assumed to be
+// transactional when the column is not reported at all, so that drift is never reported on clusters lacking it.
+func transactionsEnabled(value interface{}) bool {
+	switch t := value.(type) {
+	case nil:
+		return true
+	case map[string]string:
+		return strings.EqualFold(t["enabled"], "true")
+	case bool:
+		return t
+	default:
+		return true
+	}
+}
+
+func orderKeyColumns(columns []liveKeyColumn) []string {
+	sort.SliceStable(columns, func(i, j int) bool {
+		return columns[i].position < columns[j].position
+	})
+	var result []string
+	for _, c := range columns {
+		result = append(result, c.name)
+	}
+	return result
+}
+
+// splitIndexColumns splits the target or include option of an index, e.g. "(namespace_id, status), start_time"
+func splitIndexColumns(value string) []string {
+	var result []string
+	for _, name := range strings.Split(strings.NewReplacer("(", "", ")", "").Replace(value), ",") {
+		if name = strings.Trim(strings.TrimSpace(name), `"`); name != "" {
+			result = append(result, strings.ToLower(name))
+		}
+	}
+	return result
+}
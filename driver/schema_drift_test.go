@@ -0,0 +1,51 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package driver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionsEnabled(t *testing.T) {
+	assert.True(t, transactionsEnabled(nil))
+	assert.True(t, transactionsEnabled(map[string]string{"enabled": "true"}))
+	assert.False(t, transactionsEnabled(map[string]string{"enabled": "false"}))
+	assert.False(t, transactionsEnabled(map[string]string{}))
+	assert.False(t, transactionsEnabled(false))
+}
+
+func TestSplitIndexColumns(t *testing.T) {
+	assert.Equal(t, []string{"namespace_id", "status", "start_time"}, splitIndexColumns(`(namespace_id, "Status"), start_time`))
+	assert.Equal(t, []string{"data"}, splitIndexColumns("data"))
+	assert.Empty(t, splitIndexColumns(""))
+}
+
+func TestOrderKeyColumns(t *testing.T) {
+	assert.Equal(t, []string{"shard_id", "type", "range_id"}, orderKeyColumns([]liveKeyColumn{
+		{name: "range_id", position: 2},
+		{name: "shard_id", position: 0},
+		{name: "type", position: 1},
+	}))
+}
@@ -27,7 +27,9 @@ package driver
 
 import (
 	"fmt"
-	"github.com/manetu/temporal-yugabyte/utils/gocql"
+
+	localgocql "github.com/manetu/temporal-yugabyte/utils/gocql"
+	"github.com/yugabyte/gocql"
 )
 
 const (
@@ -36,17 +38,18 @@ const (
 
 type (
 	SchemaVersionReader struct {
-		session gocql.Session
+		session localgocql.Session
 	}
 )
 
-func NewSchemaVersionReader(session gocql.Session) *SchemaVersionReader {
+func NewSchemaVersionReader(session localgocql.Session) *SchemaVersionReader {
 	return &SchemaVersionReader{
 		session: session,
 	}
 }
 
-// ReadSchemaVersion returns the current schema version for the Keyspace
+// ReadSchemaVersion returns the current schema version for the Keyspace.  When no version is recorded for it, the
+// error wraps gocql.ErrNotFound.
 func (svr *SchemaVersionReader) ReadSchemaVersion(keyspace string) (string, error) {
 	query := svr.session.Query(readSchemaVersionCQL, keyspace)
 
@@ -55,7 +58,7 @@ func (svr *SchemaVersionReader) ReadSchemaVersion(keyspace string) (string, erro
 	success := iter.Scan(&version)
 	err := iter.Close()
 	if err == nil && !success {
-		err = fmt.Errorf("no schema version found for keyspace %q", keyspace)
+		err = fmt.Errorf("no schema version found for keyspace %q: %w", keyspace, gocql.ErrNotFound)
 	}
 	if err != nil {
 		return "", fmt.Errorf("unable to get current schema version from driver: %w", err)
@@ -0,0 +1,87 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package driver
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/manetu/temporal-yugabyte/utils/gocql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	// versionSession answers every query with the row of a schema_version table
+	versionSession struct {
+		fakeSession
+		iter versionIter
+	}
+
+	versionQuery struct {
+		gocql.Query
+		iter versionIter
+	}
+
+	// versionIter yields version, if not empty, and fails with err on close
+	versionIter struct {
+		gocql.Iter
+		version string
+		err     error
+	}
+)
+
+func (s *versionSession) Query(string, ...interface{}) gocql.Query {
+	return versionQuery{iter: s.iter}
+}
+
+func (q versionQuery) Iter() gocql.Iter {
+	return &q.iter
+}
+
+func (i *versionIter) Scan(dest ...interface{}) bool {
+	if i.version == "" {
+		return false
+	}
+	*dest[0].(*string) = i.version
+	i.version = ""
+	return true
+}
+
+func (i *versionIter) Close() error {
+	return i.err
+}
+
+func TestReadSchemaVersion(t *testing.T) {
+	version, err := NewSchemaVersionReader(&versionSession{iter: versionIter{version: "1.5"}}).ReadSchemaVersion("temporal")
+	require.NoError(t, err)
+	assert.Equal(t, "1.5", version)
+
+	_, err = NewSchemaVersionReader(&versionSession{}).ReadSchemaVersion("temporal")
+	assert.True(t, gocql.IsNotFoundError(err))
+	assert.ErrorContains(t, err, `no schema version found for keyspace "temporal"`)
+
+	_, err = NewSchemaVersionReader(&versionSession{iter: versionIter{err: errors.New("unavailable")}}).ReadSchemaVersion("temporal")
+	assert.False(t, gocql.IsNotFoundError(err))
+	assert.ErrorContains(t, err, "unavailable")
+}
@@ -31,7 +31,6 @@ import (
 	"github.com/manetu/temporal-yugabyte/utils/gocql"
 	"strings"
 
-	"go.temporal.io/api/serviceerror"
 	"go.temporal.io/server/common/log"
 	p "go.temporal.io/server/common/persistence"
 )
@@ -49,6 +48,13 @@ const (
 		`SET shard = ?, shard_encoding = ?, range_id = ? ` +
 		`WHERE shard_id = ? ` +
 		`IF range_id = ?`
+
+	// templateAssertShardOwnershipQuery rewrites range_id with its own value, so the condition is evaluated with the
+	// same linearizability as the updates made by a competing owner
+	templateAssertShardOwnershipQuery = `UPDATE shards ` +
+		`SET range_id = ? ` +
+		`WHERE shard_id = ? ` +
+		`IF range_id = ?`
 )
 
 type (
@@ -75,6 +81,7 @@ func (d *ShardStore) GetOrCreateShard(
 	ctx context.Context,
 	request *p.InternalGetOrCreateShardRequest,
 ) (*p.InternalGetOrCreateShardResponse, error) {
+	ctx = gocql.WithOperation(ctx, "GetOrCreateShard")
 	query := d.Session.Query(templateGetShardQuery,
 		request.ShardID,
 	).WithContext(ctx)
@@ -122,6 +129,7 @@ func (d *ShardStore) UpdateShard(
 	ctx context.Context,
 	request *p.InternalUpdateShardRequest,
 ) error {
+	ctx = gocql.WithOperation(ctx, "UpdateShard")
 	query := d.Session.Query(templateUpdateShardQuery,
 		request.ShardInfo.Data,
 		request.ShardInfo.EncodingType.String(),
@@ -156,7 +164,28 @@ func (d *ShardStore) AssertShardOwnership(
 	ctx context.Context,
 	request *p.AssertShardOwnershipRequest,
 ) error {
-	return serviceerror.NewUnimplemented("AssertShardOwnership is not implemented for yugabyte shard store")
+	ctx = gocql.WithOperation(ctx, "AssertShardOwnership")
+	query := d.Session.Query(templateAssertShardOwnershipQuery,
+		request.RangeID,
+		request.ShardID,
+		request.RangeID,
+	).WithContext(ctx)
+
+	previous := make(map[string]interface{})
+	applied, err := query.MapScanCAS(previous)
+	if err != nil {
+		return gocql.ConvertError("AssertShardOwnership", err)
+	}
+
+	if !applied {
+		return &p.ShardOwnershipLostError{
+			ShardID: request.ShardID,
+			Msg: fmt.Sprintf("Failed to assert shard ownership.  request_range_id: %v, actual_range_id: %v",
+				request.RangeID, previous["range_id"]),
+		}
+	}
+
+	return nil
 }
 
 func (d *ShardStore) GetName() string {
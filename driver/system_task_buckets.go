@@ -0,0 +1,163 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package driver
+
+import (
+	"github.com/manetu/temporal-yugabyte/utils/gocql"
+	p "go.temporal.io/server/common/persistence"
+	"go.temporal.io/server/service/history/tasks"
+)
+
+const (
+	templateCreateSystemTaskBucketQuery = `INSERT INTO system_task_buckets (` +
+		`shard_id, id, bucket) ` +
+		`VALUES(?, ?, ?) `
+
+	templateCreateBucketedSystemTaskQuery = `INSERT INTO system_tasks_by_bucket (` +
+		`shard_id, id, bucket, data, encoding, task_id) ` +
+		`VALUES(?, ?, ?, ?, ?, ?) `
+
+	templateListSystemTaskBucketsQuery = `SELECT bucket ` +
+		`FROM system_task_buckets ` +
+		`WHERE shard_id = ? ` +
+		`and id = ? ` +
+		`and bucket >= ? ` +
+		`and bucket < ?`
+
+	templateListSystemTaskBucketsBeforeQuery = `SELECT bucket ` +
+		`FROM system_task_buckets ` +
+		`WHERE shard_id = ? ` +
+		`and id = ? ` +
+		`and bucket < ?`
+
+	templateGetBucketedSystemTasksQuery = `SELECT task_id, data, encoding ` +
+		`FROM system_tasks_by_bucket ` +
+		`WHERE shard_id = ? ` +
+		`and id = ? ` +
+		`and bucket = ? ` +
+		`and task_id >= ? ` +
+		`and task_id < ?`
+
+	templateCompleteBucketedSystemTaskQuery = `DELETE FROM system_tasks_by_bucket ` +
+		`WHERE shard_id = ? ` +
+		`and id = ? ` +
+		`and bucket = ? ` +
+		`and task_id = ? `
+
+	templateRangeCompleteBucketedSystemTaskQuery = `DELETE FROM system_tasks_by_bucket ` +
+		`WHERE shard_id = ? ` +
+		`and id = ? ` +
+		`and bucket = ? ` +
+		`and task_id >= ? ` +
+		`and task_id < ? `
+
+	templateDeleteSystemTaskBucketTasksQuery = `DELETE FROM system_tasks_by_bucket ` +
+		`WHERE shard_id = ? ` +
+		`and id = ? ` +
+		`and bucket = ? `
+
+	templateDeleteSystemTaskBucketQuery = `DELETE FROM system_task_buckets ` +
+		`WHERE shard_id = ? ` +
+		`and id = ? ` +
+		`and bucket = ? `
+)
+
+// systemTaskCategories maps the bucketed queues of system_tasks to their task category
+var systemTaskCategories = map[string]int{
+	systemTaskTransfer:    tasks.CategoryIDTransfer,
+	systemTaskVisibility:  tasks.CategoryIDVisibility,
+	systemTaskReplication: tasks.CategoryIDReplication,
+}
+
+type (
+	// systemTaskBuckets lays out the transfer, visibility and replication tasks of a shard across
+	// system_tasks_by_bucket partitions which each cover a fixed range of task IDs, so that writes move from
+	// partition to partition as task IDs advance and a partition the queue has moved past is deleted as a whole.  The
+	// buckets which may hold tasks are recorded in system_task_buckets, as task IDs jump whenever the range of a
+	// shard is renewed.  Tasks written to system_tasks before bucketing was enabled are still read and completed
+	// there, and the replication DLQ is not bucketed.
+	systemTaskBuckets struct {
+		knownBuckets
+		size int64
+	}
+)
+
+// newSystemTaskBuckets returns the layout for buckets of the given number of task IDs, or nil if system tasks are
+// not bucketed
+func newSystemTaskBuckets(size int) *systemTaskBuckets {
+	if size <= 0 {
+		return nil
+	}
+	return &systemTaskBuckets{
+		knownBuckets: newKnownBuckets(),
+		size:         int64(size),
+	}
+}
+
+// bucketOf returns the first task ID of the bucket holding the given task ID
+func (b *systemTaskBuckets) bucketOf(taskID int64) int64 {
+	start := taskID - taskID%b.size
+	if start > taskID {
+		start -= b.size
+	}
+	return start
+}
+
+// covers reports whether every task ID of the bucket is below the given task ID
+func (b *systemTaskBuckets) covers(bucket int64, taskID int64) bool {
+	return bucket+b.size <= taskID
+}
+
+// createTasks adds the tasks of the queue identified by id to the transaction, recording their buckets in
+// system_task_buckets unless this process already has.  A bucket is only remembered once the transaction commits.
+func (b *systemTaskBuckets) createTasks(
+	txn *gocql.Txn,
+	shardID int32,
+	id string,
+	historyTasks []p.InternalHistoryTask,
+) {
+	recorded := make(map[taskBucket]struct{})
+	for _, task := range historyTasks {
+		bucket := taskBucket{shardID: shardID, taskType: systemTaskCategories[id], start: b.bucketOf(task.Key.TaskID)}
+		if _, ok := recorded[bucket]; !ok {
+			recorded[bucket] = struct{}{}
+			if !b.isKnown(bucket) {
+				txn.Query(templateCreateSystemTaskBucketQuery,
+					shardID,
+					id,
+					bucket.start,
+				)
+				txn.OnCommit(func() { b.remember(bucket) })
+			}
+		}
+
+		txn.Query(templateCreateBucketedSystemTaskQuery,
+			shardID,
+			id,
+			bucket.start,
+			task.Blob.Data,
+			task.Blob.EncodingType.String(),
+			task.Key.TaskID,
+		)
+	}
+}
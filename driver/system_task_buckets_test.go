@@ -0,0 +1,54 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package driver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemTaskBucketOf(t *testing.T) {
+	require.Nil(t, newSystemTaskBuckets(0))
+
+	buckets := newSystemTaskBuckets(1000)
+	tests := map[string]struct {
+		taskID   int64
+		expected int64
+	}{
+		"start":    {taskID: 3000, expected: 3000},
+		"within":   {taskID: 3001, expected: 3000},
+		"end":      {taskID: 3999, expected: 3000},
+		"zero":     {taskID: 0, expected: 0},
+		"negative": {taskID: -1, expected: -1000},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, buckets.bucketOf(tc.taskID))
+		})
+	}
+
+	assert.True(t, buckets.covers(3000, 4000))
+	assert.False(t, buckets.covers(3000, 3999))
+}
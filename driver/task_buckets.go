@@ -0,0 +1,139 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package driver
+
+import (
+	"encoding/json"
+	"sync"
+
+	"go.temporal.io/api/serviceerror"
+	p "go.temporal.io/server/common/persistence"
+	"go.temporal.io/server/service/history/tasks"
+)
+
+// maxKnownBuckets bounds the buckets a process remembers having recorded in an index of buckets.  Forgetting a
+// bucket only costs recording it again.
+const maxKnownBuckets = 4096
+
+type (
+	// taskBucket identifies the partition of a bucketed layout holding some of the tasks of a shard
+	taskBucket struct {
+		shardID  int32
+		taskType int
+		start    int64
+	}
+
+	// knownBuckets remembers the buckets this process has recorded in the index of a bucketed layout, so that
+	// writes only record each bucket once
+	knownBuckets struct {
+		sync.Mutex
+		known map[taskBucket]struct{}
+	}
+
+	// taskPageToken holds the key of the last task of a page.  The tasks of a page may come from several
+	// partitions, so the next page resumes after this key rather than from a page state.
+	taskPageToken struct {
+		FireTime int64 `json:"fireTime,omitempty"`
+		TaskID   int64 `json:"taskId"`
+	}
+)
+
+func newKnownBuckets() knownBuckets {
+	return knownBuckets{
+		known: make(map[taskBucket]struct{}),
+	}
+}
+
+func (b *knownBuckets) isKnown(bucket taskBucket) bool {
+	b.Lock()
+	defer b.Unlock()
+	_, ok := b.known[bucket]
+	return ok
+}
+
+func (b *knownBuckets) remember(bucket taskBucket) {
+	b.Lock()
+	defer b.Unlock()
+	if len(b.known) >= maxKnownBuckets {
+		clear(b.known)
+	}
+	b.known[bucket] = struct{}{}
+}
+
+func (b *knownBuckets) forget(bucket taskBucket) {
+	b.Lock()
+	defer b.Unlock()
+	delete(b.known, bucket)
+}
+
+func decodeTaskPageToken(token []byte) (*taskPageToken, error) {
+	if len(token) == 0 {
+		return nil, nil
+	}
+
+	var result taskPageToken
+	if err := json.Unmarshal(token, &result); err != nil {
+		return nil, serviceerror.NewInvalidArgument("invalid history task page token")
+	}
+	return &result, nil
+}
+
+// newTaskPageToken returns the token resuming a read after the given task
+func newTaskPageToken(key tasks.Key) ([]byte, error) {
+	return json.Marshal(taskPageToken{
+		FireTime: p.UnixMilliseconds(key.FireTime),
+		TaskID:   key.TaskID,
+	})
+}
+
+// precedes reports whether the task with the given key belongs to a later page than the token.  Immediate tasks
+// fire at the epoch, so only their task IDs are compared.
+func (t *taskPageToken) precedes(timestamp int64, taskID int64) bool {
+	if t == nil {
+		return true
+	}
+	return timestamp > t.FireTime || (timestamp == t.FireTime && taskID > t.TaskID)
+}
+
+// mergeHistoryTasks merges two lists of tasks, each ordered by key, into one ordered list
+func mergeHistoryTasks(a, b []p.InternalHistoryTask) []p.InternalHistoryTask {
+	if len(a) == 0 {
+		return b
+	}
+	if len(b) == 0 {
+		return a
+	}
+
+	result := make([]p.InternalHistoryTask, 0, len(a)+len(b))
+	for len(a) > 0 && len(b) > 0 {
+		if b[0].Key.CompareTo(a[0].Key) < 0 {
+			result = append(result, b[0])
+			b = b[1:]
+		} else {
+			result = append(result, a[0])
+			a = a[1:]
+		}
+	}
+	result = append(result, a...)
+	return append(result, b...)
+}
@@ -0,0 +1,106 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package driver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/serviceerror"
+	p "go.temporal.io/server/common/persistence"
+	"go.temporal.io/server/service/history/tasks"
+)
+
+func TestKnownBuckets(t *testing.T) {
+	buckets := newKnownBuckets()
+	bucket := taskBucket{shardID: 1, taskType: rowTypeTimerTask, start: 0}
+
+	assert.False(t, buckets.isKnown(bucket))
+	buckets.remember(bucket)
+	assert.True(t, buckets.isKnown(bucket))
+	buckets.forget(bucket)
+	assert.False(t, buckets.isKnown(bucket))
+
+	for i := 0; i <= maxKnownBuckets; i++ {
+		buckets.remember(taskBucket{shardID: int32(i)})
+	}
+	assert.LessOrEqual(t, len(buckets.known), maxKnownBuckets)
+}
+
+func TestTaskPageToken(t *testing.T) {
+	after, err := decodeTaskPageToken(nil)
+	require.NoError(t, err)
+	assert.Nil(t, after)
+	assert.True(t, after.precedes(0, 0))
+
+	fireTime := time.UnixMilli(1700000000123).UTC()
+	token, err := newTaskPageToken(tasks.NewKey(fireTime, 42))
+	require.NoError(t, err)
+	after, err = decodeTaskPageToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, &taskPageToken{FireTime: p.UnixMilliseconds(fireTime), TaskID: 42}, after)
+
+	assert.False(t, after.precedes(after.FireTime-1, 100))
+	assert.False(t, after.precedes(after.FireTime, 41))
+	assert.False(t, after.precedes(after.FireTime, 42))
+	assert.True(t, after.precedes(after.FireTime, 43))
+	assert.True(t, after.precedes(after.FireTime+1, 0))
+
+	token, err = newTaskPageToken(tasks.NewImmediateKey(7))
+	require.NoError(t, err)
+	after, err = decodeTaskPageToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, &taskPageToken{TaskID: 7}, after)
+	assert.False(t, after.precedes(0, 7))
+	assert.True(t, after.precedes(0, 8))
+
+	_, err = decodeTaskPageToken([]byte("not json"))
+	var invalidArgument *serviceerror.InvalidArgument
+	assert.ErrorAs(t, err, &invalidArgument)
+}
+
+func TestMergeHistoryTasks(t *testing.T) {
+	task := func(seconds int64, taskID int64) p.InternalHistoryTask {
+		return p.InternalHistoryTask{Key: tasks.NewKey(time.Unix(seconds, 0), taskID)}
+	}
+	keys := func(tasks []p.InternalHistoryTask) []int64 {
+		var result []int64
+		for _, task := range tasks {
+			result = append(result, task.Key.FireTime.Unix()*100+task.Key.TaskID)
+		}
+		return result
+	}
+
+	assert.Empty(t, mergeHistoryTasks(nil, nil))
+	assert.Equal(t, []int64{101}, keys(mergeHistoryTasks([]p.InternalHistoryTask{task(1, 1)}, nil)))
+	assert.Equal(t, []int64{101}, keys(mergeHistoryTasks(nil, []p.InternalHistoryTask{task(1, 1)})))
+	assert.Equal(t,
+		[]int64{101, 102, 103, 201, 301, 302},
+		keys(mergeHistoryTasks(
+			[]p.InternalHistoryTask{task(1, 2), task(2, 1), task(3, 2)},
+			[]p.InternalHistoryTask{task(1, 1), task(1, 3), task(3, 1)},
+		)),
+	)
+}
@@ -0,0 +1,96 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package driver
+
+import (
+	"encoding/json"
+	"hash/fnv"
+
+	"go.temporal.io/api/serviceerror"
+)
+
+const (
+	// taskQueueUserDataBuckets is the number of partitions each namespace's task queue user data is spread
+	// across. Rows are placed by hashing the task queue name, so changing this value strands existing rows.
+	taskQueueUserDataBuckets = 16
+
+	// legacyTaskQueueUserDataBucket addresses the single task_queue_user_data partition namespaces used
+	// before task_queue_user_data_v2. Rows are moved out of it as their task queue is next updated, or all at
+	// once by MigrateTaskQueueUserData, and the partition is read until taskQueueUserDataMigrated is set.
+	legacyTaskQueueUserDataBucket = -1
+)
+
+type (
+	// userDataPageToken records how far a listing of task queue user data has progressed: the bucket
+	// being read and the page state within it. Buckets are visited in a fixed order, the legacy
+	// partition first, so a token remains valid while rows are written.
+	userDataPageToken struct {
+		Bucket    int    `json:"bucket"`
+		PageState []byte `json:"pageState,omitempty"`
+	}
+)
+
+// taskQueueUserDataBucket returns the bucket holding the user data and build ID mappings of a task queue
+func taskQueueUserDataBucket(taskQueue string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(taskQueue))
+	return int(h.Sum32() % taskQueueUserDataBuckets)
+}
+
+// taskQueueUserDataBucketsWithLegacy returns every bucket of a namespace in listing order
+func taskQueueUserDataBucketsWithLegacy() []int {
+	buckets := make([]int, 0, taskQueueUserDataBuckets+1)
+	for bucket := legacyTaskQueueUserDataBucket; bucket < taskQueueUserDataBuckets; bucket++ {
+		buckets = append(buckets, bucket)
+	}
+	return buckets
+}
+
+func decodeUserDataPageToken(token []byte) (userDataPageToken, error) {
+	if len(token) == 0 {
+		return userDataPageToken{Bucket: legacyTaskQueueUserDataBucket}, nil
+	}
+
+	// tokens issued before task_queue_user_data_v2 are the raw page state of the legacy partition, a serialized
+	// protobuf which does not begin with '{', and resume the listing there
+	if token[0] != '{' {
+		return userDataPageToken{Bucket: legacyTaskQueueUserDataBucket, PageState: token}, nil
+	}
+
+	var result userDataPageToken
+	if err := json.Unmarshal(token, &result); err != nil {
+		return result, serviceerror.NewInvalidArgument("invalid task queue user data page token")
+	}
+	if result.Bucket < legacyTaskQueueUserDataBucket || result.Bucket >= taskQueueUserDataBuckets {
+		return result, serviceerror.NewInvalidArgument("invalid task queue user data page token")
+	}
+	return result, nil
+}
+
+// encode returns the token for the next page, or nil once every bucket has been read
+func (t userDataPageToken) encode() ([]byte, error) {
+	if t.Bucket >= taskQueueUserDataBuckets {
+		return nil, nil
+	}
+	return json.Marshal(t)
+}
@@ -0,0 +1,94 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package driver
+
+import (
+	"context"
+
+	"github.com/manetu/temporal-yugabyte/utils/gocql"
+	"go.temporal.io/server/common/log"
+	"go.temporal.io/server/common/log/tag"
+)
+
+const (
+	templateScanLegacyTaskQueueUserDataQuery = `SELECT namespace_id, build_id, task_queue_name, data, data_encoding, version
+		FROM task_queue_user_data`
+	templateDeleteLegacyBuildIdTaskQueueMappingIfExistsQuery = `DELETE FROM task_queue_user_data
+		WHERE namespace_id = ? AND build_id = ? AND task_queue_name = ?
+		IF EXISTS ELSE ERROR`
+
+	migrateTaskQueueUserDataPageSize = 1000
+)
+
+// MigrateTaskQueueUserData moves every row of the legacy task_queue_user_data table to its bucket of
+// task_queue_user_data_v2, returning the number of rows moved and the number skipped.  Each row is moved in a
+// transaction conditioned on the legacy row being unchanged, so the migration may run alongside the matching
+// service; a row which an update moves or changes meanwhile is skipped, and the migration is complete once a run
+// skips none.
+func MigrateTaskQueueUserData(
+	ctx context.Context,
+	session gocql.Session,
+	logger log.Logger,
+) (int, int, error) {
+	ctx = gocql.WithOperation(ctx, "MigrateTaskQueueUserData")
+	iter := session.Query(templateScanLegacyTaskQueueUserDataQuery).
+		WithContext(ctx).
+		PageSize(migrateTaskQueueUserDataPageSize).
+		Iter()
+
+	moved, skipped := 0, 0
+	var namespaceID, buildID, taskQueue, encoding string
+	var data []byte
+	var version int64
+	for iter.Scan(&namespaceID, &buildID, &taskQueue, &data, &encoding, &version) {
+		txn := session.NewTxn().WithContext(ctx)
+		bucket := taskQueueUserDataBucket(taskQueue)
+		if buildID == "" {
+			txn.Query(templateDeleteLegacyTaskQueueUserDataQuery, namespaceID, taskQueue, version)
+			txn.Query(templateInsertTaskQueueUserDataQuery, namespaceID, bucket, taskQueue, data, encoding, version)
+		} else {
+			txn.Query(templateDeleteLegacyBuildIdTaskQueueMappingIfExistsQuery, namespaceID, buildID, taskQueue)
+			txn.Query(templateInsertBuildIdTaskQueueMappingQuery, namespaceID, bucket, buildID, taskQueue)
+		}
+
+		err := txn.Exec()
+		switch {
+		case err == nil:
+			moved++
+		case gocql.ConflictError(err):
+			logger.Info("Task queue user data changed while being migrated, skipping it.",
+				tag.WorkflowNamespaceID(namespaceID),
+				tag.WorkflowTaskQueueName(taskQueue),
+				tag.BuildId(buildID),
+			)
+			skipped++
+		default:
+			_ = iter.Close()
+			return moved, skipped, gocql.ConvertError("MigrateTaskQueueUserData", err)
+		}
+	}
+	if err := iter.Close(); err != nil {
+		return moved, skipped, gocql.ConvertError("MigrateTaskQueueUserData", err)
+	}
+	return moved, skipped, nil
+}
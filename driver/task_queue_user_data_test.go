@@ -0,0 +1,186 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package driver
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"testing"
+
+	localgocql "github.com/manetu/temporal-yugabyte/utils/gocql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yugabyte/gocql"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/server/common/log"
+	p "go.temporal.io/server/common/persistence"
+)
+
+type (
+	// userDataSession answers every query as a partition holding no user data and one build ID mapping
+	userDataSession struct {
+		fakeSession
+	}
+
+	userDataQuery struct {
+		localgocql.Query
+	}
+)
+
+func (s *userDataSession) Query(stmt string, args ...interface{}) localgocql.Query {
+	s.statements = append(s.statements, stmt)
+	return &userDataQuery{}
+}
+
+func (q *userDataQuery) WithContext(context.Context) localgocql.Query {
+	return q
+}
+
+func (q *userDataQuery) Scan(dest ...interface{}) error {
+	if count, ok := dest[0].(*int); ok {
+		*count = 1
+		return nil
+	}
+	return gocql.ErrNotFound
+}
+
+var legacyUserDataTable = regexp.MustCompile(`FROM task_queue_user_data\b`)
+
+// legacyReads returns the number of statements which read the legacy task_queue_user_data table
+func (s *userDataSession) legacyReads() int {
+	reads := 0
+	for _, stmt := range s.statements {
+		if legacyUserDataTable.MatchString(stmt) {
+			reads++
+		}
+	}
+	return reads
+}
+
+func TestTaskQueueUserDataBucket(t *testing.T) {
+	used := make(map[int]bool)
+	for i := 0; i < 1000; i++ {
+		taskQueue := "task-queue-" + strconv.Itoa(i)
+		bucket := taskQueueUserDataBucket(taskQueue)
+		require.GreaterOrEqual(t, bucket, 0)
+		require.Less(t, bucket, taskQueueUserDataBuckets)
+		require.Equal(t, bucket, taskQueueUserDataBucket(taskQueue), "buckets must be stable")
+		used[bucket] = true
+	}
+	assert.Len(t, used, taskQueueUserDataBuckets)
+
+	buckets := taskQueueUserDataBucketsWithLegacy()
+	require.Len(t, buckets, taskQueueUserDataBuckets+1)
+	assert.Equal(t, legacyTaskQueueUserDataBucket, buckets[0])
+}
+
+func TestUserDataPageToken(t *testing.T) {
+	tests := map[string]struct {
+		token    []byte
+		expected userDataPageToken
+		invalid  bool
+	}{
+		"empty": {
+			expected: userDataPageToken{Bucket: legacyTaskQueueUserDataBucket},
+		},
+		"bucket": {
+			token:    []byte(`{"bucket":3,"pageState":"AQI="}`),
+			expected: userDataPageToken{Bucket: 3, PageState: []byte{1, 2}},
+		},
+		"legacyPageState": {
+			token:    []byte{0x0a, 0x04, 1, 2, 3, 4},
+			expected: userDataPageToken{Bucket: legacyTaskQueueUserDataBucket, PageState: []byte{0x0a, 0x04, 1, 2, 3, 4}},
+		},
+		"garbage": {
+			token:   []byte("{not a token"),
+			invalid: true,
+		},
+		"bucketOutOfRange": {
+			token:   []byte(`{"bucket":16}`),
+			invalid: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			token, err := decodeUserDataPageToken(tc.token)
+			if tc.invalid {
+				var invalid *serviceerror.InvalidArgument
+				require.ErrorAs(t, err, &invalid)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, token)
+		})
+	}
+
+	encoded, err := userDataPageToken{Bucket: 5, PageState: []byte{9}}.encode()
+	require.NoError(t, err)
+	decoded, err := decodeUserDataPageToken(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, userDataPageToken{Bucket: 5, PageState: []byte{9}}, decoded)
+
+	encoded, err = userDataPageToken{Bucket: taskQueueUserDataBuckets}.encode()
+	require.NoError(t, err)
+	assert.Nil(t, encoded, "no token once every bucket has been read")
+}
+
+func TestLegacyTaskQueueUserDataReads(t *testing.T) {
+	tests := map[string]struct {
+		migrated    bool
+		legacyReads int
+		partitions  int
+	}{
+		"legacy": {
+			legacyReads: 2,
+			partitions:  taskQueueUserDataBuckets + 1,
+		},
+		"migrated": {
+			migrated:   true,
+			partitions: taskQueueUserDataBuckets,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			session := &userDataSession{}
+			store := newMatchingTaskStore(session, log.NewNoopLogger(), tc.migrated)
+
+			_, err := store.GetTaskQueueUserData(context.Background(), &p.GetTaskQueueUserDataRequest{
+				NamespaceID: "namespace",
+				TaskQueue:   "task-queue",
+			})
+			var notFound *serviceerror.NotFound
+			require.ErrorAs(t, err, &notFound)
+
+			count, err := store.CountTaskQueuesByBuildId(context.Background(), &p.CountTaskQueuesByBuildIdRequest{
+				NamespaceID: "namespace",
+				BuildID:     "build",
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tc.partitions, count, "every partition of the namespace is counted")
+			assert.Equal(t, tc.legacyReads, session.legacyReads())
+		})
+	}
+}
@@ -0,0 +1,158 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package driver
+
+import (
+	"time"
+
+	"github.com/manetu/temporal-yugabyte/utils/gocql"
+	p "go.temporal.io/server/common/persistence"
+)
+
+const (
+	templateCreateTimerBucketQuery = `INSERT INTO timer_buckets (` +
+		`shard_id, type, bucket) ` +
+		`VALUES(?, ?, ?) `
+
+	templateCreateBucketedTimerTaskQuery = `INSERT INTO timers_by_bucket (` +
+		`shard_id, type, bucket, data, encoding, visibility_ts, task_id) ` +
+		`VALUES(?, ?, ?, ?, ?, ?, ?) `
+
+	templateListTimerBucketsQuery = `SELECT bucket ` +
+		`FROM timer_buckets ` +
+		`WHERE shard_id = ? ` +
+		`and type = ? ` +
+		`and bucket >= ? ` +
+		`and bucket < ?`
+
+	templateListTimerBucketsBeforeQuery = `SELECT bucket ` +
+		`FROM timer_buckets ` +
+		`WHERE shard_id = ? ` +
+		`and type = ? ` +
+		`and bucket < ?`
+
+	templateGetBucketedTimerTasksQuery = `SELECT visibility_ts, task_id, data, encoding ` +
+		`FROM timers_by_bucket ` +
+		`WHERE shard_id = ? ` +
+		`and type = ? ` +
+		`and bucket = ? ` +
+		`and visibility_ts >= ? ` +
+		`and visibility_ts < ?`
+
+	templateCompleteBucketedTimerTaskQuery = `DELETE FROM timers_by_bucket ` +
+		`WHERE shard_id = ? ` +
+		`and type = ? ` +
+		`and bucket = ? ` +
+		`and visibility_ts = ? ` +
+		`and task_id = ? `
+
+	templateRangeCompleteBucketedTimerTaskQuery = `DELETE FROM timers_by_bucket ` +
+		`WHERE shard_id = ? ` +
+		`and type = ? ` +
+		`and bucket = ? ` +
+		`and visibility_ts >= ? ` +
+		`and visibility_ts < ? `
+
+	templateDeleteTimerBucketTasksQuery = `DELETE FROM timers_by_bucket ` +
+		`WHERE shard_id = ? ` +
+		`and type = ? ` +
+		`and bucket = ? `
+
+	templateDeleteTimerBucketQuery = `DELETE FROM timer_buckets ` +
+		`WHERE shard_id = ? ` +
+		`and type = ? ` +
+		`and bucket = ? `
+)
+
+type (
+	// timerBuckets lays out the timers and scheduled history tasks of a shard across timers_by_bucket partitions
+	// which each cover a fixed span of fire times, so that a partition the queue has moved past is deleted as a
+	// whole instead of accumulating range deletes in a single partition.  The buckets which may hold tasks are
+	// recorded in timer_buckets so that reads do not probe empty spans.  Tasks written to timers before
+	// bucketing was enabled are still read and completed there.
+	timerBuckets struct {
+		knownBuckets
+		width int64 // milliseconds
+	}
+)
+
+// newTimerBuckets returns the layout for buckets of the given width, or nil if timers are not bucketed
+func newTimerBuckets(width time.Duration) *timerBuckets {
+	if width <= 0 {
+		return nil
+	}
+	return &timerBuckets{
+		knownBuckets: newKnownBuckets(),
+		width:        width.Milliseconds(),
+	}
+}
+
+// bucketOf returns the start of the bucket holding tasks firing at the given time in milliseconds
+func (b *timerBuckets) bucketOf(timestamp int64) int64 {
+	start := timestamp - timestamp%b.width
+	if start > timestamp {
+		start -= b.width
+	}
+	return start
+}
+
+// covers reports whether every task of the bucket fires before the given time in milliseconds
+func (b *timerBuckets) covers(bucket int64, timestamp int64) bool {
+	return bucket+b.width <= timestamp
+}
+
+// createTasks adds the tasks to the transaction, recording their buckets in timer_buckets unless this process
+// already has.  A bucket is only remembered once the transaction commits.
+func (b *timerBuckets) createTasks(
+	txn *gocql.Txn,
+	shardID int32,
+	taskType int,
+	historyTasks []p.InternalHistoryTask,
+) {
+	recorded := make(map[taskBucket]struct{})
+	for _, task := range historyTasks {
+		timestamp := p.UnixMilliseconds(task.Key.FireTime)
+		bucket := taskBucket{shardID: shardID, taskType: taskType, start: b.bucketOf(timestamp)}
+		if _, ok := recorded[bucket]; !ok {
+			recorded[bucket] = struct{}{}
+			if !b.isKnown(bucket) {
+				txn.Query(templateCreateTimerBucketQuery,
+					shardID,
+					taskType,
+					bucket.start,
+				)
+				txn.OnCommit(func() { b.remember(bucket) })
+			}
+		}
+
+		txn.Query(templateCreateBucketedTimerTaskQuery,
+			shardID,
+			taskType,
+			bucket.start,
+			task.Blob.Data,
+			task.Blob.EncodingType.String(),
+			timestamp,
+			task.Key.TaskID,
+		)
+	}
+}
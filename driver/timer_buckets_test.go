@@ -0,0 +1,57 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package driver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimerBucketOf(t *testing.T) {
+	require.Nil(t, newTimerBuckets(0))
+
+	buckets := newTimerBuckets(time.Hour)
+	hour := time.Hour.Milliseconds()
+	tests := map[string]struct {
+		timestamp int64
+		expected  int64
+	}{
+		"start":         {timestamp: 3 * hour, expected: 3 * hour},
+		"within":        {timestamp: 3*hour + 1, expected: 3 * hour},
+		"end":           {timestamp: 4*hour - 1, expected: 3 * hour},
+		"epoch":         {timestamp: 0, expected: 0},
+		"beforeEpoch":   {timestamp: -1, expected: -hour},
+		"defaultFireAt": {timestamp: defaultVisibilityTimestamp, expected: defaultVisibilityTimestamp},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, buckets.bucketOf(tc.timestamp))
+		})
+	}
+
+	assert.True(t, buckets.covers(3*hour, 4*hour))
+	assert.False(t, buckets.covers(3*hour, 4*hour-1))
+}
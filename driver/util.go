@@ -26,18 +26,36 @@
 package driver
 
 import (
+	"context"
+
 	"github.com/manetu/temporal-yugabyte/utils/gocql"
 	commonpb "go.temporal.io/api/common/v1"
 	enumspb "go.temporal.io/api/enums/v1"
 	persistencespb "go.temporal.io/server/api/persistence/v1"
 	"go.temporal.io/server/common/convert"
+	"go.temporal.io/server/common/log/tag"
 	p "go.temporal.io/server/common/persistence"
 
 	"go.temporal.io/server/service/history/tasks"
 )
 
+// workflowLogContext identifies the shard and workflow of a request in the log entries of its slow statements
+func workflowLogContext(
+	ctx context.Context,
+	shardID int32,
+	namespaceID string,
+	workflowID string,
+) context.Context {
+	return gocql.WithLogTags(ctx,
+		tag.ShardID(shardID),
+		tag.WorkflowNamespaceID(namespaceID),
+		tag.WorkflowID(workflowID),
+	)
+}
+
 func applyWorkflowMutationTxn(
 	txn *gocql.Txn,
+	layout executionLayout,
 	shardID int32,
 	workflowMutation *p.InternalWorkflowMutation,
 ) error {
@@ -66,6 +84,11 @@ func applyWorkflowMutationTxn(
 		return err
 	}
 
+	if layout.mapTables {
+		updateExecutionMapTables(txn, shardID, workflowMutation)
+		return applyTasks(txn, layout, shardID, workflowMutation.Tasks)
+	}
+
 	if err := updateActivityInfos(
 		txn,
 		workflowMutation.UpsertActivityInfos,
@@ -149,6 +172,7 @@ func applyWorkflowMutationTxn(
 	// transfer / replication / timer tasks
 	return applyTasks(
 		txn,
+		layout,
 		shardID,
 		workflowMutation.Tasks,
 	)
@@ -156,6 +180,7 @@ func applyWorkflowMutationTxn(
 
 func applyWorkflowSnapshotTxnAsReset(
 	txn *gocql.Txn,
+	layout executionLayout,
 	shardID int32,
 	workflowSnapshot *p.InternalWorkflowSnapshot,
 ) error {
@@ -184,6 +209,13 @@ func applyWorkflowSnapshotTxnAsReset(
 		return err
 	}
 
+	if layout.mapTables {
+		if err := resetExecutionMapTables(txn, shardID, workflowSnapshot); err != nil {
+			return err
+		}
+		return applyTasks(txn, layout, shardID, workflowSnapshot.Tasks)
+	}
+
 	if err := resetActivityInfos(
 		txn,
 		workflowSnapshot.ActivityInfos,
@@ -259,6 +291,7 @@ func applyWorkflowSnapshotTxnAsReset(
 	// transfer / replication / timer tasks
 	return applyTasks(
 		txn,
+		layout,
 		shardID,
 		workflowSnapshot.Tasks,
 	)
@@ -266,6 +299,7 @@ func applyWorkflowSnapshotTxnAsReset(
 
 func applyWorkflowSnapshotTxnAsNew(
 	txn *gocql.Txn,
+	layout executionLayout,
 	shardID int32,
 	workflowSnapshot *p.InternalWorkflowSnapshot,
 ) error {
@@ -281,6 +315,11 @@ func applyWorkflowSnapshotTxnAsNew(
 		return err
 	}
 
+	if layout.mapTables {
+		createExecutionMapTables(txn, shardID, workflowSnapshot)
+		return applyTasks(txn, layout, shardID, workflowSnapshot.Tasks)
+	}
+
 	if err := updateActivityInfos(
 		txn,
 		workflowSnapshot.ActivityInfos,
@@ -354,6 +393,7 @@ func applyWorkflowSnapshotTxnAsNew(
 	// transfer / replication / timer tasks
 	return applyTasks(
 		txn,
+		layout,
 		shardID,
 		workflowSnapshot.Tasks,
 	)
@@ -451,6 +491,7 @@ func updateExecution(
 
 func applyTasks(
 	txn *gocql.Txn,
+	layout executionLayout,
 	shardID int32,
 	insertTasks map[tasks.Category][]p.InternalHistoryTask,
 ) error {
@@ -459,15 +500,15 @@ func applyTasks(
 	for category, tasksByCategory := range insertTasks {
 		switch category.ID() {
 		case tasks.CategoryIDTransfer:
-			err = createTransferTasks(txn, tasksByCategory, shardID)
+			err = createTransferTasks(txn, layout, tasksByCategory, shardID)
 		case tasks.CategoryIDTimer:
-			err = createTimerTasks(txn, tasksByCategory, shardID)
+			err = createTimerTasks(txn, layout, tasksByCategory, shardID)
 		case tasks.CategoryIDVisibility:
-			err = createVisibilityTasks(txn, tasksByCategory, shardID)
+			err = createVisibilityTasks(txn, layout, tasksByCategory, shardID)
 		case tasks.CategoryIDReplication:
-			err = createReplicationTasks(txn, tasksByCategory, shardID)
+			err = createReplicationTasks(txn, layout, tasksByCategory, shardID)
 		default:
-			err = createHistoryTasks(txn, category, tasksByCategory, shardID)
+			err = createHistoryTasks(txn, layout, category, tasksByCategory, shardID)
 		}
 
 		if err != nil {
@@ -480,9 +521,15 @@ func applyTasks(
 
 func createTransferTasks(
 	txn *gocql.Txn,
+	layout executionLayout,
 	transferTasks []p.InternalHistoryTask,
 	shardID int32,
 ) error {
+	if layout.systemTasks != nil {
+		layout.systemTasks.createTasks(txn, shardID, systemTaskTransfer, transferTasks)
+		return nil
+	}
+
 	for _, task := range transferTasks {
 		txn.Query(templateCreateTransferTaskQuery,
 			shardID,
@@ -497,9 +544,15 @@ func createTransferTasks(
 
 func createTimerTasks(
 	txn *gocql.Txn,
+	layout executionLayout,
 	timerTasks []p.InternalHistoryTask,
 	shardID int32,
 ) error {
+	if layout.timers != nil {
+		layout.timers.createTasks(txn, shardID, rowTypeTimerTask, timerTasks)
+		return nil
+	}
+
 	for _, task := range timerTasks {
 		txn.Query(templateCreateTimerTaskQuery,
 			shardID,
@@ -515,9 +568,15 @@ func createTimerTasks(
 
 func createReplicationTasks(
 	txn *gocql.Txn,
+	layout executionLayout,
 	replicationTasks []p.InternalHistoryTask,
 	shardID int32,
 ) error {
+	if layout.systemTasks != nil {
+		layout.systemTasks.createTasks(txn, shardID, systemTaskReplication, replicationTasks)
+		return nil
+	}
+
 	for _, task := range replicationTasks {
 		txn.Query(templateCreateReplicationTaskQuery,
 			shardID,
@@ -532,9 +591,15 @@ func createReplicationTasks(
 
 func createVisibilityTasks(
 	txn *gocql.Txn,
+	layout executionLayout,
 	visibilityTasks []p.InternalHistoryTask,
 	shardID int32,
 ) error {
+	if layout.systemTasks != nil {
+		layout.systemTasks.createTasks(txn, shardID, systemTaskVisibility, visibilityTasks)
+		return nil
+	}
+
 	for _, task := range visibilityTasks {
 		txn.Query(templateCreateVisibilityTaskQuery,
 			shardID,
@@ -549,11 +614,16 @@ func createVisibilityTasks(
 
 func createHistoryTasks(
 	txn *gocql.Txn,
+	layout executionLayout,
 	category tasks.Category,
 	historyTasks []p.InternalHistoryTask,
 	shardID int32,
 ) error {
 	isScheduledTask := category.Type() == tasks.CategoryTypeScheduled
+	if isScheduledTask && layout.timers != nil {
+		layout.timers.createTasks(txn, shardID, int(category.ID()), historyTasks)
+		return nil
+	}
 	for _, task := range historyTasks {
 		visibilityTimestamp := defaultVisibilityTimestamp
 		if isScheduledTask {
@@ -26,14 +26,16 @@
 package driver
 
 import (
-	"github.com/yugabyte/gocql"
+	"fmt"
+
 	ybconfig "github.com/manetu/temporal-yugabyte/driver/config"
 	ybschema "github.com/manetu/temporal-yugabyte/schema/yugabyte"
 	localgocql "github.com/manetu/temporal-yugabyte/utils/gocql"
+	"github.com/yugabyte/gocql"
 	"go.temporal.io/server/common/config"
 	"go.temporal.io/server/common/log"
+	"go.temporal.io/server/common/log/tag"
 	"go.temporal.io/server/common/metrics"
-
 	"go.temporal.io/server/common/persistence/schema"
 	"go.temporal.io/server/common/resolver"
 )
@@ -59,7 +61,7 @@ func checkMainKeyspace(
 	r resolver.ServiceResolver,
 ) error {
 	ds, ok := cfg.DataStores[cfg.DefaultStore]
-	if ok && ds.Cassandra != nil {
+	if ok && ds.CustomDataStoreConfig != nil {
 		return CheckCompatibleVersion(*ds.CustomDataStoreConfig, r, ybschema.Version)
 	}
 	return nil
@@ -87,7 +89,30 @@ func CheckCompatibleVersion(
 	}
 	defer session.Close()
 
-	schemaVersionReader := NewSchemaVersionReader(session)
+	return checkSchemaVersion(NewSchemaVersionReader(session), ccfg.Keyspace, expectedVersion)
+}
 
-	return schema.VerifyCompatibleVersion(schemaVersionReader, "temporal", expectedVersion)
+// checkSchemaVersion returns an error, naming the remedy, unless the schema version of keyspace is at least
+// expectedVersion
+func checkSchemaVersion(reader schema.VersionReader, keyspace string, expectedVersion string) error {
+	if err := schema.VerifyCompatibleVersion(reader, keyspace, expectedVersion); err != nil {
+		return fmt.Errorf("keyspace %s is not compatible with schema version %s, "+
+			"update it with \"temporal-yugabyte-tool --keyspace %s setup\": %w", keyspace, expectedVersion, keyspace, err)
+	}
+	return nil
+}
+
+// verifySchemaVersion fails unless the schema version of the configured keyspace is compatible with the one this
+// server expects, or only warns when the check is skipped
+func verifySchemaVersion(cfg ybconfig.Yugabyte, session localgocql.Session, logger log.Logger) {
+	err := checkSchemaVersion(NewSchemaVersionReader(session), cfg.Keyspace, ybschema.Version)
+	if err == nil {
+		return
+	}
+	tags := []tag.Tag{tag.NewStringTag("keyspace", cfg.Keyspace), tag.NewStringTag("expected-schema-version", ybschema.Version), tag.Error(err)}
+	if cfg.SkipSchemaVersionCheck {
+		logger.Warn("starting on an incompatible schema version as skipSchemaVersionCheck is set", tags...)
+		return
+	}
+	logger.Fatal("incompatible schema version, set skipSchemaVersionCheck to start regardless during a rolling upgrade", tags...)
 }
@@ -0,0 +1,56 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package driver
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	// fakeVersionReader is a [schema.VersionReader] returning a fixed version or error
+	fakeVersionReader struct {
+		version string
+		err     error
+	}
+)
+
+func (r fakeVersionReader) ReadSchemaVersion(string) (string, error) {
+	return r.version, r.err
+}
+
+func TestCheckSchemaVersion(t *testing.T) {
+	assert.NoError(t, checkSchemaVersion(fakeVersionReader{version: "1.5"}, "temporal", "1.5"))
+	// the code may be rolled back after a schema update
+	assert.NoError(t, checkSchemaVersion(fakeVersionReader{version: "1.6"}, "temporal", "1.5"))
+
+	err := checkSchemaVersion(fakeVersionReader{version: "1.4"}, "temporal_a", "1.5")
+	assert.ErrorContains(t, err, `keyspace temporal_a is not compatible with schema version 1.5`)
+	assert.ErrorContains(t, err, `temporal-yugabyte-tool --keyspace temporal_a setup`)
+	assert.ErrorContains(t, err, "Actual version: 1.4")
+
+	err = checkSchemaVersion(fakeVersionReader{err: errors.New("table schema_version does not exist")}, "temporal_a", "1.5")
+	assert.ErrorContains(t, err, "table schema_version does not exist")
+}
@@ -0,0 +1,356 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package driver
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/temporalio/sqlparser"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/server/common/persistence/visibility/store"
+	"go.temporal.io/server/common/searchattribute"
+	"go.temporal.io/server/common/sqlquery"
+)
+
+type (
+	// visibilityQuery is a parsed visibility list filter: a conjunction of conditions on the system search attributes
+	// the Yugabyte visibility store supports, and the attribute counts are grouped by, if any
+	visibilityQuery struct {
+		conditions []visibilityCondition
+		groupBy    string
+		// divisionFiltered records whether the query constrains TemporalNamespaceDivision itself; when it does not,
+		// only runs without a division match, so that internal workflows stay out of user lists
+		divisionFiltered bool
+	}
+
+	// visibilityCondition compares a search attribute of a run with one value, or two for BETWEEN. Values are
+	// strings, statuses, or times truncated to the millisecond precision of the store.
+	visibilityCondition struct {
+		field    string
+		operator string
+		values   []any
+	}
+
+	// visibilityRecord is a run as stored in executions_visibility
+	visibilityRecord struct {
+		*store.InternalWorkflowExecutionInfo
+		namespaceDivision string
+	}
+
+	visibilityFieldKind int
+)
+
+const (
+	visibilityKeywordField visibilityFieldKind = iota
+	visibilityStatusField
+	visibilityTimeField
+)
+
+// visibilityFields are the search attributes a visibility query may filter on
+var visibilityFields = map[string]visibilityFieldKind{
+	searchattribute.WorkflowID:                visibilityKeywordField,
+	searchattribute.RunID:                     visibilityKeywordField,
+	searchattribute.WorkflowType:              visibilityKeywordField,
+	searchattribute.TemporalNamespaceDivision: visibilityKeywordField,
+	searchattribute.ExecutionStatus:           visibilityStatusField,
+	searchattribute.StartTime:                 visibilityTimeField,
+	searchattribute.CloseTime:                 visibilityTimeField,
+	searchattribute.ExecutionTime:             visibilityTimeField,
+}
+
+// visibilityOperators are the comparisons supported for each kind of field
+var visibilityOperators = map[visibilityFieldKind][]string{
+	visibilityKeywordField: {sqlparser.EqualStr, sqlparser.NotEqualStr, sqlparser.IsNullStr, sqlparser.IsNotNullStr},
+	visibilityStatusField:  {sqlparser.EqualStr, sqlparser.NotEqualStr},
+	visibilityTimeField: {
+		sqlparser.EqualStr, sqlparser.NotEqualStr,
+		sqlparser.LessThanStr, sqlparser.LessEqualStr, sqlparser.GreaterThanStr, sqlparser.GreaterEqualStr,
+		sqlparser.BetweenStr, sqlparser.IsNullStr, sqlparser.IsNotNullStr,
+	},
+}
+
+func invalidVisibilityQuery(format string, args ...any) error {
+	return serviceerror.NewInvalidArgument(fmt.Sprintf("invalid query: "+format, args...))
+}
+
+// parseVisibilityQuery parses the SQL-like list filter of a visibility request. Only conjunctions of comparisons on
+// visibilityFields are supported, and only counts may be grouped, by ExecutionStatus.
+func parseVisibilityQuery(query string) (*visibilityQuery, error) {
+	query = strings.TrimSpace(query)
+	lower := strings.ToLower(query)
+	if query != "" && !strings.HasPrefix(lower, "order by ") && !strings.HasPrefix(lower, "group by ") {
+		query = "where " + query
+	}
+
+	stmt, err := sqlparser.Parse("select * from executions_visibility " + query)
+	if err != nil {
+		return nil, invalidVisibilityQuery("%v", err)
+	}
+	sel, ok := stmt.(*sqlparser.Select)
+	if !ok || sel.Limit != nil || sel.Having != nil {
+		return nil, invalidVisibilityQuery("%s", query)
+	}
+	if len(sel.OrderBy) > 0 {
+		return nil, invalidVisibilityQuery("ORDER BY is not supported")
+	}
+
+	q := &visibilityQuery{}
+	if len(sel.GroupBy) > 0 {
+		col, ok := sel.GroupBy[0].(*sqlparser.ColName)
+		if len(sel.GroupBy) > 1 || !ok || visibilityColumnName(col) != searchattribute.ExecutionStatus {
+			return nil, invalidVisibilityQuery("GROUP BY is only supported on %s", searchattribute.ExecutionStatus)
+		}
+		q.groupBy = searchattribute.ExecutionStatus
+	}
+	if sel.Where != nil {
+		if err := q.add(sel.Where.Expr); err != nil {
+			return nil, err
+		}
+	}
+	return q, nil
+}
+
+func visibilityColumnName(col *sqlparser.ColName) string {
+	return strings.ReplaceAll(sqlparser.String(col), "`", "")
+}
+
+func (q *visibilityQuery) add(expr sqlparser.Expr) error {
+	switch e := expr.(type) {
+	case *sqlparser.AndExpr:
+		if err := q.add(e.Left); err != nil {
+			return err
+		}
+		return q.add(e.Right)
+	case *sqlparser.ParenExpr:
+		return q.add(e.Expr)
+	case *sqlparser.ComparisonExpr:
+		return q.addCondition(e.Left, e.Operator, e.Right)
+	case *sqlparser.RangeCond:
+		return q.addCondition(e.Left, e.Operator, e.From, e.To)
+	case *sqlparser.IsExpr:
+		return q.addCondition(e.Expr, e.Operator)
+	default:
+		return invalidVisibilityQuery("unsupported expression %s", sqlparser.String(expr))
+	}
+}
+
+func (q *visibilityQuery) addCondition(left sqlparser.Expr, operator string, right ...sqlparser.Expr) error {
+	col, ok := left.(*sqlparser.ColName)
+	if !ok {
+		return invalidVisibilityQuery("expected a search attribute, got %s", sqlparser.String(left))
+	}
+	field := visibilityColumnName(col)
+	kind, ok := visibilityFields[field]
+	if !ok {
+		return invalidVisibilityQuery("filtering on %s is not supported", field)
+	}
+	supported := false
+	for _, op := range visibilityOperators[kind] {
+		supported = supported || op == operator
+	}
+	if !supported {
+		return invalidVisibilityQuery("operator %q is not supported on %s", operator, field)
+	}
+
+	condition := visibilityCondition{field: field, operator: operator}
+	for _, expr := range right {
+		v, err := parseVisibilityValue(kind, expr)
+		if err != nil {
+			return invalidVisibilityQuery("%s: %v", field, err)
+		}
+		condition.values = append(condition.values, v)
+	}
+	q.conditions = append(q.conditions, condition)
+	if field == searchattribute.TemporalNamespaceDivision {
+		q.divisionFiltered = true
+	}
+	return nil
+}
+
+func parseVisibilityValue(kind visibilityFieldKind, expr sqlparser.Expr) (any, error) {
+	if _, ok := expr.(*sqlparser.SQLVal); !ok {
+		return nil, fmt.Errorf("expected a literal, got %s", sqlparser.String(expr))
+	}
+	v, err := sqlquery.ParseValue(sqlparser.String(expr))
+	if err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case visibilityStatusField:
+		switch t := v.(type) {
+		case string:
+			status, err := enumspb.WorkflowExecutionStatusFromString(t)
+			if err != nil {
+				return nil, err
+			}
+			return status, nil
+		case int64:
+			return enumspb.WorkflowExecutionStatus(t), nil
+		}
+	case visibilityTimeField:
+		switch t := v.(type) {
+		case string:
+			ts, err := time.Parse(time.RFC3339Nano, t)
+			if err != nil {
+				return nil, err
+			}
+			return visibilityTime(ts), nil
+		case int64:
+			return visibilityTime(time.Unix(0, t)), nil
+		}
+	default:
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected value %v", v)
+}
+
+// visibilityTime truncates t to the millisecond precision of YCQL timestamps
+func visibilityTime(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return t.UTC().Truncate(time.Millisecond)
+}
+
+// equals returns the value the query requires field to be equal to, if any
+func (q *visibilityQuery) equals(field string) (any, bool) {
+	for _, c := range q.conditions {
+		if c.field == field && c.operator == sqlparser.EqualStr {
+			return c.values[0], true
+		}
+	}
+	return nil, false
+}
+
+// startTimeRange returns the inclusive bounds the query places on the start time of a run, zero when unbounded
+func (q *visibilityQuery) startTimeRange() (from time.Time, to time.Time) {
+	for _, c := range q.conditions {
+		if c.field != searchattribute.StartTime {
+			continue
+		}
+		var lower, upper time.Time
+		switch c.operator {
+		case sqlparser.EqualStr:
+			lower, upper = c.values[0].(time.Time), c.values[0].(time.Time)
+		case sqlparser.GreaterThanStr, sqlparser.GreaterEqualStr:
+			lower = c.values[0].(time.Time)
+		case sqlparser.LessThanStr, sqlparser.LessEqualStr:
+			upper = c.values[0].(time.Time)
+		case sqlparser.BetweenStr:
+			lower, upper = c.values[0].(time.Time), c.values[1].(time.Time)
+		}
+		if !lower.IsZero() && lower.After(from) {
+			from = lower
+		}
+		if !upper.IsZero() && (to.IsZero() || upper.Before(to)) {
+			to = upper
+		}
+	}
+	return from, to
+}
+
+// matches reports whether a run satisfies every condition of the query
+func (q *visibilityQuery) matches(r *visibilityRecord) bool {
+	if !q.divisionFiltered && r.namespaceDivision != "" {
+		return false
+	}
+	for _, c := range q.conditions {
+		if !c.matches(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c visibilityCondition) matches(r *visibilityRecord) bool {
+	var v any
+	switch c.field {
+	case searchattribute.WorkflowID:
+		v = r.WorkflowID
+	case searchattribute.RunID:
+		v = r.RunID
+	case searchattribute.WorkflowType:
+		v = r.TypeName
+	case searchattribute.TemporalNamespaceDivision:
+		v = r.namespaceDivision
+	case searchattribute.ExecutionStatus:
+		v = r.Status
+	case searchattribute.StartTime:
+		v = visibilityTime(r.StartTime)
+	case searchattribute.CloseTime:
+		v = visibilityTime(r.CloseTime)
+	case searchattribute.ExecutionTime:
+		v = visibilityTime(r.ExecutionTime)
+	}
+
+	isNull := v == ""
+	if t, ok := v.(time.Time); ok {
+		isNull = t.IsZero()
+	}
+	switch c.operator {
+	case sqlparser.IsNullStr:
+		return isNull
+	case sqlparser.IsNotNullStr:
+		return !isNull
+	}
+	if isNull && c.field != searchattribute.ExecutionStatus {
+		// as in SQL, comparisons with a missing value never hold
+		return false
+	}
+
+	switch c.operator {
+	case sqlparser.EqualStr:
+		return compareVisibilityValues(v, c.values[0]) == 0
+	case sqlparser.NotEqualStr:
+		return compareVisibilityValues(v, c.values[0]) != 0
+	case sqlparser.LessThanStr:
+		return compareVisibilityValues(v, c.values[0]) < 0
+	case sqlparser.LessEqualStr:
+		return compareVisibilityValues(v, c.values[0]) <= 0
+	case sqlparser.GreaterThanStr:
+		return compareVisibilityValues(v, c.values[0]) > 0
+	case sqlparser.GreaterEqualStr:
+		return compareVisibilityValues(v, c.values[0]) >= 0
+	case sqlparser.BetweenStr:
+		return compareVisibilityValues(v, c.values[0]) >= 0 && compareVisibilityValues(v, c.values[1]) <= 0
+	}
+	return false
+}
+
+func compareVisibilityValues(a any, b any) int {
+	switch t := a.(type) {
+	case string:
+		return strings.Compare(t, b.(string))
+	case enumspb.WorkflowExecutionStatus:
+		return int(t) - int(b.(enumspb.WorkflowExecutionStatus))
+	case time.Time:
+		return t.Compare(b.(time.Time))
+	}
+	return 0
+}
@@ -0,0 +1,161 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package driver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/server/common/persistence/visibility/store"
+)
+
+func TestParseVisibilityQuery(t *testing.T) {
+	start := time.Date(2025, 3, 1, 12, 0, 0, 123456789, time.UTC)
+
+	for _, tc := range []struct {
+		name  string
+		query string
+		stmt  string
+		args  []interface{}
+	}{
+		{
+			name:  "namespace",
+			query: "",
+			stmt:  templateListVisibilityQuery,
+			args:  []interface{}{"ns"},
+		},
+		{
+			name:  "workflow id",
+			query: `WorkflowId = "wf" AND WorkflowType = 'type'`,
+			stmt:  templateListVisibilityQuery + `and workflow_id = ? `,
+			args:  []interface{}{"ns", "wf"},
+		},
+		{
+			name:  "run id",
+			query: `RunId = 'run' AND StartTime >= '` + start.Format(time.RFC3339Nano) + `'`,
+			stmt:  templateGetVisibilityQuery,
+			args:  []interface{}{"ns", "run"},
+		},
+		{
+			name:  "run id and workflow id",
+			query: `WorkflowId = 'wf' AND RunId = 'run'`,
+			stmt:  templateGetVisibilityQuery,
+			args:  []interface{}{"ns", "run"},
+		},
+		{
+			name:  "workflow type",
+			query: `ExecutionStatus = 'Running' AND (WorkflowType = 'type')`,
+			stmt:  templateListVisibilityQuery + `and workflow_type_name = ? `,
+			args:  []interface{}{"ns", "type"},
+		},
+		{
+			name:  "status and start time",
+			query: `ExecutionStatus = "Failed" AND StartTime >= '` + start.Format(time.RFC3339Nano) + `'`,
+			stmt:  templateListVisibilityQuery + `and status = ? and start_time >= ? `,
+			args:  []interface{}{"ns", int32(enumspb.WORKFLOW_EXECUTION_STATUS_FAILED), start.Truncate(time.Millisecond)},
+		},
+		{
+			name:  "start time range",
+			query: `StartTime BETWEEN 0 AND '` + start.Format(time.RFC3339Nano) + `' AND StartTime < 1000000`,
+			stmt:  templateListVisibilityQuery + `and start_time >= ? and start_time <= ? `,
+			args:  []interface{}{"ns", time.Unix(0, 0).UTC(), time.Unix(0, 1000000).UTC()},
+		},
+		{
+			name:  "group by",
+			query: `GROUP BY ExecutionStatus`,
+			stmt:  templateListVisibilityQuery,
+			args:  []interface{}{"ns"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			q, err := parseVisibilityQuery(tc.query)
+			require.NoError(t, err)
+
+			stmt, args := listVisibilityStatement("ns", q)
+			assert.Equal(t, tc.stmt, stmt)
+			assert.Equal(t, tc.args, args)
+		})
+	}
+}
+
+func TestParseVisibilityQueryUnsupported(t *testing.T) {
+	for _, query := range []string{
+		`WorkflowId = 'a' OR WorkflowId = 'b'`,
+		`CustomKeywordField = 'a'`,
+		`WorkflowId > 'a'`,
+		`WorkflowId IN ('a', 'b')`,
+		`ExecutionStatus = 'Sleeping'`,
+		`StartTime > 'yesterday'`,
+		`ORDER BY StartTime`,
+		`GROUP BY WorkflowType`,
+		`WorkflowId = ` + "`a`",
+	} {
+		_, err := parseVisibilityQuery(query)
+		assert.Error(t, err, query)
+	}
+}
+
+func TestVisibilityQueryMatches(t *testing.T) {
+	start := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	record := &visibilityRecord{
+		InternalWorkflowExecutionInfo: &store.InternalWorkflowExecutionInfo{
+			WorkflowID: "wf",
+			RunID:      "run",
+			TypeName:   "type",
+			StartTime:  start,
+			Status:     enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING,
+		},
+	}
+	divided := &visibilityRecord{
+		InternalWorkflowExecutionInfo: record.InternalWorkflowExecutionInfo,
+		namespaceDivision:             "internal",
+	}
+
+	for _, tc := range []struct {
+		query   string
+		record  *visibilityRecord
+		matches bool
+	}{
+		{query: ``, record: record, matches: true},
+		{query: ``, record: divided, matches: false},
+		{query: `TemporalNamespaceDivision = 'internal'`, record: divided, matches: true},
+		{query: `TemporalNamespaceDivision IS NOT NULL`, record: divided, matches: true},
+		{query: `TemporalNamespaceDivision IS NULL`, record: record, matches: true},
+		{query: `ExecutionStatus != 'Running'`, record: record, matches: false},
+		{query: `ExecutionStatus = 'Running' AND RunId = 'run'`, record: record, matches: true},
+		{query: `WorkflowType != 'type'`, record: record, matches: false},
+		{query: `StartTime >= '2025-03-01T12:00:00.0009Z'`, record: record, matches: true},
+		{query: `StartTime > '2025-03-01T12:00:00Z'`, record: record, matches: false},
+		{query: `StartTime BETWEEN '2025-03-01T00:00:00Z' AND '2025-03-02T00:00:00Z'`, record: record, matches: true},
+		{query: `CloseTime IS NULL`, record: record, matches: true},
+		{query: `CloseTime < '2030-01-01T00:00:00Z'`, record: record, matches: false},
+		{query: `CloseTime != '2030-01-01T00:00:00Z'`, record: record, matches: false},
+	} {
+		q, err := parseVisibilityQuery(tc.query)
+		require.NoError(t, err, tc.query)
+		assert.Equal(t, tc.matches, q.matches(tc.record), tc.query)
+	}
+}
@@ -0,0 +1,556 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	ybconfig "github.com/manetu/temporal-yugabyte/driver/config"
+	"github.com/manetu/temporal-yugabyte/utils/gocql"
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/server/common/config"
+	"go.temporal.io/server/common/log"
+	"go.temporal.io/server/common/metrics"
+	"go.temporal.io/server/common/namespace"
+	"go.temporal.io/server/common/payload"
+	p "go.temporal.io/server/common/persistence"
+	"go.temporal.io/server/common/persistence/visibility/manager"
+	"go.temporal.io/server/common/persistence/visibility/store"
+	"go.temporal.io/server/common/resolver"
+	"go.temporal.io/server/common/searchattribute"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// VisibilityStoreName is the name the Yugabyte visibility store reports to Temporal
+	VisibilityStoreName = "yugabyte"
+
+	// defaultVisibilityScanLimit is the number of index rows read per list page or count unless configured otherwise
+	defaultVisibilityScanLimit = 10000
+
+	visibilityColumns = `run_id, workflow_id, workflow_type_name, status, start_time, execution_time, close_time, ` +
+		`execution_duration, history_length, history_size_bytes, state_transition_count, memo, encoding, task_queue, ` +
+		`search_attributes, namespace_division, parent_workflow_id, parent_run_id, root_workflow_id, root_run_id `
+
+	templateUpsertVisibilityQuery = `INSERT INTO executions_visibility (namespace_id, ` + visibilityColumns + `) ` +
+		`VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) `
+
+	templateCreateVisibilityQuery = templateUpsertVisibilityQuery + `IF NOT EXISTS`
+
+	templateDeleteVisibilityQuery = `DELETE FROM executions_visibility ` +
+		`WHERE namespace_id = ? ` +
+		`and run_id = ? `
+
+	templateGetVisibilityQuery = `SELECT ` + visibilityColumns +
+		`FROM executions_visibility ` +
+		`WHERE namespace_id = ? ` +
+		`and run_id = ? `
+
+	// templateListVisibilityQuery is completed by listVisibilityStatement with the clauses that steer the read to
+	// one of the executions_visibility indexes
+	templateListVisibilityQuery = `SELECT ` + visibilityColumns +
+		`FROM executions_visibility ` +
+		`WHERE namespace_id = ? `
+)
+
+type (
+	// VisibilityStoreFactory creates visibility stores backed by Yugabyte.  Register it with
+	// temporal.WithCustomVisibilityStoreFactory.
+	VisibilityStoreFactory struct {
+	}
+
+	// VisibilityStore is a visibility store backed by the executions_visibility table and its indexes.  Queries may
+	// filter on a subset of the system search attributes; the filter is evaluated against the rows of the index
+	// best suited to it, in descending order of start time, reading at most scanLimit rows per page or count.
+	VisibilityStore struct {
+		keyspace  string
+		session   gocql.Session
+		logger    log.Logger
+		scanLimit int
+	}
+)
+
+var _ store.VisibilityStore = (*VisibilityStore)(nil)
+
+// NewVisibilityStore returns a Yugabyte visibility store configured from the given custom datastore
+func (f *VisibilityStoreFactory) NewVisibilityStore(
+	cfg config.CustomDatastoreConfig,
+	_ searchattribute.Provider,
+	_ searchattribute.MapperProvider,
+	_ namespace.Registry,
+	r resolver.ServiceResolver,
+	logger log.Logger,
+	metricsHandler metrics.Handler,
+) (store.VisibilityStore, error) {
+	ccfg, err := ybconfig.ImportConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	session, err := newSession(ccfg, r, logger, metricsHandler)
+	if err != nil {
+		return nil, err
+	}
+	return newVisibilityStore(ccfg.Keyspace, session, logger, ccfg.VisibilityScanLimit), nil
+}
+
+// NewVisibilityStore returns a visibility store for the given keyspace.  The store takes ownership of the session.
+func NewVisibilityStore(
+	keyspace string,
+	session gocql.Session,
+	logger log.Logger,
+) *VisibilityStore {
+	return newVisibilityStore(keyspace, session, logger, 0)
+}
+
+// newVisibilityStore returns a visibility store reading at most scanLimit index rows per list page or count, or
+// defaultVisibilityScanLimit rows if not positive
+func newVisibilityStore(
+	keyspace string,
+	session gocql.Session,
+	logger log.Logger,
+	scanLimit int,
+) *VisibilityStore {
+	if scanLimit <= 0 {
+		scanLimit = defaultVisibilityScanLimit
+	}
+	return &VisibilityStore{
+		keyspace:  keyspace,
+		session:   session,
+		logger:    logger,
+		scanLimit: scanLimit,
+	}
+}
+
+func (s *VisibilityStore) Close() {
+	s.session.Close()
+}
+
+func (s *VisibilityStore) GetName() string {
+	return VisibilityStoreName
+}
+
+func (s *VisibilityStore) GetIndexName() string {
+	return s.keyspace
+}
+
+// ValidateCustomSearchAttributes accepts every custom search attribute: they are stored with the run, although
+// queries cannot filter on them
+func (s *VisibilityStore) ValidateCustomSearchAttributes(searchAttributes map[string]any) (map[string]any, error) {
+	return searchAttributes, nil
+}
+
+func (s *VisibilityStore) RecordWorkflowExecutionStarted(
+	ctx context.Context,
+	request *store.InternalRecordWorkflowExecutionStartedRequest,
+) error {
+	ctx = gocql.WithOperation(ctx, "RecordWorkflowExecutionStarted")
+	values, err := visibilityValues(request.InternalVisibilityRequestBase, nil)
+	if err != nil {
+		return err
+	}
+
+	// a replayed start must not overwrite the record of a run that has since been updated or closed
+	previous := make(map[string]interface{})
+	_, err = s.session.Query(templateCreateVisibilityQuery, values...).WithContext(ctx).MapScanCAS(previous)
+	return gocql.ConvertError("RecordWorkflowExecutionStarted", err)
+}
+
+func (s *VisibilityStore) RecordWorkflowExecutionClosed(
+	ctx context.Context,
+	request *store.InternalRecordWorkflowExecutionClosedRequest,
+) error {
+	ctx = gocql.WithOperation(ctx, "RecordWorkflowExecutionClosed")
+	values, err := visibilityValues(request.InternalVisibilityRequestBase, request)
+	if err != nil {
+		return err
+	}
+
+	err = s.session.Query(templateUpsertVisibilityQuery, values...).WithContext(ctx).Exec()
+	return gocql.ConvertError("RecordWorkflowExecutionClosed", err)
+}
+
+func (s *VisibilityStore) UpsertWorkflowExecution(
+	ctx context.Context,
+	request *store.InternalUpsertWorkflowExecutionRequest,
+) error {
+	ctx = gocql.WithOperation(ctx, "UpsertWorkflowExecution")
+	values, err := visibilityValues(request.InternalVisibilityRequestBase, nil)
+	if err != nil {
+		return err
+	}
+
+	err = s.session.Query(templateUpsertVisibilityQuery, values...).WithContext(ctx).Exec()
+	return gocql.ConvertError("UpsertWorkflowExecution", err)
+}
+
+func (s *VisibilityStore) DeleteWorkflowExecution(
+	ctx context.Context,
+	request *manager.VisibilityDeleteWorkflowExecutionRequest,
+) error {
+	ctx = gocql.WithOperation(ctx, "DeleteWorkflowExecution")
+	err := s.session.Query(templateDeleteVisibilityQuery,
+		request.NamespaceID.String(),
+		request.RunID,
+	).WithContext(ctx).Exec()
+	return gocql.ConvertError("DeleteWorkflowExecution", err)
+}
+
+func (s *VisibilityStore) GetWorkflowExecution(
+	ctx context.Context,
+	request *manager.GetWorkflowExecutionRequest,
+) (*store.InternalGetWorkflowExecutionResponse, error) {
+	ctx = gocql.WithOperation(ctx, "GetWorkflowExecution")
+	var row visibilityRow
+	err := s.session.Query(templateGetVisibilityQuery,
+		request.NamespaceID.String(),
+		request.RunID,
+	).WithContext(ctx).Scan(row.dest()...)
+	if err != nil {
+		return nil, gocql.ConvertError("GetWorkflowExecution", err)
+	}
+
+	record, err := row.record()
+	if err != nil {
+		return nil, err
+	}
+	return &store.InternalGetWorkflowExecutionResponse{
+		Execution: record.InternalWorkflowExecutionInfo,
+	}, nil
+}
+
+func (s *VisibilityStore) ListWorkflowExecutions(
+	ctx context.Context,
+	request *manager.ListWorkflowExecutionsRequestV2,
+) (*store.InternalListWorkflowExecutionsResponse, error) {
+	ctx = gocql.WithOperation(ctx, "ListWorkflowExecutions")
+	q, err := parseVisibilityQuery(request.Query)
+	if err != nil {
+		return nil, err
+	}
+	if q.groupBy != "" {
+		return nil, serviceerror.NewInvalidArgument("GROUP BY is only supported by CountWorkflowExecutions")
+	}
+
+	// Rows the filter rejects are skipped, so a page of the index may yield fewer executions than requested.  Read
+	// pages until the response is full, the index is exhausted or scanLimit rows have been read, and resume from the
+	// last page state.  A response may therefore hold fewer executions than requested, or none, and still go on.
+	stmt, args := listVisibilityStatement(request.NamespaceID.String(), q)
+	response := &store.InternalListWorkflowExecutionsResponse{}
+	pageState := request.NextPageToken
+	scanned := 0
+	for {
+		iter := s.session.Query(stmt, args...).
+			WithContext(ctx).
+			PageSize(min(request.PageSize-len(response.Executions), s.scanLimit-scanned)).
+			PageState(pageState).
+			Iter()
+
+		var row visibilityRow
+		for iter.Scan(row.dest()...) {
+			scanned++
+			record, err := row.record()
+			if err != nil {
+				_ = iter.Close()
+				return nil, err
+			}
+			if q.matches(record) {
+				response.Executions = append(response.Executions, record.InternalWorkflowExecutionInfo)
+			}
+			row = visibilityRow{}
+		}
+		pageState = iter.PageState()
+		if err := iter.Close(); err != nil {
+			return nil, gocql.ConvertError("ListWorkflowExecutions", err)
+		}
+		if len(pageState) == 0 || len(response.Executions) >= request.PageSize || scanned >= s.scanLimit {
+			break
+		}
+	}
+	response.NextPageToken = pageState
+	return response, nil
+}
+
+func (s *VisibilityStore) ScanWorkflowExecutions(
+	ctx context.Context,
+	request *manager.ListWorkflowExecutionsRequestV2,
+) (*store.InternalListWorkflowExecutionsResponse, error) {
+	ctx = gocql.WithOperation(ctx, "ScanWorkflowExecutions")
+	return s.ListWorkflowExecutions(ctx, request)
+}
+
+func (s *VisibilityStore) CountWorkflowExecutions(
+	ctx context.Context,
+	request *manager.CountWorkflowExecutionsRequest,
+) (*manager.CountWorkflowExecutionsResponse, error) {
+	ctx = gocql.WithOperation(ctx, "CountWorkflowExecutions")
+	q, err := parseVisibilityQuery(request.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	// A count cannot be returned in part, so a query which would read more than scanLimit rows of the index is
+	// rejected rather than answered with a count of the rows read
+	stmt, args := listVisibilityStatement(request.NamespaceID.String(), q)
+	response := &manager.CountWorkflowExecutionsResponse{}
+	counts := make(map[enumspb.WorkflowExecutionStatus]int64)
+	var pageState []byte
+	scanned := 0
+	for {
+		iter := s.session.Query(stmt, args...).
+			WithContext(ctx).
+			PageSize(s.scanLimit - scanned + 1).
+			PageState(pageState).
+			Iter()
+
+		var row visibilityRow
+		for iter.Scan(row.dest()...) {
+			if scanned++; scanned > s.scanLimit {
+				_ = iter.Close()
+				return nil, serviceerror.NewInvalidArgument(fmt.Sprintf(
+					"query reads more than %d visibility records; narrow it by WorkflowId, WorkflowType, "+
+						"ExecutionStatus or StartTime, or raise visibilityScanLimit", s.scanLimit))
+			}
+			record, err := row.record()
+			if err != nil {
+				_ = iter.Close()
+				return nil, err
+			}
+			if q.matches(record) {
+				response.Count++
+				counts[record.Status]++
+			}
+			row = visibilityRow{}
+		}
+		pageState = iter.PageState()
+		if err := iter.Close(); err != nil {
+			return nil, gocql.ConvertError("CountWorkflowExecutions", err)
+		}
+		if len(pageState) == 0 {
+			break
+		}
+	}
+
+	if q.groupBy == "" {
+		return response, nil
+	}
+
+	statuses := make([]enumspb.WorkflowExecutionStatus, 0, len(counts))
+	for status := range counts {
+		statuses = append(statuses, status)
+	}
+	slices.SortFunc(statuses, func(a, b enumspb.WorkflowExecutionStatus) int {
+		if counts[a] != counts[b] {
+			return int(counts[b] - counts[a])
+		}
+		return int(a - b)
+	})
+	for _, status := range statuses {
+		value, err := searchattribute.EncodeValue(status.String(), enumspb.INDEXED_VALUE_TYPE_KEYWORD)
+		if err != nil {
+			return nil, err
+		}
+		response.Groups = append(response.Groups, &workflowservice.CountWorkflowExecutionsResponse_AggregationGroup{
+			GroupValues: []*commonpb.Payload{value},
+			Count:       counts[status],
+		})
+	}
+	return response, nil
+}
+
+// listVisibilityStatement completes templateListVisibilityQuery for q.  An equality on the run ID reads the one run
+// by its primary key.  Otherwise an equality on the workflow ID, the workflow type or the status, in that order of
+// preference, selects the index partitioned by it, and bounds on the start time become a range on the clustering key
+// of that index.  The remaining conditions are left to visibilityQuery.matches.
+func listVisibilityStatement(namespaceID string, q *visibilityQuery) (string, []interface{}) {
+	if v, ok := q.equals(searchattribute.RunID); ok {
+		return templateGetVisibilityQuery, []interface{}{namespaceID, v}
+	}
+
+	stmt := templateListVisibilityQuery
+	args := []interface{}{namespaceID}
+
+	if v, ok := q.equals(searchattribute.WorkflowID); ok {
+		stmt += `and workflow_id = ? `
+		args = append(args, v)
+	} else if v, ok := q.equals(searchattribute.WorkflowType); ok {
+		stmt += `and workflow_type_name = ? `
+		args = append(args, v)
+	} else if v, ok := q.equals(searchattribute.ExecutionStatus); ok {
+		stmt += `and status = ? `
+		args = append(args, int32(v.(enumspb.WorkflowExecutionStatus)))
+	}
+
+	from, to := q.startTimeRange()
+	if !from.IsZero() {
+		stmt += `and start_time >= ? `
+		args = append(args, from)
+	}
+	if !to.IsZero() {
+		stmt += `and start_time <= ? `
+		args = append(args, to)
+	}
+	return stmt, args
+}
+
+// visibilityValues returns the bind values of templateUpsertVisibilityQuery for a run, including its close
+// attributes when closed is not nil
+func visibilityValues(
+	request *store.InternalVisibilityRequestBase,
+	closed *store.InternalRecordWorkflowExecutionClosedRequest,
+) ([]interface{}, error) {
+	var memo []byte
+	var encoding string
+	if request.Memo != nil {
+		memo = request.Memo.Data
+		encoding = request.Memo.EncodingType.String()
+	}
+
+	var searchAttributes []byte
+	var division string
+	if len(request.SearchAttributes.GetIndexedFields()) > 0 {
+		var err error
+		searchAttributes, err = proto.Marshal(request.SearchAttributes)
+		if err != nil {
+			return nil, serviceerror.NewInternal("unable to serialize search attributes: " + err.Error())
+		}
+		if value, ok := request.SearchAttributes.IndexedFields[searchattribute.TemporalNamespaceDivision]; ok {
+			if err := payload.Decode(value, &division); err != nil {
+				return nil, serviceerror.NewInvalidArgument("invalid " + searchattribute.TemporalNamespaceDivision + ": " + err.Error())
+			}
+		}
+	}
+
+	var closeTime, executionDuration, historyLength, historySizeBytes, stateTransitionCount interface{}
+	if closed != nil {
+		closeTime = visibilityTime(closed.CloseTime)
+		executionDuration = closed.ExecutionDuration.Nanoseconds()
+		historyLength = closed.HistoryLength
+		historySizeBytes = closed.HistorySizeBytes
+		stateTransitionCount = closed.StateTransitionCount
+	}
+
+	var parentWorkflowID, parentRunID interface{}
+	if request.ParentWorkflowID != nil {
+		parentWorkflowID = *request.ParentWorkflowID
+	}
+	if request.ParentRunID != nil {
+		parentRunID = *request.ParentRunID
+	}
+
+	return []interface{}{
+		request.NamespaceID,
+		request.RunID,
+		request.WorkflowID,
+		request.WorkflowTypeName,
+		int32(request.Status),
+		visibilityTime(request.StartTime),
+		visibilityTime(request.ExecutionTime),
+		closeTime,
+		executionDuration,
+		historyLength,
+		historySizeBytes,
+		stateTransitionCount,
+		memo,
+		encoding,
+		request.TaskQueue,
+		searchAttributes,
+		division,
+		parentWorkflowID,
+		parentRunID,
+		request.RootWorkflowID,
+		request.RootRunID,
+	}, nil
+}
+
+// visibilityRow holds the columns of visibilityColumns as scanned
+type visibilityRow struct {
+	runID                string
+	workflowID           string
+	typeName             string
+	status               int32
+	startTime            time.Time
+	executionTime        time.Time
+	closeTime            time.Time
+	executionDuration    int64
+	historyLength        int64
+	historySizeBytes     int64
+	stateTransitionCount int64
+	memo                 []byte
+	encoding             string
+	taskQueue            string
+	searchAttributes     []byte
+	namespaceDivision    string
+	parentWorkflowID     string
+	parentRunID          string
+	rootWorkflowID       string
+	rootRunID            string
+}
+
+func (r *visibilityRow) dest() []interface{} {
+	return []interface{}{
+		&r.runID, &r.workflowID, &r.typeName, &r.status, &r.startTime, &r.executionTime, &r.closeTime,
+		&r.executionDuration, &r.historyLength, &r.historySizeBytes, &r.stateTransitionCount, &r.memo, &r.encoding,
+		&r.taskQueue, &r.searchAttributes, &r.namespaceDivision, &r.parentWorkflowID, &r.parentRunID,
+		&r.rootWorkflowID, &r.rootRunID,
+	}
+}
+
+func (r *visibilityRow) record() (*visibilityRecord, error) {
+	info := &store.InternalWorkflowExecutionInfo{
+		WorkflowID:           r.workflowID,
+		RunID:                r.runID,
+		TypeName:             r.typeName,
+		StartTime:            r.startTime,
+		ExecutionTime:        r.executionTime,
+		CloseTime:            r.closeTime,
+		ExecutionDuration:    time.Duration(r.executionDuration),
+		Status:               enumspb.WorkflowExecutionStatus(r.status),
+		HistoryLength:        r.historyLength,
+		HistorySizeBytes:     r.historySizeBytes,
+		StateTransitionCount: r.stateTransitionCount,
+		TaskQueue:            r.taskQueue,
+		ParentWorkflowID:     r.parentWorkflowID,
+		ParentRunID:          r.parentRunID,
+		RootWorkflowID:       r.rootWorkflowID,
+		RootRunID:            r.rootRunID,
+	}
+	if len(r.memo) > 0 {
+		info.Memo = p.NewDataBlob(r.memo, r.encoding)
+	}
+	if len(r.searchAttributes) > 0 {
+		info.SearchAttributes = &commonpb.SearchAttributes{}
+		if err := proto.Unmarshal(r.searchAttributes, info.SearchAttributes); err != nil {
+			return nil, serviceerror.NewInternal("unable to deserialize search attributes: " + err.Error())
+		}
+	}
+	return &visibilityRecord{
+		InternalWorkflowExecutionInfo: info,
+		namespaceDivision:             r.namespaceDivision,
+	}, nil
+}
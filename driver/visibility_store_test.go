@@ -0,0 +1,183 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package driver
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/manetu/temporal-yugabyte/utils/gocql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/server/common/log"
+	"go.temporal.io/server/common/namespace"
+	"go.temporal.io/server/common/persistence/visibility/manager"
+)
+
+type (
+	// visibilitySession serves the rows of an index to every query, a page at a time, counting the rows read
+	visibilitySession struct {
+		fakeSession
+		rows    []visibilityRow
+		scanned int
+	}
+
+	visibilityPagedQuery struct {
+		gocql.Query
+		session   *visibilitySession
+		pageSize  int
+		pageState []byte
+	}
+
+	visibilityPagedIter struct {
+		gocql.Iter
+		session   *visibilitySession
+		rows      []visibilityRow
+		pageState []byte
+	}
+)
+
+func (s *visibilitySession) Query(string, ...interface{}) gocql.Query {
+	return &visibilityPagedQuery{session: s}
+}
+
+func (q *visibilityPagedQuery) WithContext(context.Context) gocql.Query {
+	return q
+}
+
+func (q *visibilityPagedQuery) PageSize(n int) gocql.Query {
+	q.pageSize = n
+	return q
+}
+
+func (q *visibilityPagedQuery) PageState(state []byte) gocql.Query {
+	q.pageState = state
+	return q
+}
+
+func (q *visibilityPagedQuery) Iter() gocql.Iter {
+	start := 0
+	if len(q.pageState) > 0 {
+		start, _ = strconv.Atoi(string(q.pageState))
+	}
+	end := min(start+q.pageSize, len(q.session.rows))
+	iter := &visibilityPagedIter{session: q.session, rows: q.session.rows[start:end]}
+	if end < len(q.session.rows) {
+		iter.pageState = []byte(strconv.Itoa(end))
+	}
+	return iter
+}
+
+func (i *visibilityPagedIter) Scan(dest ...interface{}) bool {
+	if len(i.rows) == 0 {
+		return false
+	}
+	row := i.rows[0]
+	i.rows = i.rows[1:]
+	i.session.scanned++
+	*dest[0].(*string) = row.runID
+	*dest[1].(*string) = row.workflowID
+	*dest[2].(*string) = row.typeName
+	*dest[3].(*int32) = row.status
+	*dest[4].(*time.Time) = row.startTime
+	return true
+}
+
+func (i *visibilityPagedIter) PageState() []byte {
+	return i.pageState
+}
+
+func (i *visibilityPagedIter) Close() error {
+	return nil
+}
+
+// newScanLimitedVisibilityStore returns a store over 100 runs of which every 25th has failed
+func newScanLimitedVisibilityStore(scanLimit int) (*VisibilityStore, *visibilitySession) {
+	session := &visibilitySession{}
+	start := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 100; i++ {
+		status := enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING
+		if i%25 == 0 {
+			status = enumspb.WORKFLOW_EXECUTION_STATUS_FAILED
+		}
+		session.rows = append(session.rows, visibilityRow{
+			runID:      strconv.Itoa(i),
+			workflowID: "wf-" + strconv.Itoa(i),
+			typeName:   "type",
+			status:     int32(status),
+			startTime:  start.Add(-time.Duration(i) * time.Minute),
+		})
+	}
+	return newVisibilityStore("temporal_visibility", session, log.NewNoopLogger(), scanLimit), session
+}
+
+func TestVisibilityListBoundsRowsScanned(t *testing.T) {
+	s, session := newScanLimitedVisibilityStore(30)
+	const query = `WorkflowType = 'type' AND ExecutionStatus = 'Failed'`
+
+	var runIDs []string
+	var token []byte
+	for calls := 1; ; calls++ {
+		session.scanned = 0
+		response, err := s.ListWorkflowExecutions(context.Background(), &manager.ListWorkflowExecutionsRequestV2{
+			NamespaceID:   namespace.ID("ns"),
+			PageSize:      10,
+			NextPageToken: token,
+			Query:         query,
+		})
+		require.NoError(t, err)
+		assert.LessOrEqual(t, session.scanned, 30)
+		for _, execution := range response.Executions {
+			runIDs = append(runIDs, execution.RunID)
+		}
+		token = response.NextPageToken
+		if len(token) == 0 {
+			assert.Equal(t, 4, calls)
+			break
+		}
+	}
+	assert.Equal(t, []string{"0", "25", "50", "75"}, runIDs)
+}
+
+func TestVisibilityCountBoundsRowsScanned(t *testing.T) {
+	request := &manager.CountWorkflowExecutionsRequest{
+		NamespaceID: namespace.ID("ns"),
+		Query:       `WorkflowType = 'type' AND ExecutionStatus = 'Failed'`,
+	}
+
+	s, session := newScanLimitedVisibilityStore(30)
+	_, err := s.CountWorkflowExecutions(context.Background(), request)
+	var invalidArgument *serviceerror.InvalidArgument
+	require.ErrorAs(t, err, &invalidArgument)
+	assert.Contains(t, err.Error(), "more than 30 visibility records")
+	assert.Equal(t, 31, session.scanned)
+
+	s, _ = newScanLimitedVisibilityStore(100)
+	response, err := s.CountWorkflowExecutions(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), response.Count)
+}
@@ -0,0 +1,138 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ysql
+
+import (
+	"context"
+	gosql "database/sql"
+
+	ybschema "github.com/manetu/temporal-yugabyte/schema/yugabyte"
+	"go.temporal.io/server/common/metrics"
+	"go.temporal.io/server/common/persistence/schema"
+	"go.temporal.io/server/common/persistence/sql/sqlplugin"
+)
+
+type (
+	// db retries the visibility statements of the PostgreSQL plugin which fail with a YSQL transaction conflict.
+	// Each of them is a single statement, which YSQL applies or rolls back as a whole.  Explicit transactions are not
+	// retried, as only their owner can run them again.
+	db struct {
+		sqlplugin.DB
+		retry          *retryPolicy
+		metricsHandler metrics.Handler
+	}
+
+	// adminDB verifies visibility databases against the YSQL visibility schema, which is versioned independently of
+	// the PostgreSQL schema
+	adminDB struct {
+		sqlplugin.AdminDB
+		dbKind sqlplugin.DbKind
+		dbName string
+	}
+)
+
+var _ sqlplugin.DB = (*db)(nil)
+var _ sqlplugin.AdminDB = (*adminDB)(nil)
+
+func newDB(delegate sqlplugin.DB, retry *retryPolicy, metricsHandler metrics.Handler) *db {
+	return &db{
+		DB:             delegate,
+		retry:          retry,
+		metricsHandler: metricsHandler,
+	}
+}
+
+func newAdminDB(delegate sqlplugin.AdminDB, dbKind sqlplugin.DbKind, dbName string) *adminDB {
+	return &adminDB{
+		AdminDB: delegate,
+		dbKind:  dbKind,
+		dbName:  dbName,
+	}
+}
+
+// withRetry runs op under the retry policy of d, tagging retries with the operation
+func withRetry[T any](ctx context.Context, d *db, operation string, op func() (T, error)) (T, error) {
+	var result T
+	err := d.retry.execute(ctx, d.metricsHandler.WithTags(metrics.OperationTag(operation)), func() error {
+		var err error
+		result, err = op()
+		return err
+	})
+	return result, err
+}
+
+func (d *db) InsertIntoVisibility(ctx context.Context, row *sqlplugin.VisibilityRow) (gosql.Result, error) {
+	return withRetry(ctx, d, "InsertIntoVisibility", func() (gosql.Result, error) {
+		return d.DB.InsertIntoVisibility(ctx, row)
+	})
+}
+
+func (d *db) ReplaceIntoVisibility(ctx context.Context, row *sqlplugin.VisibilityRow) (gosql.Result, error) {
+	return withRetry(ctx, d, "ReplaceIntoVisibility", func() (gosql.Result, error) {
+		return d.DB.ReplaceIntoVisibility(ctx, row)
+	})
+}
+
+func (d *db) DeleteFromVisibility(ctx context.Context, filter sqlplugin.VisibilityDeleteFilter) (gosql.Result, error) {
+	return withRetry(ctx, d, "DeleteFromVisibility", func() (gosql.Result, error) {
+		return d.DB.DeleteFromVisibility(ctx, filter)
+	})
+}
+
+func (d *db) SelectFromVisibility(ctx context.Context, filter sqlplugin.VisibilitySelectFilter) ([]sqlplugin.VisibilityRow, error) {
+	return withRetry(ctx, d, "SelectFromVisibility", func() ([]sqlplugin.VisibilityRow, error) {
+		return d.DB.SelectFromVisibility(ctx, filter)
+	})
+}
+
+func (d *db) GetFromVisibility(ctx context.Context, filter sqlplugin.VisibilityGetFilter) (*sqlplugin.VisibilityRow, error) {
+	return withRetry(ctx, d, "GetFromVisibility", func() (*sqlplugin.VisibilityRow, error) {
+		return d.DB.GetFromVisibility(ctx, filter)
+	})
+}
+
+func (d *db) CountFromVisibility(ctx context.Context, filter sqlplugin.VisibilitySelectFilter) (int64, error) {
+	return withRetry(ctx, d, "CountFromVisibility", func() (int64, error) {
+		return d.DB.CountFromVisibility(ctx, filter)
+	})
+}
+
+func (d *db) CountGroupByFromVisibility(ctx context.Context, filter sqlplugin.VisibilitySelectFilter) ([]sqlplugin.VisibilityCountRow, error) {
+	return withRetry(ctx, d, "CountGroupByFromVisibility", func() ([]sqlplugin.VisibilityCountRow, error) {
+		return d.DB.CountGroupByFromVisibility(ctx, filter)
+	})
+}
+
+// ExpectedVersion returns the YSQL visibility schema version for visibility databases, and the PostgreSQL schema
+// version otherwise
+func (a *adminDB) ExpectedVersion() string {
+	if a.dbKind == sqlplugin.DbKindVisibility {
+		return ybschema.YSQLVisibilityVersion
+	}
+	return a.AdminDB.ExpectedVersion()
+}
+
+// VerifyVersion verifies that the schema version of the database is compatible with ExpectedVersion
+func (a *adminDB) VerifyVersion() error {
+	return schema.VerifyCompatibleVersion(a.AdminDB, a.dbName, a.ExpectedVersion())
+}
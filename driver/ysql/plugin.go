@@ -0,0 +1,147 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package ysql provides the yugabyte-ysql SQL plugin, which runs the PostgreSQL plugin against the YSQL API of
+// YugabyteDB.  It is intended for SQL visibility, alongside the YCQL datastore of the driver package.
+package ysql
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"go.temporal.io/server/common/config"
+	"go.temporal.io/server/common/log"
+	"go.temporal.io/server/common/metrics"
+	"go.temporal.io/server/common/persistence/sql"
+	"go.temporal.io/server/common/persistence/sql/sqlplugin"
+	"go.temporal.io/server/common/persistence/sql/sqlplugin/postgresql"
+	"go.temporal.io/server/common/resolver"
+)
+
+const (
+	// PluginName is the name of the plugin, to be used as the pluginName of a SQL datastore
+	PluginName = "yugabyte-ysql"
+
+	// delegatePluginName is the PostgreSQL plugin the statements are executed by.  Its pgx driver accepts a list of
+	// hosts and fails over between them.
+	delegatePluginName = postgresql.PluginNamePGX
+)
+
+type (
+	plugin struct {
+	}
+
+	// balancingResolver resolves a comma-separated list of YSQL nodes to a multi-host address, rotating the order of
+	// the nodes on every resolution so that the connection pools of successive handles favour different nodes
+	balancingResolver struct {
+		resolver resolver.ServiceResolver
+		next     atomic.Uint32
+	}
+)
+
+var _ sqlplugin.Plugin = (*plugin)(nil)
+
+func init() {
+	sql.RegisterPlugin(PluginName, &plugin{})
+}
+
+// CreateDB returns a handle on the database which retries YSQL transaction conflicts
+func (p *plugin) CreateDB(
+	dbKind sqlplugin.DbKind,
+	cfg *config.SQL,
+	r resolver.ServiceResolver,
+	logger log.Logger,
+	metricsHandler metrics.Handler,
+) (sqlplugin.DB, error) {
+	dcfg, retry, err := delegateConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.NewSQLDB(dbKind, dcfg, newBalancingResolver(r), logger, metricsHandler)
+	if err != nil {
+		return nil, err
+	}
+	return newDB(db, retry, metricsHandler), nil
+}
+
+// CreateAdminDB returns a handle on the database for the schema tools, which verifies the visibility schema against
+// the YSQL visibility schema of this repository
+func (p *plugin) CreateAdminDB(
+	dbKind sqlplugin.DbKind,
+	cfg *config.SQL,
+	r resolver.ServiceResolver,
+	logger log.Logger,
+	metricsHandler metrics.Handler,
+) (sqlplugin.AdminDB, error) {
+	dcfg, _, err := delegateConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.NewSQLAdminDB(dbKind, dcfg, newBalancingResolver(r), logger, metricsHandler)
+	if err != nil {
+		return nil, err
+	}
+	return newAdminDB(db, dbKind, cfg.DatabaseName), nil
+}
+
+// delegateConfig returns the configuration of the PostgreSQL plugin for cfg, without the connect attributes
+// interpreted by this plugin, and the retry policy those attributes describe
+func delegateConfig(cfg *config.SQL) (*config.SQL, *retryPolicy, error) {
+	dcfg := *cfg
+	dcfg.PluginName = delegatePluginName
+	dcfg.ConnectAttributes = make(map[string]string, len(cfg.ConnectAttributes))
+	for k, v := range cfg.ConnectAttributes {
+		dcfg.ConnectAttributes[k] = v
+	}
+
+	retry, err := newRetryPolicy(dcfg.ConnectAttributes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &dcfg, retry, nil
+}
+
+func newBalancingResolver(r resolver.ServiceResolver) *balancingResolver {
+	if r == nil {
+		r = resolver.NewNoopResolver()
+	}
+	return &balancingResolver{resolver: r}
+}
+
+func (b *balancingResolver) Resolve(service string) []string {
+	var nodes []string
+	for _, node := range strings.Split(service, ",") {
+		if node = strings.TrimSpace(node); node != "" {
+			nodes = append(nodes, b.resolver.Resolve(node)...)
+		}
+	}
+	switch len(nodes) {
+	case 0:
+		return []string{service}
+	case 1:
+		return nodes
+	}
+
+	start := int(b.next.Add(1)-1) % len(nodes)
+	rotated := append(nodes[start:len(nodes):len(nodes)], nodes[:start]...)
+	return []string{strings.Join(rotated, ",")}
+}
@@ -0,0 +1,136 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/server/common/config"
+	"go.temporal.io/server/common/metrics"
+	"go.temporal.io/server/common/resolver"
+)
+
+type fakeSQLStateError string
+
+func (e fakeSQLStateError) Error() string {
+	return "SQLSTATE " + string(e)
+}
+
+func (e fakeSQLStateError) SQLState() string {
+	return string(e)
+}
+
+func TestIsTransactionConflict(t *testing.T) {
+	assert.True(t, IsTransactionConflict(fakeSQLStateError("40001")))
+	assert.True(t, IsTransactionConflict(fmt.Errorf("wrapped: %w", fakeSQLStateError("40P01"))))
+	assert.False(t, IsTransactionConflict(fakeSQLStateError("23505")))
+	assert.False(t, IsTransactionConflict(errors.New("40001")))
+	assert.False(t, IsTransactionConflict(nil))
+}
+
+func TestNewRetryPolicy(t *testing.T) {
+	attributes := map[string]string{
+		retryMaxAttemptsAttribute:     "7",
+		retryInitialIntervalAttribute: "50ms",
+		retryMaxIntervalAttribute:     "10ms",
+		"application_name":            "temporal",
+	}
+	p, err := newRetryPolicy(attributes)
+	require.NoError(t, err)
+	assert.Equal(t, &retryPolicy{maxAttempts: 7, initialInterval: 50 * time.Millisecond, maxInterval: 50 * time.Millisecond}, p)
+	assert.Equal(t, map[string]string{"application_name": "temporal"}, attributes)
+
+	p, err = newRetryPolicy(nil)
+	require.NoError(t, err)
+	assert.Equal(t, defaultRetryMaxAttempts, p.maxAttempts)
+
+	_, err = newRetryPolicy(map[string]string{retryMaxAttemptsAttribute: "0"})
+	assert.Error(t, err)
+	_, err = newRetryPolicy(map[string]string{retryMaxIntervalAttribute: "soon"})
+	assert.Error(t, err)
+}
+
+func TestRetryPolicyExecute(t *testing.T) {
+	p := &retryPolicy{maxAttempts: 3, initialInterval: time.Millisecond, maxInterval: time.Millisecond}
+
+	attempts := 0
+	err := p.execute(context.Background(), metrics.NoopMetricsHandler, func() error {
+		attempts++
+		if attempts < 3 {
+			return fakeSQLStateError("40001")
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+
+	attempts = 0
+	err = p.execute(context.Background(), metrics.NoopMetricsHandler, func() error {
+		attempts++
+		return fakeSQLStateError("40001")
+	})
+	assert.Equal(t, fakeSQLStateError("40001"), err)
+	assert.Equal(t, 3, attempts)
+
+	attempts = 0
+	err = p.execute(context.Background(), metrics.NoopMetricsHandler, func() error {
+		attempts++
+		return fakeSQLStateError("23505")
+	})
+	assert.Equal(t, fakeSQLStateError("23505"), err)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestBalancingResolver(t *testing.T) {
+	r := newBalancingResolver(resolver.NewNoopResolver())
+
+	assert.Equal(t, []string{"a:5433"}, r.Resolve("a:5433"))
+	assert.Equal(t, []string{"a:5433,b:5433,c:5433"}, r.Resolve("a:5433, b:5433,c:5433"))
+	assert.Equal(t, []string{"b:5433,c:5433,a:5433"}, r.Resolve("a:5433,b:5433,c:5433"))
+	assert.Equal(t, []string{"c:5433,a:5433,b:5433"}, r.Resolve("a:5433,b:5433,c:5433"))
+	assert.Equal(t, []string{""}, r.Resolve(""))
+}
+
+func TestDelegateConfig(t *testing.T) {
+	cfg := &config.SQL{
+		PluginName:        PluginName,
+		DatabaseName:      "temporal_visibility",
+		ConnectAttributes: map[string]string{retryMaxAttemptsAttribute: "2", "application_name": "temporal"},
+	}
+	dcfg, retry, err := delegateConfig(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, delegatePluginName, dcfg.PluginName)
+	assert.Equal(t, "temporal_visibility", dcfg.DatabaseName)
+	assert.Equal(t, map[string]string{"application_name": "temporal"}, dcfg.ConnectAttributes)
+	assert.Equal(t, 2, retry.maxAttempts)
+
+	// the configuration of the datastore is left untouched
+	assert.Equal(t, PluginName, cfg.PluginName)
+	assert.Len(t, cfg.ConnectAttributes, 2)
+}
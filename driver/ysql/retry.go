@@ -0,0 +1,176 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"go.temporal.io/server/common/metrics"
+)
+
+const (
+	// Connect attributes configuring the retry of transaction conflicts.  They are interpreted by this plugin and
+	// not passed on to YSQL.
+	retryMaxAttemptsAttribute     = "retryMaxAttempts"
+	retryInitialIntervalAttribute = "retryInitialInterval"
+	retryMaxIntervalAttribute     = "retryMaxInterval"
+
+	defaultRetryMaxAttempts     = 5
+	defaultRetryInitialInterval = 20 * time.Millisecond
+	defaultRetryMaxInterval     = time.Second
+)
+
+// conflictSQLStates are the SQLSTATE codes YSQL reports when a transaction was aborted by a conflict with another
+// transaction, or must be restarted to read a consistent snapshot.  The transaction was not applied, so the statement
+// may be retried.
+var conflictSQLStates = map[string]bool{
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected
+}
+
+// Metrics emitted by the plugin, tagged with the operation of the statement
+var (
+	StatementRetries = metrics.NewCounterDef(
+		"yugabyte_ysql_statement_retries",
+		metrics.WithDescription("YSQL statements retried after a transaction conflict"),
+	)
+	StatementRetriesExhausted = metrics.NewCounterDef(
+		"yugabyte_ysql_statement_retries_exhausted",
+		metrics.WithDescription("YSQL statements which still failed with a transaction conflict after the final attempt"),
+	)
+)
+
+type (
+	// retryPolicy retries statements which failed with a YSQL transaction conflict, backing off exponentially with
+	// jitter between attempts
+	retryPolicy struct {
+		maxAttempts     int
+		initialInterval time.Duration
+		maxInterval     time.Duration
+	}
+
+	// sqlStateError is implemented by the errors of both the pgx and pq drivers
+	sqlStateError interface {
+		SQLState() string
+	}
+)
+
+// newRetryPolicy returns the retry policy described by the connect attributes, applying defaults for anything not
+// set, and removes those attributes
+func newRetryPolicy(attributes map[string]string) (*retryPolicy, error) {
+	p := &retryPolicy{
+		maxAttempts:     defaultRetryMaxAttempts,
+		initialInterval: defaultRetryInitialInterval,
+		maxInterval:     defaultRetryMaxInterval,
+	}
+
+	if v, ok := attributes[retryMaxAttemptsAttribute]; ok {
+		delete(attributes, retryMaxAttemptsAttribute)
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("connect attribute %q: expected a positive integer, got %q", retryMaxAttemptsAttribute, v)
+		}
+		p.maxAttempts = n
+	}
+	for key, dest := range map[string]*time.Duration{
+		retryInitialIntervalAttribute: &p.initialInterval,
+		retryMaxIntervalAttribute:     &p.maxInterval,
+	} {
+		v, ok := attributes[key]
+		if !ok {
+			continue
+		}
+		delete(attributes, key)
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("connect attribute %q: expected a duration such as \"20ms\", got %q", key, v)
+		}
+		*dest = d
+	}
+	if p.maxInterval < p.initialInterval {
+		p.maxInterval = p.initialInterval
+	}
+
+	return p, nil
+}
+
+// execute runs op until it succeeds, fails with an error other than a transaction conflict or exhausts the attempts
+func (p *retryPolicy) execute(ctx context.Context, metricsHandler metrics.Handler, op func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := op()
+		if err == nil || !IsTransactionConflict(err) {
+			return err
+		}
+
+		if attempt >= p.maxAttempts {
+			if p.maxAttempts > 1 {
+				StatementRetriesExhausted.With(metricsHandler).Record(1)
+			}
+			return err
+		}
+
+		StatementRetries.With(metricsHandler).Record(1)
+		if !sleep(ctx, p.backoff(attempt)) {
+			return err
+		}
+	}
+}
+
+// backoff returns the delay before the given retry: exponential growth capped at maxInterval, of which the
+// upper half is randomized so that competing transactions do not collide again
+func (p *retryPolicy) backoff(attempt int) time.Duration {
+	d := p.initialInterval
+	for i := 1; i < attempt && d < p.maxInterval; i++ {
+		d *= 2
+	}
+	if d > p.maxInterval {
+		d = p.maxInterval
+	}
+
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// IsTransactionConflict returns true if the error reports that a YSQL transaction was aborted because it conflicted
+// with another transaction
+func IsTransactionConflict(err error) bool {
+	var stateErr sqlStateError
+	return errors.As(err, &stateErr) && conflictSQLStates[stateErr.SQLState()]
+}
@@ -33,6 +33,7 @@ import (
 
 	"github.com/manetu/temporal-yugabyte"
 	localconfig "github.com/manetu/temporal-yugabyte/driver/config"
+	ybschema "github.com/manetu/temporal-yugabyte/schema/yugabyte"
 	localgocql "github.com/manetu/temporal-yugabyte/utils/gocql"
 
 	"github.com/yugabyte/gocql"
@@ -51,6 +52,21 @@ import (
 
 const (
 	testSchemaDir = "../../schema/yugabyte/temporal"
+
+	temporalSchemaDir   = "schema/yugabyte/temporal/"
+	visibilitySchemaDir = "schema/yugabyte/visibility/"
+
+	createSchemaVersionTableCQL = `CREATE TABLE IF NOT EXISTS schema_version (` +
+		`keyspace_name text PRIMARY KEY, ` +
+		`creation_time timestamp, ` +
+		`curr_version text, ` +
+		`min_compatible_version text` +
+		`) WITH transactions = { 'enabled' : true }`
+
+	writeSchemaVersionCQL = `INSERT INTO schema_version (keyspace_name, creation_time, curr_version, min_compatible_version) ` +
+		`VALUES (?, ?, ?, ?)`
+
+	dropSchemaVersionTableCQL = `DROP TABLE IF EXISTS schema_version`
 )
 
 // TestCluster allows executing yugabyte operations in testing.
@@ -60,6 +76,8 @@ type TestCluster struct {
 	cfg            config.CustomDatastoreConfig
 	faultInjection *config.FaultInjection
 	logger         log.Logger
+	// visibility selects the visibility schema, and configures the cluster as the visibility store
+	visibility bool
 }
 
 // NewTestCluster returns a new yugabyte test cluster
@@ -93,13 +111,17 @@ func NewTestCluster(keyspace, username, password, host string, port int, schemaD
 // Config returns the persistence config for connecting to this test cluster
 func (s *TestCluster) Config() config.Persistence {
 	cfg := s.cfg
-	return config.Persistence{
+	result := config.Persistence{
 		DefaultStore: "test",
 		DataStores: map[string]config.DataStore{
 			"test": {CustomDataStoreConfig: &cfg, FaultInjection: s.faultInjection},
 		},
 		TransactionSizeLimit: dynamicconfig.GetIntPropertyFn(primitives.DefaultTransactionSizeLimit),
 	}
+	if s.visibility {
+		result.VisibilityStore = "test"
+	}
+	return result
 }
 
 func (s *TestCluster) CustomConfig() config.CustomDatastoreConfig {
@@ -117,11 +139,17 @@ func (s *TestCluster) SetupTestDatabase() {
 	s.CreateDatabase()
 	s.CreateSession(s.DatabaseName())
 	s.LoadSchema("create-schema.cql")
+	if !s.visibility {
+		s.recordSchemaVersion(ybschema.Version)
+	}
 }
 
 // TearDownTestDatabase from PersistenceTestCluster interface
 func (s *TestCluster) TearDownTestDatabase() {
 	s.LoadSchema("drop-schema.cql")
+	if err := s.session.Query(dropSchemaVersionTableCQL).Exec(); err != nil {
+		s.logger.Fatal("TearDownTestDatabase", tag.Error(err))
+	}
 	s.DropDatabase()
 	s.session.Close()
 }
@@ -189,7 +217,11 @@ func (s *TestCluster) DropDatabase() {
 
 // LoadSchema from PersistenceTestCluster interface
 func (s *TestCluster) LoadSchema(schemaFile string) {
-	schema, err := temporal_yugabyte.SchemaFs.ReadFile("schema/yugabyte/temporal/" + schemaFile)
+	schemaFs, schemaDir := temporal_yugabyte.SchemaFs, temporalSchemaDir
+	if s.visibility {
+		schemaFs, schemaDir = temporal_yugabyte.VisibilitySchemaFs, visibilitySchemaDir
+	}
+	schema, err := schemaFs.ReadFile(schemaDir + schemaFile)
 	if err != nil {
 		s.logger.Fatal("LoadSchema", tag.Error(err))
 	}
@@ -206,6 +238,17 @@ func (s *TestCluster) LoadSchema(schemaFile string) {
 	s.logger.Debug("loaded schema")
 }
 
+// recordSchemaVersion records the version of the schema loaded, as the schema tool would, so that the driver's
+// startup checks accept the keyspace
+func (s *TestCluster) recordSchemaVersion(version string) {
+	if err := s.session.Query(createSchemaVersionTableCQL).Exec(); err != nil {
+		s.logger.Fatal("recordSchemaVersion", tag.Error(err))
+	}
+	if err := s.session.Query(writeSchemaVersionCQL, s.keyspace, time.Now().UTC(), version, version).Exec(); err != nil {
+		s.logger.Fatal("recordSchemaVersion", tag.Error(err))
+	}
+}
+
 func (s *TestCluster) GetSession() localgocql.Session {
 	return s.session
 }
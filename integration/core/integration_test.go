@@ -34,6 +34,7 @@ import (
 	"time"
 
 	"github.com/manetu/temporal-yugabyte/driver"
+	localconfig "github.com/manetu/temporal-yugabyte/driver/config"
 	"github.com/manetu/temporal-yugabyte/utils/gocql"
 
 	"github.com/google/uuid"
@@ -43,6 +44,7 @@ import (
 	commonpb "go.temporal.io/api/common/v1"
 	enumspb "go.temporal.io/api/enums/v1"
 	"go.temporal.io/api/serviceerror"
+	enumsspb "go.temporal.io/server/api/enums/v1"
 	persistencespb "go.temporal.io/server/api/persistence/v1"
 	"go.temporal.io/server/common/log"
 	"go.temporal.io/server/common/log/tag"
@@ -52,6 +54,7 @@ import (
 	"go.temporal.io/server/common/persistence/serialization"
 	_ "go.temporal.io/server/common/persistence/sql/sqlplugin/mysql"
 	commontests "go.temporal.io/server/common/persistence/tests"
+	"go.temporal.io/server/service/history/tasks"
 )
 
 type (
@@ -188,6 +191,329 @@ func TestYugabyteExecutionMutableStateStoreSuite(t *testing.T) {
 	suite.Run(t, s)
 }
 
+func TestYugabyteExecutionMutableStateStoreSuiteWithMapTables(t *testing.T) {
+	testData, tearDown := setUpYugabyteTestWithOptions(t, map[string]any{"executionMapTables": true})
+	defer tearDown()
+
+	shardStore, err := testData.Factory.NewShardStore()
+	if err != nil {
+		t.Fatalf("unable to create Yugabyte DB: %v", err)
+	}
+	executionStore, err := testData.Factory.NewExecutionStore()
+	if err != nil {
+		t.Fatalf("unable to create Yugabyte DB: %v", err)
+	}
+
+	s := commontests.NewExecutionMutableStateSuite(
+		t,
+		shardStore,
+		executionStore,
+		serialization.NewSerializer(),
+		&persistence.HistoryBranchUtilImpl{},
+		testData.Logger,
+	)
+	suite.Run(t, s)
+}
+
+func TestYugabyteExecutionMapTablesMigration(t *testing.T) {
+	cluster := NewTestClusterForYugabyte(&persistencetests.TestBaseOptions{}, log.NewNoopLogger())
+	cluster.SetupTestDatabase()
+	t.Cleanup(cluster.TearDownTestDatabase)
+
+	ctx := context.Background()
+	session := cluster.GetSession()
+	collections := driver.NewFactoryFromSession(localconfig.Yugabyte{}, testYugabyteClusterName, log.NewNoopLogger(), session)
+	tables := driver.NewFactoryFromSession(localconfig.Yugabyte{ExecutionMapTables: true}, testYugabyteClusterName, log.NewNoopLogger(), session)
+	shardStore, err := collections.NewShardStore()
+	require.NoError(t, err)
+	collectionStore, err := collections.NewExecutionStore()
+	require.NoError(t, err)
+	tableStore, err := tables.NewExecutionStore()
+	require.NoError(t, err)
+
+	const shardID = 1
+	serializer := serialization.NewSerializer()
+	_, err = shardStore.GetOrCreateShard(ctx, &persistence.InternalGetOrCreateShardRequest{
+		ShardID: shardID,
+		CreateShardInfo: func() (int64, *commonpb.DataBlob, error) {
+			blob, err := serializer.ShardInfoToBlob(&persistencespb.ShardInfo{ShardId: shardID, RangeId: 1}, enumspb.ENCODING_TYPE_PROTO3)
+			return 1, blob, err
+		},
+	})
+	require.NoError(t, err)
+
+	blob := func(data string) *commonpb.DataBlob {
+		return persistence.NewDataBlob([]byte(data), enumspb.ENCODING_TYPE_PROTO3.String())
+	}
+	namespaceID := uuid.NewString()
+	workflowID := "execution-map-tables"
+	runID := uuid.NewString()
+	legacySignal := uuid.NewString()
+	newSignal := uuid.NewString()
+
+	executionInfo := &persistencespb.WorkflowExecutionInfo{NamespaceId: namespaceID, WorkflowId: workflowID}
+	executionInfoBlob, err := serializer.WorkflowExecutionInfoToBlob(executionInfo, enumspb.ENCODING_TYPE_PROTO3)
+	require.NoError(t, err)
+	executionState := &persistencespb.WorkflowExecutionState{
+		CreateRequestId: uuid.NewString(),
+		RunId:           runID,
+		State:           enumsspb.WORKFLOW_EXECUTION_STATE_RUNNING,
+		Status:          enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING,
+	}
+	executionStateBlob, err := serializer.WorkflowExecutionStateToBlob(executionState, enumspb.ENCODING_TYPE_PROTO3)
+	require.NoError(t, err)
+
+	// a workflow written before the map tables were enabled
+	_, err = collectionStore.CreateWorkflowExecution(ctx, &persistence.InternalCreateWorkflowExecutionRequest{
+		ShardID: shardID,
+		RangeID: 1,
+		Mode:    persistence.CreateWorkflowModeBrandNew,
+		NewWorkflowSnapshot: persistence.InternalWorkflowSnapshot{
+			NamespaceID:         namespaceID,
+			WorkflowID:          workflowID,
+			RunID:               runID,
+			ExecutionInfo:       executionInfo,
+			ExecutionInfoBlob:   executionInfoBlob,
+			ExecutionState:      executionState,
+			ExecutionStateBlob:  executionStateBlob,
+			NextEventID:         2,
+			DBRecordVersion:     1,
+			ActivityInfos:       map[int64]*commonpb.DataBlob{1: blob("legacy-1"), 2: blob("legacy-2")},
+			TimerInfos:          map[string]*commonpb.DataBlob{"legacy": blob("legacy-timer")},
+			ChildExecutionInfos: map[int64]*commonpb.DataBlob{},
+			RequestCancelInfos:  map[int64]*commonpb.DataBlob{},
+			SignalInfos:         map[int64]*commonpb.DataBlob{},
+			SignalRequestedIDs:  map[string]struct{}{legacySignal: {}},
+			Tasks:               map[tasks.Category][]persistence.InternalHistoryTask{},
+			Checksum:            &commonpb.DataBlob{EncodingType: enumspb.ENCODING_TYPE_PROTO3},
+		},
+	})
+	require.NoError(t, err)
+
+	get := func() *persistence.InternalWorkflowMutableState {
+		response, err := tableStore.GetWorkflowExecution(ctx, &persistence.GetWorkflowExecutionRequest{
+			ShardID:     shardID,
+			NamespaceID: namespaceID,
+			WorkflowID:  workflowID,
+			RunID:       runID,
+		})
+		require.NoError(t, err)
+		return response.State
+	}
+
+	// entries in the collections are still read once the map tables are enabled
+	state := get()
+	require.Equal(t, map[int64]*commonpb.DataBlob{1: blob("legacy-1"), 2: blob("legacy-2")}, state.ActivityInfos)
+	require.Equal(t, map[string]*commonpb.DataBlob{"legacy": blob("legacy-timer")}, state.TimerInfos)
+	require.Equal(t, []string{legacySignal}, state.SignalRequestedIDs)
+
+	// updates go to the map tables, deletes apply to both
+	require.NoError(t, tableStore.UpdateWorkflowExecution(ctx, &persistence.InternalUpdateWorkflowExecutionRequest{
+		ShardID: shardID,
+		RangeID: 1,
+		Mode:    persistence.UpdateWorkflowModeUpdateCurrent,
+		UpdateWorkflowMutation: persistence.InternalWorkflowMutation{
+			NamespaceID:              namespaceID,
+			WorkflowID:               workflowID,
+			RunID:                    runID,
+			ExecutionInfo:            executionInfo,
+			ExecutionInfoBlob:        executionInfoBlob,
+			ExecutionState:           executionState,
+			ExecutionStateBlob:       executionStateBlob,
+			NextEventID:              3,
+			DBRecordVersion:          2,
+			UpsertActivityInfos:      map[int64]*commonpb.DataBlob{2: blob("table-2"), 3: blob("table-3")},
+			DeleteActivityInfos:      map[int64]struct{}{1: {}},
+			UpsertTimerInfos:         map[string]*commonpb.DataBlob{"table": blob("table-timer")},
+			DeleteTimerInfos:         map[string]struct{}{"legacy": {}},
+			UpsertSignalRequestedIDs: map[string]struct{}{newSignal: {}},
+			DeleteSignalRequestedIDs: map[string]struct{}{legacySignal: {}},
+			NewBufferedEvents:        blob("buffered"),
+			Tasks:                    map[tasks.Category][]persistence.InternalHistoryTask{},
+			Condition:                2,
+			Checksum:                 &commonpb.DataBlob{EncodingType: enumspb.ENCODING_TYPE_PROTO3},
+		},
+	}))
+
+	state = get()
+	require.Equal(t, map[int64]*commonpb.DataBlob{2: blob("table-2"), 3: blob("table-3")}, state.ActivityInfos)
+	require.Equal(t, map[string]*commonpb.DataBlob{"table": blob("table-timer")}, state.TimerInfos)
+	require.Equal(t, []string{newSignal}, state.SignalRequestedIDs)
+	require.Equal(t, []*commonpb.DataBlob{blob("buffered")}, state.BufferedEvents)
+
+	require.NoError(t, tableStore.DeleteWorkflowExecution(ctx, &persistence.DeleteWorkflowExecutionRequest{
+		ShardID:     shardID,
+		NamespaceID: namespaceID,
+		WorkflowID:  workflowID,
+		RunID:       runID,
+	}))
+	var count int
+	require.NoError(t, session.Query(`SELECT COUNT(*) FROM execution_activities WHERE shard_id = ? AND namespace_id = ? AND workflow_id = ? AND run_id = ?`,
+		shardID, namespaceID, workflowID, runID).Scan(&count))
+	require.Zero(t, count)
+}
+
+func TestYugabyteExecutionMapTablesReset(t *testing.T) {
+	cluster := NewTestClusterForYugabyte(&persistencetests.TestBaseOptions{}, log.NewNoopLogger())
+	cluster.SetupTestDatabase()
+	t.Cleanup(cluster.TearDownTestDatabase)
+
+	ctx := context.Background()
+	session := cluster.GetSession()
+	tables := driver.NewFactoryFromSession(localconfig.Yugabyte{ExecutionMapTables: true}, testYugabyteClusterName, log.NewNoopLogger(), session)
+	shardStore, err := tables.NewShardStore()
+	require.NoError(t, err)
+	tableStore, err := tables.NewExecutionStore()
+	require.NoError(t, err)
+
+	const shardID = 1
+	serializer := serialization.NewSerializer()
+	_, err = shardStore.GetOrCreateShard(ctx, &persistence.InternalGetOrCreateShardRequest{
+		ShardID: shardID,
+		CreateShardInfo: func() (int64, *commonpb.DataBlob, error) {
+			blob, err := serializer.ShardInfoToBlob(&persistencespb.ShardInfo{ShardId: shardID, RangeId: 1}, enumspb.ENCODING_TYPE_PROTO3)
+			return 1, blob, err
+		},
+	})
+	require.NoError(t, err)
+
+	blob := func(data string) *commonpb.DataBlob {
+		return persistence.NewDataBlob([]byte(data), enumspb.ENCODING_TYPE_PROTO3.String())
+	}
+	namespaceID := uuid.NewString()
+	workflowID := "execution-map-tables-reset"
+	runID := uuid.NewString()
+	oldSignal := uuid.NewString()
+	newSignal := uuid.NewString()
+
+	executionInfo := &persistencespb.WorkflowExecutionInfo{NamespaceId: namespaceID, WorkflowId: workflowID}
+	executionInfoBlob, err := serializer.WorkflowExecutionInfoToBlob(executionInfo, enumspb.ENCODING_TYPE_PROTO3)
+	require.NoError(t, err)
+	executionState := &persistencespb.WorkflowExecutionState{
+		CreateRequestId: uuid.NewString(),
+		RunId:           runID,
+		State:           enumsspb.WORKFLOW_EXECUTION_STATE_RUNNING,
+		Status:          enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING,
+	}
+	executionStateBlob, err := serializer.WorkflowExecutionStateToBlob(executionState, enumspb.ENCODING_TYPE_PROTO3)
+	require.NoError(t, err)
+
+	snapshot := func(
+		nextEventID int64,
+		dbRecordVersion int64,
+		activityInfos map[int64]*commonpb.DataBlob,
+		timerInfos map[string]*commonpb.DataBlob,
+		signalRequestedIDs map[string]struct{},
+	) persistence.InternalWorkflowSnapshot {
+		return persistence.InternalWorkflowSnapshot{
+			NamespaceID:         namespaceID,
+			WorkflowID:          workflowID,
+			RunID:               runID,
+			ExecutionInfo:       executionInfo,
+			ExecutionInfoBlob:   executionInfoBlob,
+			ExecutionState:      executionState,
+			ExecutionStateBlob:  executionStateBlob,
+			NextEventID:         nextEventID,
+			DBRecordVersion:     dbRecordVersion,
+			ActivityInfos:       activityInfos,
+			TimerInfos:          timerInfos,
+			ChildExecutionInfos: map[int64]*commonpb.DataBlob{},
+			RequestCancelInfos:  map[int64]*commonpb.DataBlob{},
+			SignalInfos:         map[int64]*commonpb.DataBlob{},
+			SignalRequestedIDs:  signalRequestedIDs,
+			Tasks:               map[tasks.Category][]persistence.InternalHistoryTask{},
+			Condition:           nextEventID - 1,
+			Checksum:            &commonpb.DataBlob{EncodingType: enumspb.ENCODING_TYPE_PROTO3},
+		}
+	}
+
+	_, err = tableStore.CreateWorkflowExecution(ctx, &persistence.InternalCreateWorkflowExecutionRequest{
+		ShardID: shardID,
+		RangeID: 1,
+		Mode:    persistence.CreateWorkflowModeBrandNew,
+		NewWorkflowSnapshot: snapshot(2, 1,
+			map[int64]*commonpb.DataBlob{1: blob("old-1"), 2: blob("old-2")},
+			map[string]*commonpb.DataBlob{"old": blob("old-timer"), "kept": blob("old-kept")},
+			map[string]struct{}{oldSignal: {}},
+		),
+	})
+	require.NoError(t, err)
+	require.NoError(t, tableStore.UpdateWorkflowExecution(ctx, &persistence.InternalUpdateWorkflowExecutionRequest{
+		ShardID: shardID,
+		RangeID: 1,
+		Mode:    persistence.UpdateWorkflowModeUpdateCurrent,
+		UpdateWorkflowMutation: persistence.InternalWorkflowMutation{
+			NamespaceID:        namespaceID,
+			WorkflowID:         workflowID,
+			RunID:              runID,
+			ExecutionInfo:      executionInfo,
+			ExecutionInfoBlob:  executionInfoBlob,
+			ExecutionState:     executionState,
+			ExecutionStateBlob: executionStateBlob,
+			NextEventID:        3,
+			DBRecordVersion:    2,
+			NewBufferedEvents:  blob("buffered"),
+			Tasks:              map[tasks.Category][]persistence.InternalHistoryTask{},
+			Condition:          2,
+			Checksum:           &commonpb.DataBlob{EncodingType: enumspb.ENCODING_TYPE_PROTO3},
+		},
+	}))
+
+	get := func() *persistence.InternalWorkflowMutableState {
+		response, err := tableStore.GetWorkflowExecution(ctx, &persistence.GetWorkflowExecutionRequest{
+			ShardID:     shardID,
+			NamespaceID: namespaceID,
+			WorkflowID:  workflowID,
+			RunID:       runID,
+		})
+		require.NoError(t, err)
+		return response.State
+	}
+
+	state := get()
+	require.Len(t, state.ActivityInfos, 2)
+	require.Len(t, state.TimerInfos, 2)
+	require.Equal(t, []*commonpb.DataBlob{blob("buffered")}, state.BufferedEvents)
+
+	// the partition deletes of the reset precede its inserts in the same transaction, so entries sharing
+	// a key with the old state survive with their new value and everything else is gone
+	require.NoError(t, tableStore.SetWorkflowExecution(ctx, &persistence.InternalSetWorkflowExecutionRequest{
+		ShardID: shardID,
+		RangeID: 1,
+		SetWorkflowSnapshot: snapshot(4, 3,
+			map[int64]*commonpb.DataBlob{2: blob("new-2"), 3: blob("new-3")},
+			map[string]*commonpb.DataBlob{"kept": blob("new-kept")},
+			map[string]struct{}{newSignal: {}},
+		),
+	}))
+
+	state = get()
+	require.Equal(t, map[int64]*commonpb.DataBlob{2: blob("new-2"), 3: blob("new-3")}, state.ActivityInfos)
+	require.Equal(t, map[string]*commonpb.DataBlob{"kept": blob("new-kept")}, state.TimerInfos)
+	require.Equal(t, []string{newSignal}, state.SignalRequestedIDs)
+	require.Empty(t, state.BufferedEvents)
+
+	// a reset to empty maps leaves no rows behind
+	require.NoError(t, tableStore.SetWorkflowExecution(ctx, &persistence.InternalSetWorkflowExecutionRequest{
+		ShardID: shardID,
+		RangeID: 1,
+		SetWorkflowSnapshot: snapshot(5, 4,
+			map[int64]*commonpb.DataBlob{},
+			map[string]*commonpb.DataBlob{},
+			map[string]struct{}{},
+		),
+	}))
+
+	state = get()
+	require.Empty(t, state.ActivityInfos)
+	require.Empty(t, state.TimerInfos)
+	require.Empty(t, state.SignalRequestedIDs)
+	var count int
+	require.NoError(t, session.Query(`SELECT COUNT(*) FROM execution_activities WHERE shard_id = ? AND namespace_id = ? AND workflow_id = ? AND run_id = ?`,
+		shardID, namespaceID, workflowID, runID).Scan(&count))
+	require.Zero(t, count)
+}
+
 func TestYugabyteExecutionMutableStateTaskStoreSuite(t *testing.T) {
 	testData, tearDown := setUpYugabyteTest(t)
 	defer tearDown()
@@ -211,6 +537,245 @@ func TestYugabyteExecutionMutableStateTaskStoreSuite(t *testing.T) {
 	suite.Run(t, s)
 }
 
+func TestYugabyteExecutionMutableStateTaskStoreSuiteWithTimerBuckets(t *testing.T) {
+	testData, tearDown := setUpYugabyteTestWithOptions(t, map[string]any{"timerBucketWidth": "1m"})
+	defer tearDown()
+
+	shardStore, err := testData.Factory.NewShardStore()
+	if err != nil {
+		t.Fatalf("unable to create Yugabyte DB: %v", err)
+	}
+	executionStore, err := testData.Factory.NewExecutionStore()
+	if err != nil {
+		t.Fatalf("unable to create Yugabyte DB: %v", err)
+	}
+
+	s := commontests.NewExecutionMutableStateTaskSuite(
+		t,
+		shardStore,
+		executionStore,
+		serialization.NewSerializer(),
+		testData.Logger,
+	)
+	suite.Run(t, s)
+}
+
+func TestYugabyteTimerBuckets(t *testing.T) {
+	cluster := NewTestClusterForYugabyte(&persistencetests.TestBaseOptions{}, log.NewNoopLogger())
+	cluster.SetupTestDatabase()
+	t.Cleanup(cluster.TearDownTestDatabase)
+
+	ctx := context.Background()
+	session := cluster.GetSession()
+	factory := driver.NewFactoryFromSession(localconfig.Yugabyte{TimerBucketWidth: time.Minute}, testYugabyteClusterName, log.NewNoopLogger(), session)
+	shardStore, err := factory.NewShardStore()
+	require.NoError(t, err)
+	executionStore, err := factory.NewExecutionStore()
+	require.NoError(t, err)
+
+	const shardID = 1
+	_, err = shardStore.GetOrCreateShard(ctx, &persistence.InternalGetOrCreateShardRequest{
+		ShardID: shardID,
+		CreateShardInfo: func() (int64, *commonpb.DataBlob, error) {
+			blob, err := serialization.NewSerializer().ShardInfoToBlob(&persistencespb.ShardInfo{ShardId: shardID, RangeId: 1}, enumspb.ENCODING_TYPE_PROTO3)
+			return 1, blob, err
+		},
+	})
+	require.NoError(t, err)
+
+	base := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	encoding := enumspb.ENCODING_TYPE_PROTO3.String()
+
+	// timers written before bucketing was enabled live in the timers table
+	for i, offset := range []time.Duration{30 * time.Second, 90 * time.Second} {
+		require.NoError(t, session.Query(`INSERT INTO timers (shard_id, type, data, encoding, visibility_ts, task_id) VALUES (?, 0, ?, ?, ?, ?)`,
+			shardID, []byte("legacy"), encoding, base.Add(offset), int64(100+i)).Exec())
+	}
+
+	var timers []persistence.InternalHistoryTask
+	for i, offset := range []time.Duration{0, time.Minute, 2 * time.Minute, 3*time.Minute + time.Second} {
+		timers = append(timers, persistence.InternalHistoryTask{
+			Key:  tasks.NewKey(base.Add(offset), int64(200+i)),
+			Blob: persistence.NewDataBlob([]byte("bucketed"), encoding),
+		})
+	}
+	require.NoError(t, executionStore.AddHistoryTasks(ctx, &persistence.InternalAddHistoryTasksRequest{
+		ShardID:     shardID,
+		RangeID:     1,
+		NamespaceID: uuid.NewString(),
+		WorkflowID:  "timer-buckets",
+		Tasks:       map[tasks.Category][]persistence.InternalHistoryTask{tasks.CategoryTimer: timers},
+	}))
+
+	readAll := func() []int64 {
+		var taskIDs []int64
+		request := &persistence.GetHistoryTasksRequest{
+			ShardID:             shardID,
+			TaskCategory:        tasks.CategoryTimer,
+			InclusiveMinTaskKey: tasks.NewKey(base, 0),
+			ExclusiveMaxTaskKey: tasks.NewKey(base.Add(10*time.Minute), 0),
+			BatchSize:           2,
+		}
+		for {
+			response, err := executionStore.GetHistoryTasks(ctx, request)
+			require.NoError(t, err)
+			require.LessOrEqual(t, len(response.Tasks), request.BatchSize)
+			for _, task := range response.Tasks {
+				taskIDs = append(taskIDs, task.Key.TaskID)
+			}
+			if len(response.NextPageToken) == 0 {
+				return taskIDs
+			}
+			request.NextPageToken = response.NextPageToken
+		}
+	}
+	countBuckets := func() int {
+		var count int
+		require.NoError(t, session.Query(`SELECT COUNT(*) FROM timer_buckets WHERE shard_id = ? AND type = 0`, shardID).Scan(&count))
+		return count
+	}
+
+	// pages interleave the two tables in fire time order
+	require.Equal(t, []int64{200, 100, 201, 101, 202, 203}, readAll())
+	require.Equal(t, 4, countBuckets())
+
+	// buckets the queue has moved past are deleted along with their entry, the bucket in progress is range deleted
+	require.NoError(t, executionStore.RangeCompleteHistoryTasks(ctx, &persistence.RangeCompleteHistoryTasksRequest{
+		ShardID:             shardID,
+		TaskCategory:        tasks.CategoryTimer,
+		InclusiveMinTaskKey: tasks.NewKey(base, 0),
+		ExclusiveMaxTaskKey: tasks.NewKey(base.Add(150*time.Second), 0),
+	}))
+	require.Equal(t, []int64{203}, readAll())
+	require.Equal(t, 2, countBuckets())
+
+	require.NoError(t, executionStore.CompleteHistoryTask(ctx, &persistence.CompleteHistoryTaskRequest{
+		ShardID:      shardID,
+		TaskCategory: tasks.CategoryTimer,
+		TaskKey:      timers[3].Key,
+	}))
+	require.Empty(t, readAll())
+}
+
+func TestYugabyteExecutionMutableStateTaskStoreSuiteWithSystemTaskBuckets(t *testing.T) {
+	testData, tearDown := setUpYugabyteTestWithOptions(t, map[string]any{"systemTaskBucketSize": 1024})
+	defer tearDown()
+
+	shardStore, err := testData.Factory.NewShardStore()
+	if err != nil {
+		t.Fatalf("unable to create Yugabyte DB: %v", err)
+	}
+	executionStore, err := testData.Factory.NewExecutionStore()
+	if err != nil {
+		t.Fatalf("unable to create Yugabyte DB: %v", err)
+	}
+
+	s := commontests.NewExecutionMutableStateTaskSuite(
+		t,
+		shardStore,
+		executionStore,
+		serialization.NewSerializer(),
+		testData.Logger,
+	)
+	suite.Run(t, s)
+}
+
+func TestYugabyteSystemTaskBuckets(t *testing.T) {
+	cluster := NewTestClusterForYugabyte(&persistencetests.TestBaseOptions{}, log.NewNoopLogger())
+	cluster.SetupTestDatabase()
+	t.Cleanup(cluster.TearDownTestDatabase)
+
+	ctx := context.Background()
+	session := cluster.GetSession()
+	factory := driver.NewFactoryFromSession(localconfig.Yugabyte{SystemTaskBucketSize: 10}, testYugabyteClusterName, log.NewNoopLogger(), session)
+	shardStore, err := factory.NewShardStore()
+	require.NoError(t, err)
+	executionStore, err := factory.NewExecutionStore()
+	require.NoError(t, err)
+
+	const shardID = 1
+	_, err = shardStore.GetOrCreateShard(ctx, &persistence.InternalGetOrCreateShardRequest{
+		ShardID: shardID,
+		CreateShardInfo: func() (int64, *commonpb.DataBlob, error) {
+			blob, err := serialization.NewSerializer().ShardInfoToBlob(&persistencespb.ShardInfo{ShardId: shardID, RangeId: 1}, enumspb.ENCODING_TYPE_PROTO3)
+			return 1, blob, err
+		},
+	})
+	require.NoError(t, err)
+
+	encoding := enumspb.ENCODING_TYPE_PROTO3.String()
+	// the transfer queue ID of system_tasks, written before bucketing was enabled
+	const transferQueue = "70454977-3BF9-4298-AC1A-93DD68718ACB"
+	for _, taskID := range []int64{5, 15} {
+		require.NoError(t, session.Query(`INSERT INTO system_tasks (shard_id, id, data, encoding, task_id) VALUES (?, ?, ?, ?, ?)`,
+			shardID, transferQueue, []byte("legacy"), encoding, taskID).Exec())
+	}
+
+	var transfers []persistence.InternalHistoryTask
+	for _, taskID := range []int64{1, 12, 23, 47} {
+		transfers = append(transfers, persistence.InternalHistoryTask{
+			Key:  tasks.NewImmediateKey(taskID),
+			Blob: persistence.NewDataBlob([]byte("bucketed"), encoding),
+		})
+	}
+	require.NoError(t, executionStore.AddHistoryTasks(ctx, &persistence.InternalAddHistoryTasksRequest{
+		ShardID:     shardID,
+		RangeID:     1,
+		NamespaceID: uuid.NewString(),
+		WorkflowID:  "system-task-buckets",
+		Tasks:       map[tasks.Category][]persistence.InternalHistoryTask{tasks.CategoryTransfer: transfers},
+	}))
+
+	readAll := func() []int64 {
+		var taskIDs []int64
+		request := &persistence.GetHistoryTasksRequest{
+			ShardID:             shardID,
+			TaskCategory:        tasks.CategoryTransfer,
+			InclusiveMinTaskKey: tasks.NewImmediateKey(0),
+			ExclusiveMaxTaskKey: tasks.NewImmediateKey(100),
+			BatchSize:           2,
+		}
+		for {
+			response, err := executionStore.GetHistoryTasks(ctx, request)
+			require.NoError(t, err)
+			require.LessOrEqual(t, len(response.Tasks), request.BatchSize)
+			for _, task := range response.Tasks {
+				taskIDs = append(taskIDs, task.Key.TaskID)
+			}
+			if len(response.NextPageToken) == 0 {
+				return taskIDs
+			}
+			request.NextPageToken = response.NextPageToken
+		}
+	}
+	countBuckets := func() int {
+		var count int
+		require.NoError(t, session.Query(`SELECT COUNT(*) FROM system_task_buckets WHERE shard_id = ? AND id = ?`, shardID, transferQueue).Scan(&count))
+		return count
+	}
+
+	// pages interleave the two tables in task ID order
+	require.Equal(t, []int64{1, 5, 12, 15, 23, 47}, readAll())
+	require.Equal(t, 4, countBuckets())
+
+	// buckets the queue has moved past are deleted along with their entry, the bucket in progress is range deleted
+	require.NoError(t, executionStore.RangeCompleteHistoryTasks(ctx, &persistence.RangeCompleteHistoryTasksRequest{
+		ShardID:             shardID,
+		TaskCategory:        tasks.CategoryTransfer,
+		InclusiveMinTaskKey: tasks.NewImmediateKey(0),
+		ExclusiveMaxTaskKey: tasks.NewImmediateKey(25),
+	}))
+	require.Equal(t, []int64{47}, readAll())
+	require.Equal(t, 2, countBuckets())
+
+	require.NoError(t, executionStore.CompleteHistoryTask(ctx, &persistence.CompleteHistoryTaskRequest{
+		ShardID:      shardID,
+		TaskCategory: tasks.CategoryTransfer,
+		TaskKey:      transfers[3].Key,
+	}))
+	require.Empty(t, readAll())
+}
+
 // TODO: Merge persistence-core into the core directory.
 
 func TestYugabyteHistoryStoreSuite(t *testing.T) {
@@ -252,18 +817,342 @@ func TestYugabyteTaskQueueTaskSuite(t *testing.T) {
 	suite.Run(t, s)
 }
 
-//func TestYugabyteTaskQueueUserDataSuite(t *testing.T) {
-//	testData, tearDown := setUpYugabyteTest(t)
-//	defer tearDown()
-//
-//	taskQueueStore, err := testData.Factory.NewTaskStore()
-//	if err != nil {
-//		t.Fatalf("unable to create Yugabyte DB: %v", err)
-//	}
-//
-//	s := commontests.NewTaskQueueUserDataSuite(t, taskQueueStore, testData.Logger)
-//	suite.Run(t, s)
-//}
+func TestYugabyteShardOwnership(t *testing.T) {
+	testData, tearDown := setUpYugabyteTest(t)
+	defer tearDown()
+
+	ctx := context.Background()
+	serializer := serialization.NewSerializer()
+	shardInfo := func(rangeID int64) *commonpb.DataBlob {
+		blob, err := serializer.ShardInfoToBlob(&persistencespb.ShardInfo{ShardId: 1, RangeId: rangeID}, enumspb.ENCODING_TYPE_PROTO3)
+		require.NoError(t, err)
+		return blob
+	}
+
+	// each owner acquires the shard through a store of its own, as separate history hosts would
+	staleOwner, err := testData.Factory.NewShardStore()
+	require.NoError(t, err)
+	newOwner, err := testData.Factory.NewShardStore()
+	require.NoError(t, err)
+
+	var lost *persistence.ShardOwnershipLostError
+	err = staleOwner.AssertShardOwnership(ctx, &persistence.AssertShardOwnershipRequest{ShardID: 1, RangeID: 1})
+	require.ErrorAs(t, err, &lost, "a shard which does not exist is not owned")
+
+	_, err = staleOwner.GetOrCreateShard(ctx, &persistence.InternalGetOrCreateShardRequest{
+		ShardID: 1,
+		CreateShardInfo: func() (int64, *commonpb.DataBlob, error) {
+			return 1, shardInfo(1), nil
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, staleOwner.AssertShardOwnership(ctx, &persistence.AssertShardOwnershipRequest{ShardID: 1, RangeID: 1}))
+
+	// the new owner steals the shard by advancing the range
+	require.NoError(t, newOwner.UpdateShard(ctx, &persistence.InternalUpdateShardRequest{
+		ShardID:         1,
+		RangeID:         2,
+		ShardInfo:       shardInfo(2),
+		PreviousRangeID: 1,
+	}))
+
+	err = staleOwner.AssertShardOwnership(ctx, &persistence.AssertShardOwnershipRequest{ShardID: 1, RangeID: 1})
+	require.ErrorAs(t, err, &lost)
+	require.Equal(t, int32(1), lost.ShardID)
+
+	require.NoError(t, newOwner.AssertShardOwnership(ctx, &persistence.AssertShardOwnershipRequest{ShardID: 1, RangeID: 2}))
+
+	// asserting ownership leaves the shard untouched
+	response, err := newOwner.GetOrCreateShard(ctx, &persistence.InternalGetOrCreateShardRequest{ShardID: 1})
+	require.NoError(t, err)
+	require.Equal(t, shardInfo(2).Data, response.ShardInfo.Data)
+}
+
+func TestYugabyteListTaskQueue(t *testing.T) {
+	testData, tearDown := setUpYugabyteTest(t)
+	defer tearDown()
+
+	ctx := context.Background()
+	serializer := serialization.NewSerializer()
+	store, err := testData.Factory.NewTaskStore()
+	require.NoError(t, err)
+
+	namespaceID := uuid.NewString()
+	expected := make(map[string]int64)
+	for i := 0; i < 5; i++ {
+		name := "list-task-queue-" + strconv.Itoa(i)
+		info := &persistencespb.TaskQueueInfo{
+			NamespaceId: namespaceID,
+			Name:        name,
+			TaskType:    enumspb.TASK_QUEUE_TYPE_ACTIVITY,
+			Kind:        enumspb.TASK_QUEUE_KIND_NORMAL,
+		}
+		blob, err := serializer.TaskQueueInfoToBlob(info, enumspb.ENCODING_TYPE_PROTO3)
+		require.NoError(t, err)
+		rangeID := int64(i + 1)
+		require.NoError(t, store.CreateTaskQueue(ctx, &persistence.InternalCreateTaskQueueRequest{
+			NamespaceID:   namespaceID,
+			TaskQueue:     name,
+			TaskType:      enumspb.TASK_QUEUE_TYPE_ACTIVITY,
+			RangeID:       rangeID,
+			TaskQueueInfo: blob,
+		}))
+		// task rows share the partition and must not be listed
+		_, err = store.CreateTasks(ctx, &persistence.InternalCreateTasksRequest{
+			NamespaceID:   namespaceID,
+			TaskQueue:     name,
+			TaskType:      enumspb.TASK_QUEUE_TYPE_ACTIVITY,
+			RangeID:       rangeID,
+			TaskQueueInfo: blob,
+			Tasks: []*persistence.InternalCreateTask{{
+				TaskId: 1,
+				Task:   persistence.NewDataBlob([]byte("task"), enumspb.ENCODING_TYPE_PROTO3.String()),
+			}},
+		})
+		require.NoError(t, err)
+		expected[name] = rangeID
+	}
+
+	list := func() map[string]int64 {
+		found := make(map[string]int64)
+		var token []byte
+		for {
+			response, err := store.ListTaskQueue(ctx, &persistence.ListTaskQueueRequest{PageSize: 2, PageToken: token})
+			require.NoError(t, err)
+			for _, item := range response.Items {
+				info, err := serializer.TaskQueueInfoFromBlob(item.TaskQueue)
+				require.NoError(t, err)
+				if info.GetNamespaceId() == namespaceID {
+					found[info.GetName()] = item.RangeID
+				}
+			}
+			if len(response.NextPageToken) == 0 {
+				return found
+			}
+			token = response.NextPageToken
+		}
+	}
+	require.Equal(t, expected, list())
+
+	require.NoError(t, store.DeleteTaskQueue(ctx, &persistence.DeleteTaskQueueRequest{
+		TaskQueue: &persistence.TaskQueueKey{
+			NamespaceID:   namespaceID,
+			TaskQueueName: "list-task-queue-0",
+			TaskQueueType: enumspb.TASK_QUEUE_TYPE_ACTIVITY,
+		},
+		RangeID: 1,
+	}))
+	delete(expected, "list-task-queue-0")
+	require.Equal(t, expected, list())
+}
+
+func TestYugabyteTaskQueueUserDataBuckets(t *testing.T) {
+	cluster := NewTestClusterForYugabyte(&persistencetests.TestBaseOptions{}, log.NewNoopLogger())
+	cluster.SetupTestDatabase()
+	t.Cleanup(cluster.TearDownTestDatabase)
+
+	ctx := context.Background()
+	session := cluster.GetSession()
+	store := driver.NewMatchingTaskStore(session, log.NewNoopLogger())
+	namespaceID := uuid.NewString()
+	blob := func(data string) *commonpb.DataBlob {
+		return persistence.NewDataBlob([]byte(data), enumspb.ENCODING_TYPE_PROTO3.String())
+	}
+
+	// rows written before schema version 1.2 live in the single namespace partition
+	require.NoError(t, session.Query(`INSERT INTO task_queue_user_data (namespace_id, build_id, task_queue_name, data, data_encoding, version) VALUES (?, '', ?, ?, ?, ?)`,
+		namespaceID, "legacy", []byte("legacy"), enumspb.ENCODING_TYPE_PROTO3.String(), 3).Exec())
+	require.NoError(t, session.Query(`INSERT INTO task_queue_user_data (namespace_id, build_id, task_queue_name) VALUES (?, ?, ?)`,
+		namespaceID, "build-1", "legacy").Exec())
+
+	response, err := store.GetTaskQueueUserData(ctx, &persistence.GetTaskQueueUserDataRequest{NamespaceID: namespaceID, TaskQueue: "legacy"})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), response.Version)
+	require.Equal(t, []byte("legacy"), response.UserData.Data)
+
+	countBuild1 := func() int {
+		count, err := store.CountTaskQueuesByBuildId(ctx, &persistence.CountTaskQueuesByBuildIdRequest{NamespaceID: namespaceID, BuildID: "build-1"})
+		require.NoError(t, err)
+		return count
+	}
+	require.Equal(t, 1, countBuild1())
+
+	// a stale update leaves the legacy row in place
+	var conflicting bool
+	err = store.UpdateTaskQueueUserData(ctx, &persistence.InternalUpdateTaskQueueUserDataRequest{
+		NamespaceID: namespaceID,
+		Updates: map[string]*persistence.InternalSingleTaskQueueUserDataUpdate{
+			"legacy": {Version: 2, UserData: blob("stale"), Conflicting: &conflicting},
+		},
+	})
+	var conditionFailed *persistence.ConditionFailedError
+	require.ErrorAs(t, err, &conditionFailed)
+	require.True(t, conflicting)
+
+	// a current update moves the row and its build ID mapping into the task queue's bucket
+	err = store.UpdateTaskQueueUserData(ctx, &persistence.InternalUpdateTaskQueueUserDataRequest{
+		NamespaceID: namespaceID,
+		Updates: map[string]*persistence.InternalSingleTaskQueueUserDataUpdate{
+			"legacy": {Version: 3, UserData: blob("moved"), BuildIdsAdded: []string{"build-1"}},
+		},
+	})
+	require.NoError(t, err)
+
+	response, err = store.GetTaskQueueUserData(ctx, &persistence.GetTaskQueueUserDataRequest{NamespaceID: namespaceID, TaskQueue: "legacy"})
+	require.NoError(t, err)
+	require.Equal(t, int64(4), response.Version)
+	require.Equal(t, []byte("moved"), response.UserData.Data)
+	require.Equal(t, 1, countBuild1())
+
+	var legacyRows int
+	require.NoError(t, session.Query(`SELECT COUNT(*) FROM task_queue_user_data WHERE namespace_id = ?`, namespaceID).Scan(&legacyRows))
+	require.Zero(t, legacyRows)
+
+	// listing pages through every bucket
+	expected := map[string]int64{"legacy": 4}
+	for i := 0; i < 10; i++ {
+		taskQueue := "task-queue-" + strconv.Itoa(i)
+		require.NoError(t, store.UpdateTaskQueueUserData(ctx, &persistence.InternalUpdateTaskQueueUserDataRequest{
+			NamespaceID: namespaceID,
+			Updates: map[string]*persistence.InternalSingleTaskQueueUserDataUpdate{
+				taskQueue: {UserData: blob(taskQueue), BuildIdsAdded: []string{"build-1"}},
+			},
+		}))
+		expected[taskQueue] = 1
+	}
+	require.Equal(t, 11, countBuild1())
+
+	taskQueues, err := store.GetTaskQueuesByBuildId(ctx, &persistence.GetTaskQueuesByBuildIdRequest{NamespaceID: namespaceID, BuildID: "build-1"})
+	require.NoError(t, err)
+	require.Len(t, taskQueues, 11)
+
+	found := make(map[string]int64)
+	var token []byte
+	for {
+		page, err := store.ListTaskQueueUserDataEntries(ctx, &persistence.ListTaskQueueUserDataEntriesRequest{
+			NamespaceID:   namespaceID,
+			PageSize:      3,
+			NextPageToken: token,
+		})
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(page.Entries), 3)
+		for _, entry := range page.Entries {
+			found[entry.TaskQueue] = entry.Version
+		}
+		if len(page.NextPageToken) == 0 {
+			break
+		}
+		token = page.NextPageToken
+	}
+	require.Equal(t, expected, found)
+
+	// page tokens issued before the buckets were introduced are the raw page state of the legacy partition
+	legacyNamespaceID := uuid.NewString()
+	for _, taskQueue := range []string{"legacy-a", "legacy-b"} {
+		require.NoError(t, session.Query(`INSERT INTO task_queue_user_data (namespace_id, build_id, task_queue_name, data, data_encoding, version) VALUES (?, '', ?, ?, ?, ?)`,
+			legacyNamespaceID, taskQueue, []byte(taskQueue), enumspb.ENCODING_TYPE_PROTO3.String(), 1).Exec())
+	}
+	iter := session.Query(`SELECT task_queue_name, data, data_encoding, version FROM task_queue_user_data WHERE namespace_id = ? AND build_id = ''`,
+		legacyNamespaceID).PageSize(1).Iter()
+	var taskQueue, dataEncoding string
+	var data []byte
+	var version int64
+	require.True(t, iter.Scan(&taskQueue, &data, &dataEncoding, &version))
+	require.Equal(t, "legacy-a", taskQueue)
+	legacyToken := iter.PageState()
+	require.NoError(t, iter.Close())
+	require.NotEmpty(t, legacyToken)
+
+	page, err := store.ListTaskQueueUserDataEntries(ctx, &persistence.ListTaskQueueUserDataEntriesRequest{
+		NamespaceID:   legacyNamespaceID,
+		PageSize:      10,
+		NextPageToken: legacyToken,
+	})
+	require.NoError(t, err)
+	require.Len(t, page.Entries, 1)
+	require.Equal(t, "legacy-b", page.Entries[0].TaskQueue)
+}
+
+func TestYugabyteTaskQueueUserDataMigration(t *testing.T) {
+	cluster := NewTestClusterForYugabyte(&persistencetests.TestBaseOptions{}, log.NewNoopLogger())
+	cluster.SetupTestDatabase()
+	t.Cleanup(cluster.TearDownTestDatabase)
+
+	ctx := context.Background()
+	session := cluster.GetSession()
+	namespaceID := uuid.NewString()
+
+	require.NoError(t, session.Query(`INSERT INTO task_queue_user_data (namespace_id, build_id, task_queue_name, data, data_encoding, version) VALUES (?, '', ?, ?, ?, ?)`,
+		namespaceID, "legacy", []byte("legacy"), enumspb.ENCODING_TYPE_PROTO3.String(), 3).Exec())
+	require.NoError(t, session.Query(`INSERT INTO task_queue_user_data (namespace_id, build_id, task_queue_name) VALUES (?, ?, ?)`,
+		namespaceID, "build-1", "legacy").Exec())
+
+	moved, skipped, err := driver.MigrateTaskQueueUserData(ctx, session, log.NewNoopLogger())
+	require.NoError(t, err)
+	require.Equal(t, 2, moved)
+	require.Zero(t, skipped)
+
+	var legacyRows int
+	require.NoError(t, session.Query(`SELECT COUNT(*) FROM task_queue_user_data WHERE namespace_id = ?`, namespaceID).Scan(&legacyRows))
+	require.Zero(t, legacyRows)
+
+	// once migrated, the store finds the rows without reading the legacy table
+	factory := driver.NewFactoryFromSession(localconfig.Yugabyte{TaskQueueUserDataMigrated: true}, testYugabyteClusterName, log.NewNoopLogger(), session)
+	store, err := factory.NewTaskStore()
+	require.NoError(t, err)
+
+	response, err := store.GetTaskQueueUserData(ctx, &persistence.GetTaskQueueUserDataRequest{NamespaceID: namespaceID, TaskQueue: "legacy"})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), response.Version)
+	require.Equal(t, []byte("legacy"), response.UserData.Data)
+
+	taskQueues, err := store.GetTaskQueuesByBuildId(ctx, &persistence.GetTaskQueuesByBuildIdRequest{NamespaceID: namespaceID, BuildID: "build-1"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"legacy"}, taskQueues)
+
+	// a second run finds nothing left to move
+	moved, skipped, err = driver.MigrateTaskQueueUserData(ctx, session, log.NewNoopLogger())
+	require.NoError(t, err)
+	require.Zero(t, moved)
+	require.Zero(t, skipped)
+}
+
+func TestYugabyteStoreCloseLeavesSessionOpen(t *testing.T) {
+	testData, tearDown := setUpYugabyteTest(t)
+	defer tearDown()
+
+	shardStore, err := testData.Factory.NewShardStore()
+	require.NoError(t, err)
+	executionStore, err := testData.Factory.NewExecutionStore()
+	require.NoError(t, err)
+	otherShardStore, err := testData.Factory.NewShardStore()
+	require.NoError(t, err)
+
+	shardStore.Close()
+	executionStore.Close()
+
+	_, err = otherShardStore.GetOrCreateShard(context.Background(), &persistence.InternalGetOrCreateShardRequest{
+		ShardID: 1,
+		CreateShardInfo: func() (int64, *commonpb.DataBlob, error) {
+			blob, err := serialization.NewSerializer().ShardInfoToBlob(&persistencespb.ShardInfo{ShardId: 1, RangeId: 1}, enumspb.ENCODING_TYPE_PROTO3)
+			return 1, blob, err
+		},
+	})
+	require.NoError(t, err)
+}
+
+func TestYugabyteTaskQueueUserDataSuite(t *testing.T) {
+	testData, tearDown := setUpYugabyteTest(t)
+	defer tearDown()
+
+	taskQueueStore, err := testData.Factory.NewTaskStore()
+	if err != nil {
+		t.Fatalf("unable to create Yugabyte DB: %v", err)
+	}
+
+	s := commontests.NewTaskQueueUserDataSuite(t, taskQueueStore, testData.Logger)
+	suite.Run(t, s)
+}
 
 func TestYugabyteHistoryV2Persistence(t *testing.T) {
 	s := new(persistencetests.HistoryV2PersistenceSuite)
@@ -279,12 +1168,12 @@ func TestYugabyteMetadataPersistenceV2(t *testing.T) {
 	suite.Run(t, s)
 }
 
-//func TestYugabyteClusterMetadataPersistence(t *testing.T) {
-//	s := new(persistencetests.ClusterMetadataManagerSuite)
-//	s.TestBase = NewTestBaseWithYugabyte(&persistencetests.TestBaseOptions{})
-//	s.TestBase.Setup(nil)
-//	suite.Run(t, s)
-//}
+func TestYugabyteClusterMetadataPersistence(t *testing.T) {
+	s := new(persistencetests.ClusterMetadataManagerSuite)
+	s.TestBase = NewTestBaseWithYugabyte(&persistencetests.TestBaseOptions{})
+	s.TestBase.Setup(nil)
+	suite.Run(t, s)
+}
 
 func TestYugabyteQueuePersistence(t *testing.T) {
 	s := new(persistencetests.QueuePersistenceSuite)
@@ -316,21 +1205,31 @@ func TestYugabyteQueueV2Persistence(t *testing.T) {
 	})
 }
 
-//func TestYugabyteNexusEndpointPersistence(t *testing.T) {
-//	cluster := NewTestClusterForYugabyte(&persistencetests.TestBaseOptions{}, log.NewNoopLogger())
-//	cluster.SetupTestDatabase()
-//	t.Cleanup(cluster.TearDownTestDatabase)
-//
-//	tableVersion := atomic.Int64{}
-//
-//	// NB: These core cannot be run in parallel because of concurrent updates to the table version by different core
-//	t.Run("Generic", func(t *testing.T) {
-//		commontests.RunNexusEndpointTestSuite(t, newNexusEndpointStore(cluster.GetSession()), &tableVersion)
-//	})
-//	t.Run("YugabyteSpecific", func(t *testing.T) {
-//		testYugabyteNexusEndpointStore(t, cluster, &tableVersion)
-//	})
-//}
+func TestYugabyteNexusEndpointPersistence(t *testing.T) {
+	cluster := NewTestClusterForYugabyte(&persistencetests.TestBaseOptions{}, log.NewNoopLogger())
+	cluster.SetupTestDatabase()
+	t.Cleanup(cluster.TearDownTestDatabase)
+
+	tableVersion := atomic.Int64{}
+
+	// NB: These core cannot be run in parallel because of concurrent updates to the table version by different core
+	t.Run("Generic", func(t *testing.T) {
+		commontests.RunNexusEndpointTestSuite(t, newNexusEndpointStore(cluster.GetSession()), &tableVersion)
+	})
+	t.Run("YugabyteSpecific", func(t *testing.T) {
+		testYugabyteNexusEndpointStore(t, cluster, &tableVersion)
+	})
+}
+
+func TestYugabyteVisibilityPersistenceSuite(t *testing.T) {
+	logger := log.NewTestLogger()
+	cluster := NewTestClusterForYugabyteVisibility(&persistencetests.TestBaseOptions{}, logger)
+	s := &commontests.VisibilityPersistenceSuite{
+		TestBase:                     persistencetests.NewTestBaseForCluster(cluster, logger),
+		CustomVisibilityStoreFactory: &driver.VisibilityStoreFactory{},
+	}
+	suite.Run(t, s)
+}
 
 func testYugabyteQueueV2DataCorruption(t *testing.T, cluster *TestCluster) {
 	t.Run("ErrInvalidQueueMessageEncodingType", func(t *testing.T) {
@@ -1401,7 +2300,7 @@ func testYugabyteNexusEndpointStoreConcurrentCreateAndUpdate(t *testing.T, store
 					EncodingType: enumspb.ENCODING_TYPE_PROTO3,
 				}},
 		})
-		if createErr != nil {
+		if createErr == nil {
 			tableVersion.Add(1)
 		}
 		wg.Done()
@@ -1413,7 +2312,7 @@ func testYugabyteNexusEndpointStoreConcurrentCreateAndUpdate(t *testing.T, store
 			LastKnownTableVersion: requestTableVersion,
 			Endpoint:              firstEndpoint,
 		})
-		if updateErr != nil {
+		if updateErr == nil {
 			tableVersion.Add(1)
 		}
 		wg.Done()
@@ -1465,7 +2364,7 @@ func testYugabyteNexusEndpointStoreConcurrentUpdateAndDelete(t *testing.T, store
 			LastKnownTableVersion: requestTableVersion,
 			Endpoint:              endpoint,
 		})
-		if updateErr != nil {
+		if updateErr == nil {
 			tableVersion.Add(1)
 		}
 		wg.Done()
@@ -1477,7 +2376,7 @@ func testYugabyteNexusEndpointStoreConcurrentUpdateAndDelete(t *testing.T, store
 			LastKnownTableVersion: requestTableVersion,
 			ID:                    endpoint.ID,
 		})
-		if deleteErr != nil {
+		if deleteErr == nil {
 			tableVersion.Add(1)
 		}
 		wg.Done()
@@ -61,6 +61,11 @@ type (
 )
 
 func setUpYugabyteTest(t *testing.T) (YugabyteTestData, func()) {
+	return setUpYugabyteTestWithOptions(t, nil)
+}
+
+// setUpYugabyteTestWithOptions is setUpYugabyteTest with the given datastore options added to the configuration
+func setUpYugabyteTestWithOptions(t *testing.T, options map[string]any) (YugabyteTestData, func()) {
 	keyspace := testYugabyteDatabaseNamePrefix + shuffle.String(testYugabyteDatabaseNameSuffix)
 	logger := log.NewZapLogger(zaptest.NewLogger(t))
 
@@ -68,6 +73,9 @@ func setUpYugabyteTest(t *testing.T) (YugabyteTestData, func()) {
 	cluster.SetupTestDatabase()
 
 	cfg := cluster.CustomConfig()
+	for key, value := range options {
+		cfg.Options[key] = value
+	}
 
 	ccfg, err := localconfig.ImportConfig(cfg)
 	if err != nil {
@@ -115,6 +123,14 @@ func NewTestClusterForYugabyte(options *persistencetests.TestBaseOptions, logger
 	return testCluster
 }
 
+// NewTestClusterForYugabyteVisibility returns a test cluster with the visibility schema, configured as the
+// visibility store
+func NewTestClusterForYugabyteVisibility(options *persistencetests.TestBaseOptions, logger log.Logger) *TestCluster {
+	testCluster := NewTestClusterForYugabyte(options, logger)
+	testCluster.visibility = true
+	return testCluster
+}
+
 // CreateYugabyteKeyspace creates the keyspace using this session for given replica count
 func CreateYugabyteKeyspace(s localgocql.Session, keyspace string, replicas int, overwrite bool, logger log.Logger) (err error) {
 	// if overwrite flag is set, drop the keyspace and create a new one
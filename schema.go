@@ -4,3 +4,6 @@ import "embed"
 
 //go:embed schema/yugabyte/temporal
 var SchemaFs embed.FS
+
+//go:embed schema/yugabyte/visibility
+var VisibilitySchemaFs embed.FS
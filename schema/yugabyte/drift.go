@@ -0,0 +1,164 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package yugabyte
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+type (
+	// Difference is a way in which the live schema of a keyspace departs from the one expected of its schema version
+	Difference struct {
+		// Object is the table, index or type concerned, e.g. "table executions"
+		Object string
+		// Problem describes the difference, e.g. "is missing"
+		Problem string
+	}
+)
+
+// versionTables are created by the schema tools rather than by the versioned schema
+var versionTables = []string{"schema_version", "schema_update_history"}
+
+func (d Difference) String() string {
+	return d.Object + " " + d.Problem
+}
+
+// Compare returns the differences between the expected and live schemas of a keyspace, ordered by object.  The live
+// schema may lack details which a cluster does not report, such as the covering columns of an index, in which case
+// those details are not compared.
+func Compare(expected *Schema, live *Schema) []Difference {
+	var result []Difference
+	add := func(object string, format string, args ...any) {
+		result = append(result, Difference{Object: object, Problem: fmt.Sprintf(format, args...)})
+	}
+
+	for name, e := range expected.Tables {
+		object := "table " + name
+		l, ok := live.Tables[name]
+		if !ok {
+			add(object, "is missing")
+			continue
+		}
+		compareColumns(object, e, l, add)
+		if !slices.Equal(e.PartitionKey, l.PartitionKey) {
+			add(object, "has partition key (%s), expected (%s)", strings.Join(l.PartitionKey, ", "), strings.Join(e.PartitionKey, ", "))
+		}
+		if !slices.Equal(e.ClusteringKey, l.ClusteringKey) {
+			add(object, "has clustering key (%s), expected (%s)", strings.Join(l.ClusteringKey, ", "), strings.Join(e.ClusteringKey, ", "))
+		}
+		if e.Transactional && !l.Transactional {
+			add(object, "does not have transactions enabled")
+		}
+	}
+	for name := range live.Tables {
+		if _, ok := expected.Tables[name]; !ok && !slices.Contains(versionTables, name) {
+			add("table "+name, "is not part of the schema")
+		}
+	}
+
+	for name, e := range expected.Indexes {
+		object := "index " + name
+		l, ok := live.Indexes[name]
+		if !ok {
+			add(object, "is missing")
+			continue
+		}
+		if l.Table != e.Table {
+			add(object, "is on table %s, expected %s", l.Table, e.Table)
+			continue
+		}
+		if len(l.Columns) > 0 && !slices.Equal(e.Columns, l.Columns) {
+			add(object, "has columns (%s), expected (%s)", strings.Join(l.Columns, ", "), strings.Join(e.Columns, ", "))
+		}
+		if len(l.Include) > 0 && !sameNames(e.Include, l.Include) {
+			add(object, "includes (%s), expected (%s)", strings.Join(l.Include, ", "), strings.Join(e.Include, ", "))
+		}
+	}
+	for name := range live.Indexes {
+		if _, ok := expected.Indexes[name]; !ok {
+			add("index "+name, "is not part of the schema")
+		}
+	}
+
+	for name, e := range expected.Types {
+		object := "type " + name
+		l, ok := live.Types[name]
+		if !ok {
+			add(object, "is missing")
+			continue
+		}
+		if !slices.Equal(e.Fields, l.Fields) {
+			add(object, "has fields (%s), expected (%s)", formatFields(l.Fields), formatFields(e.Fields))
+		}
+	}
+	for name := range live.Types {
+		if _, ok := expected.Types[name]; !ok {
+			add("type "+name, "is not part of the schema")
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Object != result[j].Object {
+			return result[i].Object < result[j].Object
+		}
+		return result[i].Problem < result[j].Problem
+	})
+	return result
+}
+
+func compareColumns(object string, expected *Table, live *Table, add func(string, string, ...any)) {
+	for column, typ := range expected.Columns {
+		liveType, ok := live.Columns[column]
+		if !ok {
+			add(object, "is missing column %s", column)
+			continue
+		}
+		if liveType != typ {
+			add(object, "has column %s of type %s, expected %s", column, liveType, typ)
+		}
+	}
+	for column := range live.Columns {
+		if _, ok := expected.Columns[column]; !ok {
+			add(object, "has column %s which is not part of the schema", column)
+		}
+	}
+}
+
+// sameNames reports whether two lists hold the same names, in any order
+func sameNames(a []string, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
+
+func formatFields(fields []Field) string {
+	result := make([]string, 0, len(fields))
+	for _, f := range fields {
+		result = append(result, f.Name+" "+f.Type)
+	}
+	return strings.Join(result, ", ")
+}
@@ -0,0 +1,97 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package yugabyte
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare(t *testing.T) {
+	stmts := []string{
+		"CREATE TYPE serialized_blob (encoding_type text, data blob);",
+		"CREATE TABLE namespaces (id uuid, name text, detail blob, PRIMARY KEY (id)) WITH transactions = { 'enabled' : true };",
+		"CREATE INDEX namespace_by_id_idx ON namespaces (name) INCLUDE (detail);",
+		"CREATE TABLE executions (shard_id int, run_id uuid, state blob, PRIMARY KEY ((shard_id), run_id)) " +
+			"WITH transactions = { 'enabled' : true };",
+	}
+	expected, err := ParseSchema(stmts)
+	require.NoError(t, err)
+	live, err := ParseSchema(stmts)
+	require.NoError(t, err)
+	require.Empty(t, Compare(expected, live))
+
+	delete(live.Indexes, "namespace_by_id_idx")
+	live.Tables["executions"].Transactional = false
+	live.Tables["executions"].Columns["state"] = "text"
+	live.Tables["namespaces"].Columns["notes"] = "text"
+	delete(live.Tables["namespaces"].Columns, "detail")
+	live.Tables["schema_version"] = &Table{Name: "schema_version"}
+	live.Tables["scratch"] = &Table{Name: "scratch"}
+	live.Types["serialized_blob"].Fields = []Field{{Name: "data", Type: "blob"}}
+
+	assert.Equal(t, []string{
+		"index namespace_by_id_idx is missing",
+		"table executions does not have transactions enabled",
+		"table executions has column state of type text, expected blob",
+		"table namespaces has column notes which is not part of the schema",
+		"table namespaces is missing column detail",
+		"table scratch is not part of the schema",
+		"type serialized_blob has fields (data blob), expected (encoding_type text, data blob)",
+	}, differences(Compare(expected, live)))
+}
+
+func TestCompareIndexDetails(t *testing.T) {
+	expected, err := ParseSchema([]string{
+		"CREATE TABLE executions (shard_id int, run_id uuid, status int, PRIMARY KEY (shard_id, run_id));",
+		"CREATE INDEX executions_by_status ON executions ((shard_id), status) INCLUDE (run_id);",
+	})
+	require.NoError(t, err)
+
+	// clusters which do not report the columns of an index are not held to them
+	live := NewSchema()
+	live.Tables = expected.Tables
+	live.Indexes["executions_by_status"] = &Index{Name: "executions_by_status", Table: "executions"}
+	assert.Empty(t, Compare(expected, live))
+
+	live.Indexes["executions_by_status"] = &Index{
+		Name:    "executions_by_status",
+		Table:   "executions",
+		Columns: []string{"shard_id", "run_id"},
+		Include: []string{"status"},
+	}
+	assert.Equal(t, []string{
+		"index executions_by_status has columns (shard_id, run_id), expected (shard_id, status)",
+		"index executions_by_status includes (status), expected (run_id)",
+	}, differences(Compare(expected, live)))
+}
+
+func differences(diffs []Difference) []string {
+	result := make([]string, 0, len(diffs))
+	for _, d := range diffs {
+		result = append(result, d.String())
+	}
+	return result
+}
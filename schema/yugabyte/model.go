@@ -0,0 +1,333 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package yugabyte
+
+import (
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strings"
+)
+
+type (
+	// Schema describes the tables, indexes and user defined types of a keyspace
+	Schema struct {
+		Tables  map[string]*Table
+		Indexes map[string]*Index
+		Types   map[string]*Type
+	}
+
+	// Table describes a table of a keyspace
+	Table struct {
+		Name string
+		// Columns maps the name of each column to its normalized type
+		Columns       map[string]string
+		PartitionKey  []string
+		ClusteringKey []string
+		Transactional bool
+	}
+
+	// Index describes a secondary index of a keyspace
+	Index struct {
+		Name  string
+		Table string
+		// Columns are the key columns of the index, in order
+		Columns []string
+		// Include are the covering columns of the index
+		Include []string
+	}
+
+	// Type describes a user defined type of a keyspace
+	Type struct {
+		Name   string
+		Fields []Field
+	}
+
+	// Field is a field of a user defined type
+	Field struct {
+		Name string
+		Type string
+	}
+)
+
+var (
+	createTableStmtRegex = regexp.MustCompile(`(?is)^CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?([\w.]+)\s*\(`)
+	createIndexStmtRegex = regexp.MustCompile(`(?is)^CREATE\s+(?:UNIQUE\s+)?INDEX\s+(?:IF\s+NOT\s+EXISTS\s+)?([\w.]+)\s+ON\s+([\w.]+)\s*\(`)
+	includeRegex         = regexp.MustCompile(`(?is)^\s*INCLUDE\s*\(([^)]*)\)`)
+	createTypeStmtRegex  = regexp.MustCompile(`(?is)^CREATE\s+TYPE\s+(?:IF\s+NOT\s+EXISTS\s+)?([\w.]+)\s*\((.*)\)\s*;?$`)
+	dropStmtRegex        = regexp.MustCompile(`(?is)^DROP\s+(TABLE|INDEX|TYPE)\s+(?:IF\s+EXISTS\s+)?([\w.]+)\s*;?$`)
+	alterTableStmtRegex  = regexp.MustCompile(`(?is)^ALTER\s+TABLE\s+([\w.]+)\s+(ADD|DROP)\s+(.*?)\s*;?$`)
+	primaryKeyRegex      = regexp.MustCompile(`(?is)^PRIMARY\s+KEY\s*\((.*)\)$`)
+	inlinePrimaryKey     = regexp.MustCompile(`(?is)\s+PRIMARY\s+KEY$`)
+	transactionsOnRegex  = regexp.MustCompile(`(?is)\btransactions\s*=\s*\{\s*'enabled'\s*:\s*true\s*}`)
+	whitespaceRegex      = regexp.MustCompile(`\s+`)
+)
+
+// NewSchema returns an empty schema
+func NewSchema() *Schema {
+	return &Schema{
+		Tables:  make(map[string]*Table),
+		Indexes: make(map[string]*Index),
+		Types:   make(map[string]*Type),
+	}
+}
+
+// ParseSchema returns the schema created by a sequence of CQL statements
+func ParseSchema(stmts []string) (*Schema, error) {
+	s := NewSchema()
+	for _, stmt := range stmts {
+		if err := s.Apply(stmt); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// ExpectedSchema returns the schema of a keyspace to which the versioned schema of fsys has been applied up to and
+// including version
+func ExpectedSchema(fsys fs.FS, version string) (*Schema, error) {
+	s := NewSchema()
+	err := WalkVersions(fsys, version, func(version string, _ *Manifest, stmts []string) error {
+		for _, stmt := range stmts {
+			if err := s.Apply(stmt); err != nil {
+				return fmt.Errorf("%s: %w", VersionDir(version), err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Apply updates the schema with the effect of a CQL statement.  Statements which do not change the schema, such as
+// inserts, are ignored.
+func (s *Schema) Apply(stmt string) error {
+	stmt = strings.TrimSpace(stmt)
+	if match := createTableStmtRegex.FindStringSubmatchIndex(stmt); match != nil {
+		open := match[1] - 1
+		end := closingParen(stmt, open)
+		if end < 0 {
+			return fmt.Errorf("unbalanced parentheses: %s", firstLine(stmt))
+		}
+		table, err := parseTable(stmt[match[2]:match[3]], stmt[open+1:end], stmt[end+1:])
+		if err != nil {
+			return err
+		}
+		s.Tables[table.Name] = table
+		return nil
+	}
+	if match := createIndexStmtRegex.FindStringSubmatchIndex(stmt); match != nil {
+		open := match[1] - 1
+		end := closingParen(stmt, open)
+		if end < 0 {
+			return fmt.Errorf("unbalanced parentheses: %s", firstLine(stmt))
+		}
+		index := &Index{
+			Name:    normalizeName(stmt[match[2]:match[3]]),
+			Table:   normalizeName(stmt[match[4]:match[5]]),
+			Columns: splitNames(stmt[open+1 : end]),
+		}
+		if include := includeRegex.FindStringSubmatch(stmt[end+1:]); include != nil {
+			index.Include = splitNames(include[1])
+		}
+		s.Indexes[index.Name] = index
+		return nil
+	}
+	if match := createTypeStmtRegex.FindStringSubmatch(stmt); match != nil {
+		t := &Type{Name: normalizeName(match[1])}
+		for _, def := range splitTopLevel(match[2]) {
+			name, typ, ok := cutColumn(def)
+			if !ok {
+				return fmt.Errorf("type %s: invalid field %q", t.Name, def)
+			}
+			t.Fields = append(t.Fields, Field{Name: name, Type: typ})
+		}
+		s.Types[t.Name] = t
+		return nil
+	}
+	if match := dropStmtRegex.FindStringSubmatch(stmt); match != nil {
+		name := normalizeName(match[2])
+		switch strings.ToUpper(match[1]) {
+		case "TABLE":
+			delete(s.Tables, name)
+			for indexName, index := range s.Indexes {
+				if index.Table == name {
+					delete(s.Indexes, indexName)
+				}
+			}
+		case "INDEX":
+			delete(s.Indexes, name)
+		case "TYPE":
+			delete(s.Types, name)
+		}
+		return nil
+	}
+	if match := alterTableStmtRegex.FindStringSubmatch(stmt); match != nil {
+		return s.alterTable(normalizeName(match[1]), strings.ToUpper(match[2]), match[3])
+	}
+	if strings.HasPrefix(strings.ToUpper(stmt), "INSERT") {
+		return nil
+	}
+	return fmt.Errorf("unsupported statement: %s", firstLine(stmt))
+}
+
+func (s *Schema) alterTable(name string, op string, defs string) error {
+	table, ok := s.Tables[name]
+	if !ok {
+		return fmt.Errorf("alter of unknown table %s", name)
+	}
+	for _, def := range splitTopLevel(defs) {
+		if op == "DROP" {
+			delete(table.Columns, normalizeName(def))
+			continue
+		}
+		column, typ, ok := cutColumn(def)
+		if !ok {
+			return fmt.Errorf("table %s: invalid column %q", name, def)
+		}
+		table.Columns[column] = typ
+	}
+	return nil
+}
+
+func parseTable(name string, body string, options string) (*Table, error) {
+	table := &Table{
+		Name:          normalizeName(name),
+		Columns:       make(map[string]string),
+		Transactional: transactionsOnRegex.MatchString(options),
+	}
+
+	for _, def := range splitTopLevel(body) {
+		if match := primaryKeyRegex.FindStringSubmatch(def); match != nil {
+			parts := splitTopLevel(match[1])
+			if len(parts) == 0 {
+				return nil, fmt.Errorf("table %s: empty primary key", table.Name)
+			}
+			table.PartitionKey = splitNames(parts[0])
+			for _, part := range parts[1:] {
+				table.ClusteringKey = append(table.ClusteringKey, normalizeName(part))
+			}
+			continue
+		}
+
+		inline := inlinePrimaryKey.MatchString(def)
+		def = inlinePrimaryKey.ReplaceAllString(def, "")
+		column, typ, ok := cutColumn(def)
+		if !ok {
+			return nil, fmt.Errorf("table %s: invalid column %q", table.Name, def)
+		}
+		table.Columns[column] = typ
+		if inline {
+			table.PartitionKey = []string{column}
+		}
+	}
+
+	if len(table.PartitionKey) == 0 {
+		return nil, fmt.Errorf("table %s has no primary key", table.Name)
+	}
+	return table, nil
+}
+
+// cutColumn splits a column or field definition into its name and normalized type
+func cutColumn(def string) (string, string, bool) {
+	def = strings.TrimSpace(def)
+	i := strings.IndexFunc(def, func(r rune) bool { return r == ' ' || r == '\t' || r == '\n' })
+	if i < 0 {
+		return "", "", false
+	}
+	return normalizeName(def[:i]), NormalizeType(def[i:]), true
+}
+
+// closingParen returns the index of the parenthesis closing the one at open, or -1
+func closingParen(stmt string, open int) int {
+	depth := 0
+	for i := open; i < len(stmt); i++ {
+		switch stmt[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitTopLevel splits a comma separated list, ignoring the commas nested within parentheses or angle brackets
+func splitTopLevel(list string) []string {
+	var result []string
+	depth, start := 0, 0
+	for i, r := range list {
+		switch r {
+		case '(', '<':
+			depth++
+		case ')', '>':
+			depth--
+		case ',':
+			if depth == 0 {
+				result = appendNonEmpty(result, list[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return appendNonEmpty(result, list[start:])
+}
+
+func appendNonEmpty(list []string, item string) []string {
+	if item = strings.TrimSpace(item); item != "" {
+		list = append(list, item)
+	}
+	return list
+}
+
+// splitNames splits a list of column names, flattening any parenthesized groups such as a composite partition key
+func splitNames(list string) []string {
+	var result []string
+	for _, name := range strings.Split(strings.NewReplacer("(", "", ")", "").Replace(list), ",") {
+		if name = normalizeName(name); name != "" {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.Trim(strings.TrimSpace(name), `"`))
+}
+
+// NormalizeType returns a CQL type in the form used to compare the types of the expected and live schemas
+func NormalizeType(typ string) string {
+	typ = strings.ToLower(whitespaceRegex.ReplaceAllString(typ, ""))
+	return strings.ReplaceAll(typ, "varchar", "text")
+}
+
+func firstLine(stmt string) string {
+	if i := strings.IndexByte(stmt, '\n'); i >= 0 {
+		return stmt[:i] + " ..."
+	}
+	return stmt
+}
@@ -0,0 +1,113 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package yugabyte
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/server/common/persistence"
+)
+
+func TestSchemaApply(t *testing.T) {
+	s, err := ParseSchema([]string{
+		"CREATE TYPE serialized_event_batch (\n  encoding_type text,\n  data blob\n);",
+		"CREATE TABLE schema_version (keyspace_name text PRIMARY KEY, curr_version varchar);",
+		"CREATE TABLE history_node (\n" +
+			"  tree_id uuid,\n  branch_id uuid,\n  node_id bigint,\n  txn_id bigint,\n" +
+			"  data map<bigint, blob>,\n  events list<frozen<serialized_event_batch>>,\n" +
+			"  PRIMARY KEY ((tree_id), branch_id, node_id, txn_id )\n" +
+			") WITH CLUSTERING ORDER BY (branch_id ASC, node_id ASC, txn_id DESC)\n" +
+			"  AND transactions = { 'enabled' : true };",
+		"CREATE INDEX node_by_txn ON history_node ((tree_id, txn_id), node_id) INCLUDE (data) " +
+			"WITH CLUSTERING ORDER BY (node_id DESC);",
+		"ALTER TABLE history_node ADD prev_txn_id bigint;",
+		"ALTER TABLE history_node DROP data;",
+		"INSERT INTO schema_version (keyspace_name, curr_version) VALUES ('temporal', '1.0');",
+	})
+	require.NoError(t, err)
+
+	version := s.Tables["schema_version"]
+	require.NotNil(t, version)
+	assert.Equal(t, []string{"keyspace_name"}, version.PartitionKey)
+	assert.Equal(t, "text", version.Columns["curr_version"])
+	assert.False(t, version.Transactional)
+
+	node := s.Tables["history_node"]
+	require.NotNil(t, node)
+	assert.True(t, node.Transactional)
+	assert.Equal(t, []string{"tree_id"}, node.PartitionKey)
+	assert.Equal(t, []string{"branch_id", "node_id", "txn_id"}, node.ClusteringKey)
+	assert.Equal(t, map[string]string{
+		"tree_id":     "uuid",
+		"branch_id":   "uuid",
+		"node_id":     "bigint",
+		"txn_id":      "bigint",
+		"events":      "list<frozen<serialized_event_batch>>",
+		"prev_txn_id": "bigint",
+	}, node.Columns)
+
+	assert.Equal(t, &Index{
+		Name:    "node_by_txn",
+		Table:   "history_node",
+		Columns: []string{"tree_id", "txn_id", "node_id"},
+		Include: []string{"data"},
+	}, s.Indexes["node_by_txn"])
+	assert.Equal(t, []Field{{Name: "encoding_type", Type: "text"}, {Name: "data", Type: "blob"}}, s.Types["serialized_event_batch"].Fields)
+
+	require.NoError(t, s.Apply("DROP TABLE IF EXISTS history_node;"))
+	assert.NotContains(t, s.Tables, "history_node")
+	assert.NotContains(t, s.Indexes, "node_by_txn")
+
+	assert.ErrorContains(t, s.Apply("ALTER TABLE history_node ADD data blob;"), "unknown table history_node")
+	assert.ErrorContains(t, s.Apply("SELECT * FROM schema_version;"), "unsupported statement")
+}
+
+// TestExpectedSchemaMatchesCreateSchema checks that the versioned schema, applied up to the current version, creates
+// the same schema as create-schema.cql, which is the reference for new keyspaces and for schema reviews
+func TestExpectedSchemaMatchesCreateSchema(t *testing.T) {
+	for dir, version := range map[string]string{"temporal": Version, "visibility": VisibilityVersion} {
+		t.Run(dir, func(t *testing.T) {
+			expected, err := ExpectedSchema(os.DirFS(dir+"/versioned"), version)
+			require.NoError(t, err)
+
+			f, err := os.Open(dir + "/create-schema.cql")
+			require.NoError(t, err)
+			defer f.Close()
+			stmts, err := persistence.LoadAndSplitQueryFromReaders([]io.Reader{f})
+			require.NoError(t, err)
+			reference, err := ParseSchema(stmts)
+			require.NoError(t, err)
+
+			assert.Empty(t, Compare(expected, reference))
+			assert.Empty(t, Compare(reference, expected))
+			assert.NotEmpty(t, expected.Tables)
+			for name, table := range expected.Tables {
+				assert.True(t, table.Transactional, name)
+			}
+		})
+	}
+}
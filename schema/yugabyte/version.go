@@ -3,4 +3,10 @@ package yugabyte
 // NOTE: whenever there is a new database schema update, plz update the following versions
 
 // Version is the Yugabyte schema release version
-const Version = "1.0"
+const Version = "1.5"
+
+// VisibilityVersion is the Yugabyte visibility schema release version
+const VisibilityVersion = "1.0"
+
+// YSQLVisibilityVersion is the YSQL visibility schema release version, used with the yugabyte-ysql SQL plugin
+const YSQLVisibilityVersion = "1.0"
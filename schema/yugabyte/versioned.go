@@ -0,0 +1,212 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package yugabyte
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"go.temporal.io/server/common/persistence"
+)
+
+const manifestFileName = "manifest.json"
+
+type (
+	// Manifest is the manifest.json of a versioned schema directory
+	Manifest struct {
+		CurrVersion          string
+		MinCompatibleVersion string
+		Description          string
+		SchemaUpdateCqlFiles []string
+		// MD5 is the checksum of the manifest, recorded in the schema update history
+		MD5 string `json:"-"`
+	}
+)
+
+var versionDirRegex = regexp.MustCompile(`^v\d+(\.\d+)*$`)
+
+// ParseVersion parses a schema version such as "1.5"
+func ParseVersion(version string) (semver.Version, error) {
+	v, err := semver.ParseTolerant(version)
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("invalid schema version %q: %w", version, err)
+	}
+	return v, nil
+}
+
+// VersionDir returns the name of the versioned schema directory of a version
+func VersionDir(version string) string {
+	return "v" + version
+}
+
+// ListVersions returns the versions of the versioned schema directories at the root of fsys in ascending order
+func ListVersions(fsys fs.FS) ([]string, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("unable to list versioned schema: %w", err)
+	}
+
+	type version struct {
+		name   string
+		parsed semver.Version
+	}
+	var versions []version
+	for _, entry := range entries {
+		if !entry.IsDir() || !versionDirRegex.MatchString(entry.Name()) {
+			continue
+		}
+		name := strings.TrimPrefix(entry.Name(), "v")
+		parsed, err := ParseVersion(name)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, version{name: name, parsed: parsed})
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].parsed.LT(versions[j].parsed)
+	})
+
+	result := make([]string, 0, len(versions))
+	for i, v := range versions {
+		if i > 0 && versions[i-1].parsed.EQ(v.parsed) {
+			return nil, fmt.Errorf("versioned schema has more than one directory for version %s", v.name)
+		}
+		result = append(result, v.name)
+	}
+	return result, nil
+}
+
+// LatestVersion returns the highest version of the versioned schema in fsys
+func LatestVersion(fsys fs.FS) (string, error) {
+	versions, err := ListVersions(fsys)
+	if err != nil {
+		return "", err
+	}
+	if len(versions) == 0 {
+		return "", fmt.Errorf("versioned schema is empty")
+	}
+	return versions[len(versions)-1], nil
+}
+
+// ReadManifest reads the manifest of the versioned schema directory of a version, checking that it describes that
+// version
+func ReadManifest(fsys fs.FS, version string) (*Manifest, error) {
+	dir := VersionDir(version)
+	blob, err := fs.ReadFile(fsys, path.Join(dir, manifestFileName))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read manifest of %s: %w", dir, err)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(blob, &m); err != nil {
+		return nil, fmt.Errorf("unable to parse manifest of %s: %w", dir, err)
+	}
+	if m.MinCompatibleVersion == "" {
+		return nil, fmt.Errorf("manifest of %s is missing MinCompatibleVersion", dir)
+	}
+	if len(m.SchemaUpdateCqlFiles) == 0 {
+		return nil, fmt.Errorf("manifest of %s lists no SchemaUpdateCqlFiles", dir)
+	}
+	expected, err := ParseVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	if curr, err := ParseVersion(m.CurrVersion); err != nil || !curr.EQ(expected) {
+		return nil, fmt.Errorf("manifest of %s has CurrVersion %q", dir, m.CurrVersion)
+	}
+
+	sum := md5.Sum(blob)
+	m.MD5 = hex.EncodeToString(sum[:])
+	return &m, nil
+}
+
+// ReadStatements reads and splits the CQL files listed by the manifest of a version
+func ReadStatements(fsys fs.FS, version string, m *Manifest) ([]string, error) {
+	dir := VersionDir(version)
+	readers := make([]io.Reader, 0, len(m.SchemaUpdateCqlFiles))
+	for _, file := range m.SchemaUpdateCqlFiles {
+		blob, err := fs.ReadFile(fsys, path.Join(dir, file))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s: %w", path.Join(dir, file), err)
+		}
+		readers = append(readers, bytes.NewReader(blob))
+	}
+
+	stmts, err := persistence.LoadAndSplitQueryFromReaders(readers)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse statements of %s: %w", dir, err)
+	}
+	if len(stmts) == 0 {
+		return nil, fmt.Errorf("found no statements in %s", dir)
+	}
+	return stmts, nil
+}
+
+// WalkVersions calls fn with the manifest and statements of every version of fsys up to and including target, in
+// ascending order.  It fails if fsys has no directory for target.
+func WalkVersions(fsys fs.FS, target string, fn func(version string, m *Manifest, stmts []string) error) error {
+	targetParsed, err := ParseVersion(target)
+	if err != nil {
+		return err
+	}
+	versions, err := ListVersions(fsys)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, version := range versions {
+		parsed, _ := ParseVersion(version)
+		if parsed.GT(targetParsed) {
+			break
+		}
+		found = found || parsed.EQ(targetParsed)
+
+		m, err := ReadManifest(fsys, version)
+		if err != nil {
+			return err
+		}
+		stmts, err := ReadStatements(fsys, version, m)
+		if err != nil {
+			return err
+		}
+		if err := fn(version, m, stmts); err != nil {
+			return err
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("versioned schema has no version %s", target)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package yugabyte
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testVersionedSchema() fstest.MapFS {
+	return fstest.MapFS{
+		"v1.0/manifest.json": {Data: []byte(`{"CurrVersion": "1.0", "MinCompatibleVersion": "1.0", "Description": "initial", "SchemaUpdateCqlFiles": ["schema.cql"]}`)},
+		"v1.0/schema.cql": {Data: []byte(
+			"CREATE TABLE shards (shard_id int, PRIMARY KEY (shard_id)) WITH transactions = { 'enabled' : true };\n" +
+				"CREATE INDEX shards_idx ON shards (shard_id);\n",
+		)},
+		"v1.10/manifest.json": {Data: []byte(`{"CurrVersion": "1.10", "MinCompatibleVersion": "1.0", "Description": "drop index", "SchemaUpdateCqlFiles": ["index.cql"]}`)},
+		"v1.10/index.cql": {Data: []byte(
+			"-- no longer read\nDROP INDEX IF EXISTS shards_idx;\n",
+		)},
+		"v1.2/manifest.json": {Data: []byte(`{"CurrVersion": "1.2", "MinCompatibleVersion": "1.0", "Description": "column", "SchemaUpdateCqlFiles": ["column.cql"]}`)},
+		"v1.2/column.cql": {Data: []byte(
+			"ALTER TABLE shards ADD range_id bigint;\n",
+		)},
+		"README.md": {Data: []byte("not a version")},
+	}
+}
+
+func TestListVersions(t *testing.T) {
+	versions, err := ListVersions(testVersionedSchema())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.0", "1.2", "1.10"}, versions)
+
+	latest, err := LatestVersion(testVersionedSchema())
+	require.NoError(t, err)
+	assert.Equal(t, "1.10", latest)
+
+	fsys := testVersionedSchema()
+	fsys["v1.2.0/manifest.json"] = fsys["v1.2/manifest.json"]
+	_, err = ListVersions(fsys)
+	assert.ErrorContains(t, err, "more than one directory for version 1.2")
+}
+
+func TestReadManifest(t *testing.T) {
+	m, err := ReadManifest(testVersionedSchema(), "1.2")
+	require.NoError(t, err)
+	assert.Equal(t, "column", m.Description)
+	assert.Equal(t, []string{"column.cql"}, m.SchemaUpdateCqlFiles)
+	assert.Len(t, m.MD5, 32)
+
+	fsys := testVersionedSchema()
+	fsys["v1.2/manifest.json"] = &fstest.MapFile{Data: []byte(`{"CurrVersion": "1.3", "MinCompatibleVersion": "1.0", "SchemaUpdateCqlFiles": ["column.cql"]}`)}
+	_, err = ReadManifest(fsys, "1.2")
+	assert.ErrorContains(t, err, `v1.2 has CurrVersion "1.3"`)
+
+	_, err = ReadManifest(fsys, "1.5")
+	assert.ErrorContains(t, err, "unable to read manifest of v1.5")
+}
+
+func TestWalkVersions(t *testing.T) {
+	var walked []string
+	err := WalkVersions(testVersionedSchema(), "1.2", func(version string, m *Manifest, stmts []string) error {
+		walked = append(walked, version)
+		assert.NotEmpty(t, stmts)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.0", "1.2"}, walked)
+
+	err = WalkVersions(testVersionedSchema(), "1.1", func(string, *Manifest, []string) error { return nil })
+	assert.ErrorContains(t, err, "no version 1.1")
+}
@@ -0,0 +1,196 @@
+-- Visibility schema for the yugabyte-ysql SQL plugin.  It has the tables and columns of the PostgreSQL v12 visibility
+-- schema, which the statements of the PostgreSQL plugin depend on, with the following differences for YSQL:
+--  * search attribute columns are maintained by a trigger rather than generated, as YSQL releases based on
+--    PostgreSQL 11 do not support generated columns;
+--  * the first key column of each index is hash partitioned by namespace, and by workflow or run ID for the indexes
+--    only ever read by equality on them, which spreads those indexes across tablets;
+--  * search attributes holding lists or text are indexed with single-column ybgin indexes, as btree_gin is not
+--    available.
+
+-- convert_ts converts a timestamp in RFC3339 to UTC timestamp without time zone.
+CREATE FUNCTION convert_ts(s VARCHAR) RETURNS TIMESTAMP AS $$
+BEGIN
+  RETURN s::timestamptz at time zone 'UTC';
+END
+$$ LANGUAGE plpgsql IMMUTABLE RETURNS NULL ON NULL INPUT;
+
+CREATE TABLE executions_visibility (
+  namespace_id            CHAR(64)      NOT NULL,
+  run_id                  CHAR(64)      NOT NULL,
+  _version                BIGINT        NOT NULL DEFAULT 0, -- increasing version, used to reject upserts which are out of order
+  start_time              TIMESTAMP     NOT NULL,
+  execution_time          TIMESTAMP     NOT NULL,
+  workflow_id             VARCHAR(255)  NOT NULL,
+  workflow_type_name      VARCHAR(255)  NOT NULL,
+  status                  INTEGER       NOT NULL,  -- enum WorkflowExecutionStatus {RUNNING, COMPLETED, FAILED, CANCELED, TERMINATED, CONTINUED_AS_NEW, TIMED_OUT}
+  close_time              TIMESTAMP     NULL,
+  history_length          BIGINT        NULL,
+  history_size_bytes      BIGINT        NULL,
+  execution_duration      BIGINT        NULL,
+  state_transition_count  BIGINT        NULL,
+  memo                    BYTEA         NULL,
+  encoding                VARCHAR(64)   NOT NULL,
+  task_queue              VARCHAR(255)  NOT NULL DEFAULT '',
+  search_attributes       JSONB         NULL,
+  parent_workflow_id      VARCHAR(255)  NULL,
+  parent_run_id           VARCHAR(255)  NULL,
+  root_workflow_id        VARCHAR(255)  NOT NULL DEFAULT '',
+  root_run_id             VARCHAR(255)  NOT NULL DEFAULT '',
+
+  -- Pre-defined search attributes, set by executions_visibility_search_attributes
+  TemporalChangeVersion               JSONB           NULL,
+  BinaryChecksums                     JSONB           NULL,
+  BatcherUser                         VARCHAR(255)    NULL,
+  TemporalScheduledStartTime          TIMESTAMP       NULL,
+  TemporalScheduledById               VARCHAR(255)    NULL,
+  TemporalSchedulePaused              BOOLEAN         NULL,
+  TemporalNamespaceDivision           VARCHAR(255)    NULL,
+  BuildIds                            JSONB           NULL,
+  TemporalPauseInfo                   JSONB           NULL,
+  TemporalWorkerDeploymentVersion     VARCHAR(255)    NULL,
+  TemporalWorkflowVersioningBehavior  VARCHAR(255)    NULL,
+  TemporalWorkerDeployment            VARCHAR(255)    NULL,
+
+  -- Pre-allocated custom search attributes, set by executions_visibility_search_attributes
+  Bool01                              BOOLEAN         NULL,
+  Bool02                              BOOLEAN         NULL,
+  Bool03                              BOOLEAN         NULL,
+  Datetime01                          TIMESTAMP       NULL,
+  Datetime02                          TIMESTAMP       NULL,
+  Datetime03                          TIMESTAMP       NULL,
+  Double01                            DECIMAL(20, 5)  NULL,
+  Double02                            DECIMAL(20, 5)  NULL,
+  Double03                            DECIMAL(20, 5)  NULL,
+  Int01                               BIGINT          NULL,
+  Int02                               BIGINT          NULL,
+  Int03                               BIGINT          NULL,
+  Keyword01                           VARCHAR(255)    NULL,
+  Keyword02                           VARCHAR(255)    NULL,
+  Keyword03                           VARCHAR(255)    NULL,
+  Keyword04                           VARCHAR(255)    NULL,
+  Keyword05                           VARCHAR(255)    NULL,
+  Keyword06                           VARCHAR(255)    NULL,
+  Keyword07                           VARCHAR(255)    NULL,
+  Keyword08                           VARCHAR(255)    NULL,
+  Keyword09                           VARCHAR(255)    NULL,
+  Keyword10                           VARCHAR(255)    NULL,
+  Text01                              TSVECTOR        NULL,
+  Text02                              TSVECTOR        NULL,
+  Text03                              TSVECTOR        NULL,
+  KeywordList01                       JSONB           NULL,
+  KeywordList02                       JSONB           NULL,
+  KeywordList03                       JSONB           NULL,
+
+  PRIMARY KEY  ((namespace_id, run_id) HASH)
+);
+
+-- executions_visibility_search_attributes sets the search attribute columns from search_attributes on every write,
+-- as generated columns would.
+CREATE FUNCTION executions_visibility_search_attributes() RETURNS TRIGGER AS $$
+BEGIN
+  NEW.TemporalChangeVersion              := NEW.search_attributes->'TemporalChangeVersion';
+  NEW.BinaryChecksums                    := NEW.search_attributes->'BinaryChecksums';
+  NEW.BatcherUser                        := NEW.search_attributes->>'BatcherUser';
+  NEW.TemporalScheduledStartTime         := convert_ts(NEW.search_attributes->>'TemporalScheduledStartTime');
+  NEW.TemporalScheduledById              := NEW.search_attributes->>'TemporalScheduledById';
+  NEW.TemporalSchedulePaused             := (NEW.search_attributes->'TemporalSchedulePaused')::boolean;
+  NEW.TemporalNamespaceDivision          := NEW.search_attributes->>'TemporalNamespaceDivision';
+  NEW.BuildIds                           := NEW.search_attributes->'BuildIds';
+  NEW.TemporalPauseInfo                  := NEW.search_attributes->'TemporalPauseInfo';
+  NEW.TemporalWorkerDeploymentVersion    := NEW.search_attributes->>'TemporalWorkerDeploymentVersion';
+  NEW.TemporalWorkflowVersioningBehavior := NEW.search_attributes->>'TemporalWorkflowVersioningBehavior';
+  NEW.TemporalWorkerDeployment           := NEW.search_attributes->>'TemporalWorkerDeployment';
+  NEW.Bool01                             := (NEW.search_attributes->'Bool01')::boolean;
+  NEW.Bool02                             := (NEW.search_attributes->'Bool02')::boolean;
+  NEW.Bool03                             := (NEW.search_attributes->'Bool03')::boolean;
+  NEW.Datetime01                         := convert_ts(NEW.search_attributes->>'Datetime01');
+  NEW.Datetime02                         := convert_ts(NEW.search_attributes->>'Datetime02');
+  NEW.Datetime03                         := convert_ts(NEW.search_attributes->>'Datetime03');
+  NEW.Double01                           := (NEW.search_attributes->'Double01')::decimal;
+  NEW.Double02                           := (NEW.search_attributes->'Double02')::decimal;
+  NEW.Double03                           := (NEW.search_attributes->'Double03')::decimal;
+  NEW.Int01                              := (NEW.search_attributes->'Int01')::bigint;
+  NEW.Int02                              := (NEW.search_attributes->'Int02')::bigint;
+  NEW.Int03                              := (NEW.search_attributes->'Int03')::bigint;
+  NEW.Keyword01                          := NEW.search_attributes->>'Keyword01';
+  NEW.Keyword02                          := NEW.search_attributes->>'Keyword02';
+  NEW.Keyword03                          := NEW.search_attributes->>'Keyword03';
+  NEW.Keyword04                          := NEW.search_attributes->>'Keyword04';
+  NEW.Keyword05                          := NEW.search_attributes->>'Keyword05';
+  NEW.Keyword06                          := NEW.search_attributes->>'Keyword06';
+  NEW.Keyword07                          := NEW.search_attributes->>'Keyword07';
+  NEW.Keyword08                          := NEW.search_attributes->>'Keyword08';
+  NEW.Keyword09                          := NEW.search_attributes->>'Keyword09';
+  NEW.Keyword10                          := NEW.search_attributes->>'Keyword10';
+  NEW.Text01                             := (NEW.search_attributes->>'Text01')::tsvector;
+  NEW.Text02                             := (NEW.search_attributes->>'Text02')::tsvector;
+  NEW.Text03                             := (NEW.search_attributes->>'Text03')::tsvector;
+  NEW.KeywordList01                      := NEW.search_attributes->'KeywordList01';
+  NEW.KeywordList02                      := NEW.search_attributes->'KeywordList02';
+  NEW.KeywordList03                      := NEW.search_attributes->'KeywordList03';
+  RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER executions_visibility_search_attributes
+  BEFORE INSERT OR UPDATE ON executions_visibility
+  FOR EACH ROW EXECUTE PROCEDURE executions_visibility_search_attributes();
+
+CREATE INDEX default_idx                              ON executions_visibility (namespace_id HASH, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_execution_time                        ON executions_visibility (namespace_id HASH, execution_time, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_status                                ON executions_visibility (namespace_id HASH, status, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_workflow_type                         ON executions_visibility (namespace_id HASH, workflow_type_name, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_history_length                        ON executions_visibility (namespace_id HASH, history_length, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_history_size_bytes                    ON executions_visibility (namespace_id HASH, history_size_bytes, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_execution_duration                    ON executions_visibility (namespace_id HASH, execution_duration, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_state_transition_count                ON executions_visibility (namespace_id HASH, state_transition_count, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_task_queue                            ON executions_visibility (namespace_id HASH, task_queue, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_workflow_id                           ON executions_visibility ((namespace_id, workflow_id) HASH, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_parent_workflow_id                    ON executions_visibility ((namespace_id, parent_workflow_id) HASH, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_parent_run_id                         ON executions_visibility ((namespace_id, parent_run_id) HASH, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_root_workflow_id                      ON executions_visibility ((namespace_id, root_workflow_id) HASH, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_root_run_id                           ON executions_visibility ((namespace_id, root_run_id) HASH, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+
+-- Indexes for the predefined search attributes
+CREATE INDEX by_temporal_change_version               ON executions_visibility USING ybgin (TemporalChangeVersion jsonb_path_ops);
+CREATE INDEX by_binary_checksums                      ON executions_visibility USING ybgin (BinaryChecksums jsonb_path_ops);
+CREATE INDEX by_build_ids                             ON executions_visibility USING ybgin (BuildIds jsonb_path_ops);
+CREATE INDEX by_temporal_pause_info                   ON executions_visibility USING ybgin (TemporalPauseInfo jsonb_path_ops);
+CREATE INDEX by_temporal_worker_deployment_version    ON executions_visibility (namespace_id HASH, TemporalWorkerDeploymentVersion, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_temporal_workflow_versioning_behavior ON executions_visibility (namespace_id HASH, TemporalWorkflowVersioningBehavior, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_temporal_worker_deployment            ON executions_visibility (namespace_id HASH, TemporalWorkerDeployment, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_batcher_user                          ON executions_visibility (namespace_id HASH, BatcherUser, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_temporal_scheduled_start_time         ON executions_visibility (namespace_id HASH, TemporalScheduledStartTime, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_temporal_scheduled_by_id              ON executions_visibility (namespace_id HASH, TemporalScheduledById, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_temporal_schedule_paused              ON executions_visibility (namespace_id HASH, TemporalSchedulePaused, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_temporal_namespace_division           ON executions_visibility (namespace_id HASH, TemporalNamespaceDivision, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+
+-- Indexes for the pre-allocated custom search attributes
+CREATE INDEX by_bool_01                               ON executions_visibility (namespace_id HASH, Bool01, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_bool_02                               ON executions_visibility (namespace_id HASH, Bool02, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_bool_03                               ON executions_visibility (namespace_id HASH, Bool03, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_datetime_01                           ON executions_visibility (namespace_id HASH, Datetime01, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_datetime_02                           ON executions_visibility (namespace_id HASH, Datetime02, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_datetime_03                           ON executions_visibility (namespace_id HASH, Datetime03, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_double_01                             ON executions_visibility (namespace_id HASH, Double01, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_double_02                             ON executions_visibility (namespace_id HASH, Double02, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_double_03                             ON executions_visibility (namespace_id HASH, Double03, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_int_01                                ON executions_visibility (namespace_id HASH, Int01, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_int_02                                ON executions_visibility (namespace_id HASH, Int02, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_int_03                                ON executions_visibility (namespace_id HASH, Int03, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_keyword_01                            ON executions_visibility (namespace_id HASH, Keyword01, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_keyword_02                            ON executions_visibility (namespace_id HASH, Keyword02, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_keyword_03                            ON executions_visibility (namespace_id HASH, Keyword03, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_keyword_04                            ON executions_visibility (namespace_id HASH, Keyword04, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_keyword_05                            ON executions_visibility (namespace_id HASH, Keyword05, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_keyword_06                            ON executions_visibility (namespace_id HASH, Keyword06, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_keyword_07                            ON executions_visibility (namespace_id HASH, Keyword07, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_keyword_08                            ON executions_visibility (namespace_id HASH, Keyword08, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_keyword_09                            ON executions_visibility (namespace_id HASH, Keyword09, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_keyword_10                            ON executions_visibility (namespace_id HASH, Keyword10, (COALESCE(close_time, '9999-12-31 23:59:59')) DESC, start_time DESC, run_id);
+CREATE INDEX by_text_01                               ON executions_visibility USING ybgin (Text01);
+CREATE INDEX by_text_02                               ON executions_visibility USING ybgin (Text02);
+CREATE INDEX by_text_03                               ON executions_visibility USING ybgin (Text03);
+CREATE INDEX by_keyword_list_01                       ON executions_visibility USING ybgin (KeywordList01 jsonb_path_ops);
+CREATE INDEX by_keyword_list_02                       ON executions_visibility USING ybgin (KeywordList02 jsonb_path_ops);
+CREATE INDEX by_keyword_list_03                       ON executions_visibility USING ybgin (KeywordList03 jsonb_path_ops);
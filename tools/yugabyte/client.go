@@ -0,0 +1,232 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package yugabyte
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/manetu/temporal-yugabyte/driver"
+	ybconfig "github.com/manetu/temporal-yugabyte/driver/config"
+	localgocql "github.com/manetu/temporal-yugabyte/utils/gocql"
+	"github.com/yugabyte/gocql"
+	"go.temporal.io/server/common/log"
+	"go.temporal.io/server/common/log/tag"
+	"go.temporal.io/server/common/metrics"
+	"go.temporal.io/server/common/resolver"
+)
+
+const (
+	systemKeyspace = "system"
+
+	// schemaAgreementTimeout bounds the wait for all tservers to see a DDL statement.  Index creation on a populated
+	// table includes its online backfill, so this is deliberately generous.
+	schemaAgreementTimeout = 5 * time.Minute
+)
+
+const (
+	writeSchemaVersionCQL = `INSERT INTO schema_version (keyspace_name, creation_time, curr_version, min_compatible_version) ` +
+		`VALUES (?, ?, ?, ?)`
+
+	writeSchemaUpdateHistoryCQL = `INSERT INTO schema_update_history (year, month, update_time, old_version, new_version, manifest_md5, description) ` +
+		`VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	createSchemaVersionTableCQL = `CREATE TABLE IF NOT EXISTS schema_version (` +
+		`keyspace_name text PRIMARY KEY, ` +
+		`creation_time timestamp, ` +
+		`curr_version text, ` +
+		`min_compatible_version text` +
+		`) WITH transactions = { 'enabled' : true }`
+
+	createSchemaUpdateHistoryTableCQL = `CREATE TABLE IF NOT EXISTS schema_update_history (` +
+		`year int, ` +
+		`month int, ` +
+		`update_time timestamp, ` +
+		`description text, ` +
+		`manifest_md5 text, ` +
+		`new_version text, ` +
+		`old_version text, ` +
+		`PRIMARY KEY ((year, month), update_time)` +
+		`) WITH transactions = { 'enabled' : true }`
+
+	createKeyspaceCQL = `CREATE KEYSPACE IF NOT EXISTS %v WITH replication = %v`
+
+	validateHealthCQL = `SELECT release_version FROM system.local`
+)
+
+type (
+	// client executes the statements of the tool against a Yugabyte cluster
+	client struct {
+		keyspace string
+		session  localgocql.Session
+		logger   log.Logger
+	}
+
+	// placement is the replication of a keyspace: a replication factor per datacenter, or a single replication factor
+	// for the whole universe when no datacenter is named
+	placement struct {
+		replicationFactor int
+		datacenters       map[string]int
+	}
+)
+
+// newClient connects to the cluster described by cfg.  Statements are executed in cfg.Keyspace unless connectTo
+// names another keyspace, which is necessary before cfg.Keyspace exists.
+func newClient(cfg ybconfig.Yugabyte, connectTo string, logger log.Logger) (*client, error) {
+	keyspace := cfg.Keyspace
+	if connectTo != "" {
+		cfg.Keyspace = connectTo
+	}
+
+	logger.Info("Validating connection to yugabyte cluster.", tag.NewStringTag("hosts", cfg.Hosts))
+	session, err := localgocql.NewSession(
+		func() (*gocql.ClusterConfig, error) {
+			return localgocql.NewYugabyteCluster(cfg, resolver.NewNoopResolver())
+		},
+		logger,
+		metrics.NoopMetricsHandler,
+		localgocql.WithRetryPolicy(localgocql.NewRetryPolicy(cfg.Retry)),
+	)
+	if err != nil {
+		logger.Error("Connection validation failed.", tag.Error(err))
+		return nil, err
+	}
+	logger.Info("Connection validation succeeded.")
+
+	return &client{
+		keyspace: keyspace,
+		session:  session,
+		logger:   logger,
+	}, nil
+}
+
+// Exec executes a statement and, since most statements of the tool are DDL, waits for schema agreement
+func (c *client) Exec(stmt string, args ...interface{}) error {
+	if err := c.session.Query(stmt, args...).Exec(); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), schemaAgreementTimeout)
+	defer cancel()
+	return c.session.AwaitSchemaAgreement(ctx)
+}
+
+// Close closes the session of the client
+func (c *client) Close() {
+	c.session.Close()
+}
+
+// ValidateHealth executes a trivial query against the cluster
+func (c *client) ValidateHealth() (string, error) {
+	var release string
+	if err := c.session.Query(validateHealthCQL).Scan(&release); err != nil {
+		return "", err
+	}
+	return release, nil
+}
+
+// CreateKeyspace creates the keyspace of the client, if it does not already exist
+func (c *client) CreateKeyspace(p placement) error {
+	c.logger.Info("Creating keyspace.", tag.NewStringTag("keyspace", c.keyspace), tag.NewStringTag("replication", p.String()))
+	return c.Exec(fmt.Sprintf(createKeyspaceCQL, c.keyspace, p.String()))
+}
+
+// CreateSchemaVersionTables creates the tables recording the schema version and update history of the keyspace
+func (c *client) CreateSchemaVersionTables() error {
+	if err := c.Exec(createSchemaVersionTableCQL); err != nil {
+		return err
+	}
+	return c.Exec(createSchemaUpdateHistoryTableCQL)
+}
+
+// ReadSchemaVersion returns the current schema version of the keyspace
+func (c *client) ReadSchemaVersion() (string, error) {
+	return driver.NewSchemaVersionReader(c.session).ReadSchemaVersion(c.keyspace)
+}
+
+// UpdateSchemaVersion records the current schema version of the keyspace
+func (c *client) UpdateSchemaVersion(newVersion string, minCompatibleVersion string) error {
+	return c.session.Query(writeSchemaVersionCQL, c.keyspace, time.Now().UTC(), newVersion, minCompatibleVersion).Exec()
+}
+
+// WriteSchemaUpdateLog adds an entry to the schema update history of the keyspace
+func (c *client) WriteSchemaUpdateLog(oldVersion string, newVersion string, manifestMD5 string, desc string) error {
+	now := time.Now().UTC()
+	return c.session.Query(writeSchemaUpdateHistoryCQL,
+		now.Year(), int(now.Month()), now, oldVersion, newVersion, manifestMD5, desc,
+	).Exec()
+}
+
+// parsePlacement parses a placement such as "dc1=3,dc2=2".  An empty placement replicates the keyspace
+// replicationFactor times.
+func parsePlacement(value string, replicationFactor int) (placement, error) {
+	p := placement{replicationFactor: replicationFactor}
+	if replicationFactor < 1 {
+		return p, fmt.Errorf("replication factor must be positive, got %d", replicationFactor)
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return p, nil
+	}
+
+	p.datacenters = make(map[string]int)
+	for _, item := range strings.Split(value, ",") {
+		dc, rf, ok := strings.Cut(strings.TrimSpace(item), "=")
+		dc = strings.TrimSpace(dc)
+		if !ok || dc == "" {
+			return p, fmt.Errorf("invalid placement %q, expected datacenter=replicas", item)
+		}
+		var n int
+		if _, err := fmt.Sscanf(strings.TrimSpace(rf), "%d", &n); err != nil || n < 1 {
+			return p, fmt.Errorf("invalid replica count %q for datacenter %s", rf, dc)
+		}
+		if _, ok := p.datacenters[dc]; ok {
+			return p, fmt.Errorf("datacenter %s appears more than once in placement", dc)
+		}
+		p.datacenters[dc] = n
+	}
+	return p, nil
+}
+
+// String returns the placement as the replication map of a CREATE KEYSPACE statement
+func (p placement) String() string {
+	if len(p.datacenters) == 0 {
+		return fmt.Sprintf("{ 'class' : 'SimpleStrategy', 'replication_factor' : %d }", p.replicationFactor)
+	}
+
+	dcs := make([]string, 0, len(p.datacenters))
+	for dc := range p.datacenters {
+		dcs = append(dcs, dc)
+	}
+	sort.Strings(dcs)
+
+	var sb strings.Builder
+	sb.WriteString("{ 'class' : 'NetworkTopologyStrategy'")
+	for _, dc := range dcs {
+		fmt.Fprintf(&sb, ", '%s' : %d", dc, p.datacenters[dc])
+	}
+	sb.WriteString(" }")
+	return sb.String()
+}
@@ -0,0 +1,340 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package yugabyte
+
+import (
+	"fmt"
+	"io/fs"
+
+	"github.com/manetu/temporal-yugabyte/driver"
+	ybconfig "github.com/manetu/temporal-yugabyte/driver/config"
+	localgocql "github.com/manetu/temporal-yugabyte/utils/gocql"
+	"github.com/urfave/cli/v2"
+	"go.temporal.io/server/common/log"
+	"go.temporal.io/server/common/log/tag"
+	"go.temporal.io/server/common/persistence/schema"
+)
+
+const (
+	flagVersion           = "version"
+	flagFrom              = "from"
+	flagReplicationFactor = "replication-factor"
+	flagPlacement         = "placement"
+)
+
+// createKeyspace creates the keyspace named by the flags
+func createKeyspace(c *cli.Context, logger log.Logger) error {
+	cfg, _, err := newToolConfig(c)
+	if err != nil {
+		return err
+	}
+	p, err := parsePlacement(c.String(flagPlacement), c.Int(flagReplicationFactor))
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient(cfg, systemKeyspace, logger)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := client.CreateKeyspace(p); err != nil {
+		return fmt.Errorf("unable to create keyspace %s: %w", cfg.Keyspace, err)
+	}
+	return nil
+}
+
+// setupSchema creates the schema version tables of the keyspace and applies every version of the embedded schema it
+// is missing, so that it may be run against a new keyspace as well as one which is already set up
+func setupSchema(c *cli.Context, logger log.Logger) error {
+	cfg, s, err := newToolConfig(c)
+	if err != nil {
+		return err
+	}
+	fsys, target, err := targetVersion(c, s)
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient(cfg, "", logger)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := client.CreateSchemaVersionTables(); err != nil {
+		return fmt.Errorf("unable to create schema version tables: %w", err)
+	}
+	curr, err := recordedVersion(client.ReadSchemaVersion())
+	if err != nil {
+		return err
+	}
+	if curr == initialVersion {
+		logger.Info("No schema version recorded, applying the schema from scratch.", tag.NewStringTag("keyspace", cfg.Keyspace))
+	}
+	return applySchema(client, fsys, curr, target, logger)
+}
+
+// recordedVersion returns the schema version read from a keyspace, or initialVersion when the keyspace records none.
+// Any other failure to read the version is returned, rather than mistaken for a keyspace to set up from scratch.
+func recordedVersion(curr string, err error) (string, error) {
+	if localgocql.IsNotFoundError(err) {
+		return initialVersion, nil
+	}
+	return curr, err
+}
+
+// updateSchema applies the versions of the embedded schema which are newer than that of the keyspace
+func updateSchema(c *cli.Context, logger log.Logger) error {
+	cfg, s, err := newToolConfig(c)
+	if err != nil {
+		return err
+	}
+	fsys, target, err := targetVersion(c, s)
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient(cfg, "", logger)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	curr, err := client.ReadSchemaVersion()
+	if err != nil {
+		return fmt.Errorf("%w, run setup first", err)
+	}
+	return applySchema(client, fsys, curr, target, logger)
+}
+
+// dryRun prints the statements which update would execute.  The current version is read from the keyspace unless
+// given by the flags, in which case no connection is made.
+func dryRun(c *cli.Context, logger log.Logger) error {
+	cfg, s, err := newToolConfig(c)
+	if err != nil {
+		return err
+	}
+	fsys, target, err := targetVersion(c, s)
+	if err != nil {
+		return err
+	}
+
+	curr := c.String(flagFrom)
+	if curr == "" {
+		client, err := newClient(cfg, "", logger)
+		if err != nil {
+			return err
+		}
+		curr, err = recordedVersion(client.ReadSchemaVersion())
+		client.Close()
+		if err != nil {
+			return fmt.Errorf("%w, pass --%s to print the statements from a given version", err, flagFrom)
+		}
+	}
+
+	changeSets, err := buildChangeSets(fsys, curr, target)
+	if err != nil {
+		return err
+	}
+
+	w := c.App.Writer
+	fmt.Fprintf(w, "-- keyspace %s: %s -> %s\n", cfg.Keyspace, curr, target)
+	for _, cs := range changeSets {
+		fmt.Fprintf(w, "\n-- v%s: %s\n", cs.version, cs.manifest.Description)
+		for _, stmt := range cs.stmts {
+			fmt.Fprintln(w, stmt)
+		}
+	}
+	return nil
+}
+
+// printVersion prints the version of the embedded schema and that of the keyspace, failing if the latter is older
+func printVersion(c *cli.Context, logger log.Logger) error {
+	cfg, s, err := newToolConfig(c)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.App.Writer, "embedded %s schema version: %s\n", c.String(flagSchemaName), s.version)
+
+	client, err := newClient(cfg, "", logger)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	reader := driver.NewSchemaVersionReader(client.session)
+	curr, err := reader.ReadSchemaVersion(cfg.Keyspace)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.App.Writer, "keyspace %s schema version: %s\n", cfg.Keyspace, curr)
+
+	return schema.VerifyCompatibleVersion(reader, cfg.Keyspace, s.version)
+}
+
+// checkDrift prints the differences between the live schema of the keyspace and the one expected of its schema
+// version, failing if there are any
+func checkDrift(c *cli.Context, logger log.Logger) error {
+	cfg, s, err := newToolConfig(c)
+	if err != nil {
+		return err
+	}
+	fsys, err := s.versioned()
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient(cfg, "", logger)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	version, differences, err := driver.CheckSchemaDrift(client.session, cfg.Keyspace, fsys)
+	if err != nil {
+		return err
+	}
+
+	w := c.App.Writer
+	fmt.Fprintf(w, "keyspace %s schema version: %s\n", cfg.Keyspace, version)
+	for _, d := range differences {
+		fmt.Fprintln(w, d)
+	}
+	if len(differences) > 0 {
+		return fmt.Errorf("schema of keyspace %s has drifted from version %s in %d ways", cfg.Keyspace, version, len(differences))
+	}
+	fmt.Fprintln(w, "no drift found")
+	return nil
+}
+
+// migrateTaskQueueUserData moves the rows of the task_queue_user_data table of schema versions before 1.2 to
+// task_queue_user_data_v2, failing if any row changed while being moved so that it is run again
+func migrateTaskQueueUserData(c *cli.Context, logger log.Logger) error {
+	cfg, _, err := newToolConfig(c)
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient(cfg, "", logger)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	moved, skipped, err := driver.MigrateTaskQueueUserData(c.Context, client.session, logger)
+	if err != nil {
+		return fmt.Errorf("unable to migrate task queue user data after moving %d rows: %w", moved, err)
+	}
+	if skipped > 0 {
+		return fmt.Errorf("%d rows of task queue user data changed while being migrated, run the migration again", skipped)
+	}
+	logger.Info("Task queue user data migrated, set taskQueueUserDataMigrated to stop reading the legacy table.",
+		tag.NewStringTag("keyspace", cfg.Keyspace),
+		tag.NewInt("rows", moved),
+	)
+	return nil
+}
+
+// validateHealth checks that the cluster accepts connections and queries
+func validateHealth(c *cli.Context, logger log.Logger) error {
+	cfg, _, err := newToolConfig(c)
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient(cfg, systemKeyspace, logger)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	release, err := client.ValidateHealth()
+	if err != nil {
+		return fmt.Errorf("health check failed: %w", err)
+	}
+	logger.Info("Cluster is healthy.", tag.NewStringTag("release-version", release))
+	return nil
+}
+
+// applySchema applies the change sets from curr to target, recording the version of the keyspace after each
+func applySchema(client *client, fsys fs.FS, curr string, target string, logger log.Logger) error {
+	changeSets, err := buildChangeSets(fsys, curr, target)
+	if err != nil {
+		return err
+	}
+	if len(changeSets) == 0 {
+		logger.Info("Schema is up to date.", tag.NewStringTag("keyspace", client.keyspace), tag.NewStringTag("version", curr))
+		return nil
+	}
+
+	for _, cs := range changeSets {
+		logger.Info("Applying schema version.",
+			tag.NewStringTag("keyspace", client.keyspace),
+			tag.NewStringTag("version", cs.version),
+			tag.NewStringTag("description", cs.manifest.Description),
+		)
+		for _, stmt := range cs.stmts {
+			if err := client.Exec(stmt); err != nil {
+				return fmt.Errorf("v%s: error executing %s: %w", cs.version, summarize(stmt), err)
+			}
+		}
+		if err := client.UpdateSchemaVersion(cs.version, cs.manifest.MinCompatibleVersion); err != nil {
+			return fmt.Errorf("v%s: unable to update schema version: %w", cs.version, err)
+		}
+		if err := client.WriteSchemaUpdateLog(curr, cs.version, cs.manifest.MD5, cs.manifest.Description); err != nil {
+			return fmt.Errorf("v%s: unable to write schema update log: %w", cs.version, err)
+		}
+		curr = cs.version
+	}
+
+	logger.Info("Schema updated.", tag.NewStringTag("keyspace", client.keyspace), tag.NewStringTag("version", curr))
+	return nil
+}
+
+func newToolConfig(c *cli.Context) (ybconfig.Yugabyte, embeddedSchema, error) {
+	s, err := lookupSchema(c)
+	if err != nil {
+		return ybconfig.Yugabyte{}, embeddedSchema{}, err
+	}
+	cfg, err := newYugabyteConfig(c, s)
+	if err != nil {
+		return ybconfig.Yugabyte{}, embeddedSchema{}, err
+	}
+	return cfg, s, nil
+}
+
+// targetVersion returns the versioned schema and the version to update to, by default the one this build expects
+func targetVersion(c *cli.Context, s embeddedSchema) (fs.FS, string, error) {
+	fsys, err := s.versioned()
+	if err != nil {
+		return nil, "", err
+	}
+	target := c.String(flagVersion)
+	if target == "" {
+		target = s.version
+	}
+	return fsys, target, nil
+}
@@ -0,0 +1,126 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package yugabyte
+
+import (
+	"github.com/urfave/cli/v2"
+	"go.temporal.io/server/common/log"
+	"go.temporal.io/server/common/log/tag"
+)
+
+// RunTool runs the temporal-yugabyte-tool command line tool
+func RunTool(args []string) error {
+	return buildCLIOptions().Run(args)
+}
+
+// cliHandler adapts a handler to a cli action, logging the error it fails with
+func cliHandler(handler func(c *cli.Context, logger log.Logger) error, logger log.Logger) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		if err := handler(c, logger); err != nil {
+			logger.Error("Command failed.", tag.NewStringTag("command", c.Command.Name), tag.Error(err))
+			return cli.Exit("", 1)
+		}
+		return nil
+	}
+}
+
+func buildCLIOptions() *cli.App {
+	app := cli.NewApp()
+	app.Name = "temporal-yugabyte-tool"
+	app.Usage = "Command line tool for temporal yugabyte schema operations"
+	app.Flags = connectionFlags()
+	logger := log.NewCLILogger()
+
+	versionFlag := &cli.StringFlag{
+		Name:    flagVersion,
+		Aliases: []string{"v"},
+		Usage:   "schema version to update to (default: the version expected by this build)",
+	}
+
+	app.Commands = []*cli.Command{
+		{
+			Name:    "create-keyspace",
+			Aliases: []string{"create"},
+			Usage:   "create the keyspace, if it does not exist",
+			Flags: []cli.Flag{
+				&cli.IntFlag{
+					Name:    flagReplicationFactor,
+					Aliases: []string{"rf"},
+					Value:   1,
+					Usage:   "replication factor of the keyspace when no placement is given",
+				},
+				&cli.StringFlag{
+					Name:  flagPlacement,
+					Usage: "replicas per datacenter as dc1=3,dc2=2, which selects NetworkTopologyStrategy",
+				},
+			},
+			Action: cliHandler(createKeyspace, logger),
+		},
+		{
+			Name:   "setup",
+			Usage:  "create the schema version tables and apply every version of the embedded schema the keyspace lacks",
+			Flags:  []cli.Flag{versionFlag},
+			Action: cliHandler(setupSchema, logger),
+		},
+		{
+			Name:   "update",
+			Usage:  "apply the versions of the embedded schema newer than that of the keyspace",
+			Flags:  []cli.Flag{versionFlag},
+			Action: cliHandler(updateSchema, logger),
+		},
+		{
+			Name:  "dry-run",
+			Usage: "print the statements update would execute",
+			Flags: []cli.Flag{
+				versionFlag,
+				&cli.StringFlag{
+					Name:  flagFrom,
+					Usage: "current schema version, which avoids connecting to the cluster",
+				},
+			},
+			Action: cliHandler(dryRun, logger),
+		},
+		{
+			Name:   "check-drift",
+			Usage:  "compare the live schema of the keyspace with the one expected of its schema version",
+			Action: cliHandler(checkDrift, logger),
+		},
+		{
+			Name:   "migrate-task-queue-user-data",
+			Usage:  "move the task queue user data written before schema version 1.2 to task_queue_user_data_v2",
+			Action: cliHandler(migrateTaskQueueUserData, logger),
+		},
+		{
+			Name:   "validate-health",
+			Usage:  "check that the cluster accepts connections and queries",
+			Action: cliHandler(validateHealth, logger),
+		},
+		{
+			Name:   "version",
+			Usage:  "print the schema version of the keyspace and the embedded schema, failing if the keyspace is older",
+			Action: cliHandler(printVersion, logger),
+		},
+	}
+
+	return app
+}
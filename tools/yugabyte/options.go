@@ -0,0 +1,339 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package yugabyte
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"maps"
+	"strings"
+	"time"
+
+	temporal_yugabyte "github.com/manetu/temporal-yugabyte"
+	ybconfig "github.com/manetu/temporal-yugabyte/driver/config"
+	ybschema "github.com/manetu/temporal-yugabyte/schema/yugabyte"
+	"github.com/urfave/cli/v2"
+	"go.temporal.io/server/common/config"
+)
+
+const (
+	flagConfigDir                  = "config-dir"
+	flagEnv                        = "env"
+	flagZone                       = "zone"
+	flagStore                      = "store"
+	flagSchemaName                 = "schema-name"
+	flagEndpoint                   = "endpoint"
+	flagPort                       = "port"
+	flagUser                       = "user"
+	flagPassword                   = "password"
+	flagAllowedAuthenticators      = "allowed-authenticators"
+	flagKeyspace                   = "keyspace"
+	flagDatacenter                 = "datacenter"
+	flagTimeout                    = "timeout"
+	flagDisableInitialHostLookup   = "disable-initial-host-lookup"
+	flagTLS                        = "tls"
+	flagTLSCertFile                = "tls-cert-file"
+	flagTLSKeyFile                 = "tls-key-file"
+	flagTLSCaFile                  = "tls-ca-file"
+	flagTLSServerName              = "tls-server-name"
+	flagTLSDisableHostVerification = "tls-disable-host-verification"
+	flagAddressTranslator          = "address-translator"
+	flagAddressTranslatorOptions   = "address-translator-options"
+)
+
+const (
+	temporalSchemaName   = "temporal"
+	visibilitySchemaName = "visibility"
+
+	defaultTimeout = time.Minute
+)
+
+type (
+	// embeddedSchema is one of the versioned schemas built into the tool
+	embeddedSchema struct {
+		fsys            embed.FS
+		dir             string
+		version         string
+		defaultKeyspace string
+	}
+)
+
+var embeddedSchemas = map[string]embeddedSchema{
+	temporalSchemaName: {
+		fsys:            temporal_yugabyte.SchemaFs,
+		dir:             "schema/yugabyte/temporal/versioned",
+		version:         ybschema.Version,
+		defaultKeyspace: "temporal",
+	},
+	visibilitySchemaName: {
+		fsys:            temporal_yugabyte.VisibilitySchemaFs,
+		dir:             "schema/yugabyte/visibility/versioned",
+		version:         ybschema.VisibilityVersion,
+		defaultKeyspace: "temporal_visibility",
+	},
+}
+
+// versioned returns the versioned schema directories of the schema
+func (s embeddedSchema) versioned() (fs.FS, error) {
+	return fs.Sub(s.fsys, s.dir)
+}
+
+func lookupSchema(c *cli.Context) (embeddedSchema, error) {
+	name := c.String(flagSchemaName)
+	schema, ok := embeddedSchemas[name]
+	if !ok {
+		return embeddedSchema{}, fmt.Errorf("unknown schema %q, expected %s or %s", name, temporalSchemaName, visibilitySchemaName)
+	}
+	return schema, nil
+}
+
+// connectionFlags are the global flags describing how to connect to the cluster
+func connectionFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    flagConfigDir,
+			Usage:   "read connection settings from the datastore of the temporal server configuration in this directory",
+			EnvVars: []string{"TEMPORAL_CONFIG_DIR"},
+		},
+		&cli.StringFlag{
+			Name:    flagEnv,
+			Value:   "development",
+			Usage:   "runtime environment of the temporal server configuration",
+			EnvVars: []string{"TEMPORAL_ENVIRONMENT"},
+		},
+		&cli.StringFlag{
+			Name:    flagZone,
+			Usage:   "availability zone of the temporal server configuration",
+			EnvVars: []string{"TEMPORAL_AVAILABILITY_ZONE"},
+		},
+		&cli.StringFlag{
+			Name:  flagStore,
+			Usage: "datastore of the temporal server configuration (default: the default store, or the visibility store for the visibility schema)",
+		},
+		&cli.StringFlag{
+			Name:    flagSchemaName,
+			Value:   temporalSchemaName,
+			Usage:   fmt.Sprintf("embedded schema to operate on: %s or %s", temporalSchemaName, visibilitySchemaName),
+			EnvVars: []string{"YUGABYTE_SCHEMA_NAME"},
+		},
+		&cli.StringFlag{
+			Name:    flagEndpoint,
+			Aliases: []string{"ep"},
+			Value:   "127.0.0.1",
+			Usage:   "comma separated hostnames or ip addresses of yugabyte tservers",
+			EnvVars: []string{"YUGABYTE_HOST"},
+		},
+		&cli.IntFlag{
+			Name:    flagPort,
+			Aliases: []string{"p"},
+			Value:   9042,
+			Usage:   "YCQL port of the yugabyte tservers",
+			EnvVars: []string{"YUGABYTE_PORT"},
+		},
+		&cli.StringFlag{
+			Name:    flagUser,
+			Aliases: []string{"u"},
+			Usage:   "user name used for authentication",
+			EnvVars: []string{"YUGABYTE_USER"},
+		},
+		&cli.StringFlag{
+			Name:    flagPassword,
+			Aliases: []string{"pw"},
+			Usage:   "password used for authentication",
+			EnvVars: []string{"YUGABYTE_PASSWORD"},
+		},
+		&cli.StringSliceFlag{
+			Name:    flagAllowedAuthenticators,
+			Usage:   "authenticators the client may use when challenged by the server",
+			EnvVars: []string{"YUGABYTE_ALLOWED_AUTHENTICATORS"},
+		},
+		&cli.StringFlag{
+			Name:    flagKeyspace,
+			Aliases: []string{"k"},
+			Usage:   "name of the keyspace (default: temporal, or temporal_visibility for the visibility schema)",
+			EnvVars: []string{"YUGABYTE_KEYSPACE"},
+		},
+		&cli.StringFlag{
+			Name:    flagDatacenter,
+			Aliases: []string{"dc"},
+			Usage:   "connect only to the tservers of this datacenter",
+			EnvVars: []string{"YUGABYTE_DATACENTER"},
+		},
+		&cli.DurationFlag{
+			Name:    flagTimeout,
+			Value:   defaultTimeout,
+			Usage:   "timeout of each statement, which must allow for the backfill of indexes on populated tables",
+			EnvVars: []string{"YUGABYTE_TIMEOUT"},
+		},
+		&cli.BoolFlag{
+			Name:    flagDisableInitialHostLookup,
+			Usage:   "connect only to the given endpoints rather than discovering the other tservers",
+			EnvVars: []string{"YUGABYTE_DISABLE_INITIAL_HOST_LOOKUP"},
+		},
+		&cli.BoolFlag{
+			Name:    flagTLS,
+			Usage:   "enable TLS",
+			EnvVars: []string{"YUGABYTE_ENABLE_TLS"},
+		},
+		&cli.StringFlag{
+			Name:    flagTLSCertFile,
+			Usage:   "TLS client certificate file",
+			EnvVars: []string{"YUGABYTE_TLS_CERT"},
+		},
+		&cli.StringFlag{
+			Name:    flagTLSKeyFile,
+			Usage:   "TLS client key file",
+			EnvVars: []string{"YUGABYTE_TLS_KEY"},
+		},
+		&cli.StringFlag{
+			Name:    flagTLSCaFile,
+			Usage:   "TLS certificate authority file",
+			EnvVars: []string{"YUGABYTE_TLS_CA"},
+		},
+		&cli.StringFlag{
+			Name:    flagTLSServerName,
+			Usage:   "server name expected of the tserver certificates",
+			EnvVars: []string{"YUGABYTE_TLS_SERVER_NAME"},
+		},
+		&cli.BoolFlag{
+			Name:    flagTLSDisableHostVerification,
+			Usage:   "disable verification of the tserver certificates",
+			EnvVars: []string{"YUGABYTE_TLS_DISABLE_HOST_VERIFICATION"},
+		},
+		&cli.StringFlag{
+			Name:    flagAddressTranslator,
+			Usage:   "name of the address translator for the tservers",
+			EnvVars: []string{"YUGABYTE_ADDRESS_TRANSLATOR"},
+		},
+		&cli.StringFlag{
+			Name:    flagAddressTranslatorOptions,
+			Usage:   "options of the address translator as key1=value1,key2=value2",
+			EnvVars: []string{"YUGABYTE_ADDRESS_TRANSLATOR_OPTIONS"},
+		},
+	}
+}
+
+// newYugabyteConfig returns the connection settings given by the flags.  When a temporal server configuration is
+// given, the options of its datastore are the starting point and only the flags which were set explicitly replace
+// them, so that the tool connects exactly as the server does.
+func newYugabyteConfig(c *cli.Context, schema embeddedSchema) (ybconfig.Yugabyte, error) {
+	options := make(map[string]any)
+	if dir := c.String(flagConfigDir); dir != "" {
+		ds, err := loadDatastore(c, dir)
+		if err != nil {
+			return ybconfig.Yugabyte{}, err
+		}
+		maps.Copy(options, ds.Options)
+	}
+
+	overlay(c, options, flagEndpoint, "hosts", c.String(flagEndpoint))
+	overlay(c, options, flagPort, "port", c.Int(flagPort))
+	overlay(c, options, flagUser, "user", c.String(flagUser))
+	overlay(c, options, flagPassword, "password", c.String(flagPassword))
+	overlay(c, options, flagAllowedAuthenticators, "allowedAuthenticators", c.StringSlice(flagAllowedAuthenticators))
+	overlay(c, options, flagDatacenter, "datacenter", c.String(flagDatacenter))
+	overlay(c, options, flagTimeout, "timeout", c.Duration(flagTimeout))
+	overlay(c, options, flagDisableInitialHostLookup, "disableInitialHostLookup", c.Bool(flagDisableInitialHostLookup))
+
+	keyspace := c.String(flagKeyspace)
+	if keyspace == "" {
+		keyspace = schema.defaultKeyspace
+	}
+	overlay(c, options, flagKeyspace, "keyspace", keyspace)
+
+	if anySet(c, flagTLS, flagTLSCertFile, flagTLSKeyFile, flagTLSCaFile, flagTLSServerName, flagTLSDisableHostVerification) {
+		options["tls"] = map[string]any{
+			"enabled":                c.Bool(flagTLS),
+			"certFile":               c.String(flagTLSCertFile),
+			"keyFile":                c.String(flagTLSKeyFile),
+			"caFile":                 c.String(flagTLSCaFile),
+			"serverName":             c.String(flagTLSServerName),
+			"enableHostVerification": !c.Bool(flagTLSDisableHostVerification),
+		}
+	}
+
+	if c.IsSet(flagAddressTranslator) {
+		options["addressTranslator"] = map[string]any{
+			"translator": c.String(flagAddressTranslator),
+			"options":    parseOptionsMap(c.String(flagAddressTranslatorOptions)),
+		}
+	}
+
+	return ybconfig.ImportConfig(config.CustomDatastoreConfig{Options: options})
+}
+
+// loadDatastore returns the datastore selected by the flags from the temporal server configuration in dir
+func loadDatastore(c *cli.Context, dir string) (*config.CustomDatastoreConfig, error) {
+	cfg, err := config.LoadConfig(c.String(flagEnv), dir, c.String(flagZone))
+	if err != nil {
+		return nil, fmt.Errorf("unable to load temporal server configuration: %w", err)
+	}
+
+	name := c.String(flagStore)
+	if name == "" {
+		name = cfg.Persistence.DefaultStore
+		if c.String(flagSchemaName) == visibilitySchemaName {
+			name = cfg.Persistence.VisibilityStore
+		}
+	}
+	ds, ok := cfg.Persistence.DataStores[name]
+	if !ok {
+		return nil, fmt.Errorf("temporal server configuration has no datastore %q", name)
+	}
+	if ds.CustomDataStoreConfig == nil {
+		return nil, fmt.Errorf("datastore %q is not a yugabyte datastore", name)
+	}
+	return ds.CustomDataStoreConfig, nil
+}
+
+// overlay sets an option from a flag unless the option is already configured and the flag was left at its default
+func overlay(c *cli.Context, options map[string]any, flag string, key string, value any) {
+	if v, ok := options[key]; ok && v != nil && !c.IsSet(flag) {
+		return
+	}
+	options[key] = value
+}
+
+func anySet(c *cli.Context, flags ...string) bool {
+	for _, flag := range flags {
+		if c.IsSet(flag) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseOptionsMap parses options given as key1=value1,key2=value2
+func parseOptionsMap(value string) map[string]string {
+	result := make(map[string]string)
+	for _, item := range strings.Split(value, ",") {
+		key, val, ok := strings.Cut(item, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			continue
+		}
+		result[key] = strings.TrimSpace(val)
+	}
+	return result
+}
@@ -0,0 +1,113 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package yugabyte
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	ybconfig "github.com/manetu/temporal-yugabyte/driver/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func runWithConfig(t *testing.T, args ...string) (ybconfig.Yugabyte, error) {
+	var cfg ybconfig.Yugabyte
+	var cfgErr error
+	app := &cli.App{
+		Flags:  connectionFlags(),
+		Writer: io.Discard,
+		Action: func(c *cli.Context) error {
+			s, err := lookupSchema(c)
+			if err != nil {
+				cfgErr = err
+				return nil
+			}
+			cfg, cfgErr = newYugabyteConfig(c, s)
+			return nil
+		},
+	}
+	require.NoError(t, app.Run(append([]string{"temporal-yugabyte-tool"}, args...)))
+	return cfg, cfgErr
+}
+
+func TestNewYugabyteConfigDefaults(t *testing.T) {
+	cfg, err := runWithConfig(t)
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", cfg.Hosts)
+	assert.Equal(t, 9042, cfg.Port)
+	assert.Equal(t, "temporal", cfg.Keyspace)
+	assert.Equal(t, time.Minute, cfg.Timeout)
+	assert.False(t, cfg.TLS.Enabled)
+	assert.Nil(t, cfg.AddressTranslator)
+
+	cfg, err = runWithConfig(t, "--schema-name", "visibility")
+	require.NoError(t, err)
+	assert.Equal(t, "temporal_visibility", cfg.Keyspace)
+
+	_, err = runWithConfig(t, "--schema-name", "history")
+	assert.ErrorContains(t, err, `unknown schema "history"`)
+}
+
+func TestNewYugabyteConfigFlags(t *testing.T) {
+	cfg, err := runWithConfig(t,
+		"--ep", "yb-1,yb-2",
+		"-p", "9043",
+		"-k", "temporal2",
+		"--timeout", "5m",
+		"--tls",
+		"--tls-ca-file", "/certs/ca.crt",
+		"--tls-server-name", "yb.example.com",
+		"--address-translator", "fixed-address-translator",
+		"--address-translator-options", "advertised-hostname=yb.example.com, port=9044",
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "yb-1,yb-2", cfg.Hosts)
+	assert.Equal(t, 9043, cfg.Port)
+	assert.Equal(t, "temporal2", cfg.Keyspace)
+	assert.Equal(t, 5*time.Minute, cfg.Timeout)
+	assert.True(t, cfg.TLS.Enabled)
+	assert.True(t, cfg.TLS.EnableHostVerification)
+	assert.Equal(t, "/certs/ca.crt", cfg.TLS.CaFile)
+	assert.Equal(t, "yb.example.com", cfg.TLS.ServerName)
+	require.NotNil(t, cfg.AddressTranslator)
+	assert.Equal(t, "fixed-address-translator", cfg.AddressTranslator.Translator)
+	assert.Equal(t, map[string]string{"advertised-hostname": "yb.example.com", "port": "9044"}, cfg.AddressTranslator.Options)
+}
+
+func TestOverlay(t *testing.T) {
+	app := &cli.App{
+		Flags: connectionFlags(),
+		Action: func(c *cli.Context) error {
+			options := map[string]any{"hosts": "yb-config", "port": 9100}
+			overlay(c, options, flagEndpoint, "hosts", c.String(flagEndpoint))
+			overlay(c, options, flagPort, "port", c.Int(flagPort))
+			overlay(c, options, flagUser, "user", c.String(flagUser))
+			assert.Equal(t, map[string]any{"hosts": "yb-config", "port": 9200, "user": ""}, options)
+			return nil
+		},
+	}
+	require.NoError(t, app.Run([]string{"temporal-yugabyte-tool", "-p", "9200"}))
+}
@@ -0,0 +1,153 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package yugabyte
+
+import (
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strings"
+
+	ybschema "github.com/manetu/temporal-yugabyte/schema/yugabyte"
+)
+
+// initialVersion is the version of a keyspace to which no versioned schema has been applied
+const initialVersion = "0.0"
+
+type (
+	// changeSet holds the statements which move a keyspace to the version of a manifest
+	changeSet struct {
+		version  string
+		manifest *ybschema.Manifest
+		stmts    []string
+	}
+
+	// statementValidator checks the statements of successive versions against the requirements of YCQL.  It carries
+	// the tables created by earlier versions so that the indexes of later versions can be checked against them.
+	statementValidator struct {
+		transactional map[string]bool
+	}
+)
+
+var (
+	allowedStatementPrefixes = []string{"CREATE", "ALTER", "DROP", "INSERT"}
+
+	createTableRegex     = regexp.MustCompile(`(?is)^CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?([\w.]+)`)
+	createIndexRegex     = regexp.MustCompile(`(?is)^CREATE\s+(?:UNIQUE\s+)?INDEX\s+(?:IF\s+NOT\s+EXISTS\s+)?[\w.]+\s+ON\s+([\w.]+)`)
+	dropTableRegex       = regexp.MustCompile(`(?is)^DROP\s+TABLE\s+(?:IF\s+EXISTS\s+)?([\w.]+)`)
+	transactionsRegex    = regexp.MustCompile(`(?is)\bWITH\b.*\btransactions\s*=\s*\{\s*'enabled'\s*:\s*true\s*}`)
+	userEnforcedIndexRgx = regexp.MustCompile(`(?is)\bWITH\b.*\btransactions\s*=\s*\{[^}]*'consistency_level'\s*:\s*'user_enforced'`)
+)
+
+// buildChangeSets returns the change sets which move a keyspace from currVer to targetVer.  Every version up to
+// targetVer is read and validated, including those already applied, since the indexes of a pending version may refer
+// to the tables of an applied one.
+func buildChangeSets(fsys fs.FS, currVer string, targetVer string) ([]changeSet, error) {
+	curr, err := ybschema.ParseVersion(currVer)
+	if err != nil {
+		return nil, err
+	}
+	target, err := ybschema.ParseVersion(targetVer)
+	if err != nil {
+		return nil, err
+	}
+	if target.LT(curr) {
+		return nil, fmt.Errorf("target version %s is older than the current version %s", targetVer, currVer)
+	}
+	if target.EQ(curr) {
+		return nil, nil
+	}
+
+	validator := newStatementValidator()
+	var result []changeSet
+	err = ybschema.WalkVersions(fsys, targetVer, func(version string, m *ybschema.Manifest, stmts []string) error {
+		if err := validator.validate(ybschema.VersionDir(version), stmts); err != nil {
+			return err
+		}
+		if parsed, _ := ybschema.ParseVersion(version); parsed.GT(curr) {
+			result = append(result, changeSet{
+				version:  version,
+				manifest: m,
+				stmts:    stmts,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func newStatementValidator() *statementValidator {
+	return &statementValidator{
+		transactional: make(map[string]bool),
+	}
+}
+
+// validate checks that the statements of dir may be applied by the tool.  Only DDL and inserts are allowed, every
+// table must be created with transactions enabled so that the driver's multi-row updates are atomic, and indexes may
+// only be created on transactional tables since only those are backfilled consistently while being written to.
+func (v *statementValidator) validate(dir string, stmts []string) error {
+	for _, stmt := range stmts {
+		upper := strings.ToUpper(stmt)
+		allowed := false
+		for _, prefix := range allowedStatementPrefixes {
+			if strings.HasPrefix(upper, prefix) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("%s: statement is not DDL or an insert: %s", dir, summarize(stmt))
+		}
+
+		if match := createTableRegex.FindStringSubmatch(stmt); match != nil {
+			table := strings.ToLower(match[1])
+			if !transactionsRegex.MatchString(stmt) {
+				return fmt.Errorf("%s: table %s must be created WITH transactions = { 'enabled' : true }", dir, table)
+			}
+			v.transactional[table] = true
+			continue
+		}
+		if match := createIndexRegex.FindStringSubmatch(stmt); match != nil {
+			table := strings.ToLower(match[1])
+			if !v.transactional[table] && !userEnforcedIndexRgx.MatchString(stmt) {
+				return fmt.Errorf("%s: index on %s requires a table created with transactions enabled", dir, table)
+			}
+			continue
+		}
+		if match := dropTableRegex.FindStringSubmatch(stmt); match != nil {
+			delete(v.transactional, strings.ToLower(match[1]))
+		}
+	}
+	return nil
+}
+
+// summarize returns the first line of a statement for use in error messages
+func summarize(stmt string) string {
+	if i := strings.IndexByte(stmt, '\n'); i >= 0 {
+		return stmt[:i] + " ..."
+	}
+	return stmt
+}
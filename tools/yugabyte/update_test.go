@@ -0,0 +1,146 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package yugabyte
+
+import (
+	"testing"
+	"testing/fstest"
+
+	ybschema "github.com/manetu/temporal-yugabyte/schema/yugabyte"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testVersionedSchema() fstest.MapFS {
+	return fstest.MapFS{
+		"v1.0/manifest.json": {Data: []byte(`{"CurrVersion": "1.0", "MinCompatibleVersion": "1.0", "Description": "initial", "SchemaUpdateCqlFiles": ["schema.cql"]}`)},
+		"v1.0/schema.cql": {Data: []byte(
+			"CREATE TABLE shards (shard_id int, PRIMARY KEY (shard_id)) WITH transactions = { 'enabled' : true };\n",
+		)},
+		"v1.10/manifest.json": {Data: []byte(`{"CurrVersion": "1.10", "MinCompatibleVersion": "1.0", "Description": "index", "SchemaUpdateCqlFiles": ["index.cql"]}`)},
+		"v1.10/index.cql": {Data: []byte(
+			"-- backfilled online\nCREATE INDEX shards_by_range_idx ON shards (range_id);\n",
+		)},
+		"v1.2/manifest.json": {Data: []byte(`{"CurrVersion": "1.2", "MinCompatibleVersion": "1.0", "Description": "column", "SchemaUpdateCqlFiles": ["column.cql"]}`)},
+		"v1.2/column.cql": {Data: []byte(
+			"ALTER TABLE shards ADD range_id bigint;\n",
+		)},
+		"README.md": {Data: []byte("not a version")},
+	}
+}
+
+func TestBuildChangeSets(t *testing.T) {
+	fsys := testVersionedSchema()
+
+	changeSets, err := buildChangeSets(fsys, initialVersion, "1.10")
+	require.NoError(t, err)
+	require.Len(t, changeSets, 3)
+	assert.Equal(t, "1.0", changeSets[0].version)
+	assert.Equal(t, "1.10", changeSets[2].version)
+	assert.Equal(t, []string{"CREATE INDEX shards_by_range_idx ON shards (range_id);"}, changeSets[2].stmts)
+	assert.Len(t, changeSets[2].manifest.MD5, 32)
+
+	changeSets, err = buildChangeSets(fsys, "1.0", "1.2")
+	require.NoError(t, err)
+	require.Len(t, changeSets, 1)
+	assert.Equal(t, "1.2", changeSets[0].version)
+
+	changeSets, err = buildChangeSets(fsys, "1.10", "1.10")
+	require.NoError(t, err)
+	assert.Empty(t, changeSets)
+
+	_, err = buildChangeSets(fsys, "1.2", "1.0")
+	assert.ErrorContains(t, err, "older than the current version")
+	_, err = buildChangeSets(fsys, initialVersion, "1.5")
+	assert.ErrorContains(t, err, "no version 1.5")
+}
+
+func TestBuildChangeSetsRejectsMismatchedManifest(t *testing.T) {
+	fsys := testVersionedSchema()
+	fsys["v1.2/manifest.json"] = &fstest.MapFile{Data: []byte(`{"CurrVersion": "1.3", "MinCompatibleVersion": "1.0", "SchemaUpdateCqlFiles": ["column.cql"]}`)}
+
+	_, err := buildChangeSets(fsys, initialVersion, "1.10")
+	assert.ErrorContains(t, err, `v1.2 has CurrVersion "1.3"`)
+}
+
+func TestStatementValidator(t *testing.T) {
+	v := newStatementValidator()
+	require.NoError(t, v.validate("v1.0", []string{
+		"CREATE TABLE executions (shard_id int, PRIMARY KEY (shard_id)) WITH transactions = { 'enabled' : true };",
+		"CREATE TYPE serialized_event_batch (data blob);",
+		"CREATE INDEX executions_idx on executions (shard_id);",
+		"CREATE INDEX legacy_idx ON legacy (id) WITH transactions = { 'enabled' : false, 'consistency_level' : 'user_enforced' };",
+		"DROP INDEX IF EXISTS executions_idx;",
+	}))
+
+	err := v.validate("v1.1", []string{"CREATE TABLE shards (shard_id int, PRIMARY KEY (shard_id));"})
+	assert.ErrorContains(t, err, "table shards must be created WITH transactions")
+
+	err = v.validate("v1.1", []string{"CREATE INDEX shards_idx ON shards (range_id);"})
+	assert.ErrorContains(t, err, "index on shards requires a table created with transactions enabled")
+
+	err = v.validate("v1.1", []string{"SELECT * FROM executions;"})
+	assert.ErrorContains(t, err, "not DDL or an insert")
+
+	require.NoError(t, v.validate("v1.1", []string{"DROP TABLE IF EXISTS executions;"}))
+	err = v.validate("v1.2", []string{"CREATE INDEX executions_idx ON executions (shard_id);"})
+	assert.ErrorContains(t, err, "index on executions")
+}
+
+func TestEmbeddedSchemas(t *testing.T) {
+	for name, s := range embeddedSchemas {
+		t.Run(name, func(t *testing.T) {
+			fsys, err := s.versioned()
+			require.NoError(t, err)
+
+			latest, err := ybschema.LatestVersion(fsys)
+			require.NoError(t, err)
+			assert.Equal(t, s.version, latest, "the newest versioned directory must match the version expected by the driver")
+
+			changeSets, err := buildChangeSets(fsys, initialVersion, s.version)
+			require.NoError(t, err)
+			require.NotEmpty(t, changeSets)
+			for _, cs := range changeSets {
+				assert.NotEmpty(t, cs.stmts, cs.version)
+				assert.NotEmpty(t, cs.manifest.Description, cs.version)
+			}
+		})
+	}
+}
+
+func TestParsePlacement(t *testing.T) {
+	p, err := parsePlacement("", 3)
+	require.NoError(t, err)
+	assert.Equal(t, "{ 'class' : 'SimpleStrategy', 'replication_factor' : 3 }", p.String())
+
+	p, err = parsePlacement("us-west=3, us-east = 2", 1)
+	require.NoError(t, err)
+	assert.Equal(t, "{ 'class' : 'NetworkTopologyStrategy', 'us-east' : 2, 'us-west' : 3 }", p.String())
+
+	for _, invalid := range []string{"us-west", "us-west=0", "=3", "us-west=3,us-west=1", "us-west=three"} {
+		_, err = parsePlacement(invalid, 1)
+		assert.Error(t, err, invalid)
+	}
+	_, err = parsePlacement("", 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,136 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	ybconfig "github.com/manetu/temporal-yugabyte/driver/config"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	commandProviderName           = "command"
+	commandKey                    = "command"
+	commandTimeoutKey             = "timeout"
+	commandRefreshIntervalKey     = "refreshInterval"
+	defaultCommandTimeout         = 10 * time.Second
+	defaultCommandRefreshInterval = 5 * time.Minute
+)
+
+func init() {
+	RegisterProvider(commandProviderName, NewCommandProviderPlugin())
+}
+
+type (
+	CommandProviderPlugin struct {
+	}
+
+	commandProvider struct {
+		args            []string
+		timeout         time.Duration
+		refreshInterval time.Duration
+
+		sync.Mutex
+		password  string
+		fetchedAt time.Time
+	}
+)
+
+func NewCommandProviderPlugin() ProviderPlugin {
+	return &CommandProviderPlugin{}
+}
+
+// GetProvider returns a provider which obtains the password from the standard output of an external command, for
+// example a secrets manager CLI.  The "command" option is split on whitespace and executed without a shell.  The
+// password is cached for "refreshInterval" (default 5m) and the command is abandoned after "timeout" (default 10s).
+func (plugin *CommandProviderPlugin) GetProvider(cfg *ybconfig.Yugabyte) (Provider, error) {
+	if cfg.Credentials == nil {
+		return nil, errors.New("there is no credentials configuration in yugabyte configuration")
+	}
+
+	opts := cfg.Credentials.Options
+	args := strings.Fields(opts[commandKey])
+	if len(args) == 0 {
+		return nil, errors.New("detected no command key or empty value for command credential provider options")
+	}
+
+	timeout, err := durationOption(opts, commandTimeoutKey, defaultCommandTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshInterval, err := durationOption(opts, commandRefreshIntervalKey, defaultCommandRefreshInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	return &commandProvider{
+		args:            args,
+		timeout:         timeout,
+		refreshInterval: refreshInterval,
+	}, nil
+}
+
+func (p *commandProvider) Password() (string, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	if !p.fetchedAt.IsZero() && time.Since(p.fetchedAt) < p.refreshInterval {
+		return p.password, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.args[0], p.args[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("credentials command %q failed: %w: %s", p.args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	p.password = strings.TrimRight(stdout.String(), "\r\n")
+	p.fetchedAt = time.Now()
+
+	return p.password, nil
+}
+
+func durationOption(opts map[string]string, key string, defaultValue time.Duration) (time.Duration, error) {
+	v, ok := opts[key]
+	if !ok {
+		return defaultValue, nil
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q for credential provider: %w", key, v, err)
+	}
+	return d, nil
+}
@@ -0,0 +1,99 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package credentials
+
+import (
+	"errors"
+	"fmt"
+	ybconfig "github.com/manetu/temporal-yugabyte/driver/config"
+
+	"github.com/yugabyte/gocql"
+)
+
+var (
+	ErrInvalidProviderPluginName = errors.New("credentials_plugin: invalid credential provider plugin requested")
+	providers                    = map[string]ProviderPlugin{}
+)
+
+type (
+	// Provider supplies the password used to authenticate with Yugabyte.  Implementations must be safe
+	// for concurrent use as the driver authenticates connections from several goroutines.  A session rejected
+	// for its credentials is rebuilt with a new provider, which consults the source afresh.
+	Provider interface {
+		// Password returns the current password, consulting the underlying source as required
+		Password() (string, error)
+	}
+
+	// ProviderPlugin interface for credential provider mechanisms
+	ProviderPlugin interface {
+		GetProvider(*ybconfig.Yugabyte) (Provider, error)
+	}
+
+	authenticator struct {
+		username              string
+		allowedAuthenticators []string
+		provider              Provider
+	}
+)
+
+// RegisterProvider adds a credential provider plugin to the plugin registry
+// it is only safe to use from a package init function
+func RegisterProvider(name string, plugin ProviderPlugin) {
+	providers[name] = plugin
+}
+
+func LookupProvider(name string) (ProviderPlugin, error) {
+	plugin, ok := providers[name]
+	if !ok {
+		return nil, ErrInvalidProviderPluginName
+	}
+
+	return plugin, nil
+}
+
+// NewAuthenticator returns a gocql.Authenticator that obtains the password from the provider each time a
+// connection authenticates, so that new connections pick up rotated credentials
+func NewAuthenticator(username string, allowedAuthenticators []string, provider Provider) gocql.Authenticator {
+	return &authenticator{
+		username:              username,
+		allowedAuthenticators: allowedAuthenticators,
+		provider:              provider,
+	}
+}
+
+func (a *authenticator) Challenge(req []byte) ([]byte, gocql.Authenticator, error) {
+	password, err := a.provider.Password()
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to obtain yugabyte credentials: %w", err)
+	}
+
+	return gocql.PasswordAuthenticator{
+		Username:              a.username,
+		Password:              password,
+		AllowedAuthenticators: a.allowedAuthenticators,
+	}.Challenge(req)
+}
+
+func (a *authenticator) Success(_ []byte) error {
+	return nil
+}
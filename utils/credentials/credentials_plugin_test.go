@@ -0,0 +1,145 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package credentials
+
+import (
+	ybconfig "github.com/manetu/temporal-yugabyte/driver/config"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type (
+	credentialsPluginTestSuite struct {
+		suite.Suite
+	}
+)
+
+func TestCredentialsPluginTestSuite(t *testing.T) {
+	s := new(credentialsPluginTestSuite)
+	suite.Run(t, s)
+}
+
+func newConfig(provider string, options map[string]string) *ybconfig.Yugabyte {
+	return &ybconfig.Yugabyte{
+		User: "yugabyte",
+		Credentials: &ybconfig.YugabyteCredentials{
+			Provider: provider,
+			Options:  options,
+		},
+	}
+}
+
+func (s *credentialsPluginTestSuite) getProvider(cfg *ybconfig.Yugabyte) Provider {
+	plugin, err := LookupProvider(cfg.Credentials.Provider)
+	s.Require().NoError(err)
+	provider, err := plugin.GetProvider(cfg)
+	s.Require().NoError(err)
+	return provider
+}
+
+func (s *credentialsPluginTestSuite) TestUnknownProvider() {
+	_, err := LookupProvider("vault")
+	s.ErrorIs(err, ErrInvalidProviderPluginName)
+}
+
+func (s *credentialsPluginTestSuite) TestEnvProvider() {
+	s.T().Setenv("TEST_YUGABYTE_PASSWORD", "first")
+	provider := s.getProvider(newConfig(envProviderName, map[string]string{envVarKey: "TEST_YUGABYTE_PASSWORD"}))
+
+	password, err := provider.Password()
+	s.NoError(err)
+	s.Equal("first", password)
+
+	s.T().Setenv("TEST_YUGABYTE_PASSWORD", "second")
+	password, err = provider.Password()
+	s.NoError(err)
+	s.Equal("second", password)
+}
+
+func (s *credentialsPluginTestSuite) TestEnvProviderUnset() {
+	provider := s.getProvider(newConfig(envProviderName, map[string]string{envVarKey: "TEST_YUGABYTE_PASSWORD_UNSET"}))
+
+	_, err := provider.Password()
+	s.Error(err)
+}
+
+func (s *credentialsPluginTestSuite) TestFileProviderRotation() {
+	path := filepath.Join(s.T().TempDir(), "password")
+	s.Require().NoError(os.WriteFile(path, []byte("first\n"), 0600))
+
+	cfg := newConfig(fileProviderName, map[string]string{
+		filePathKey:         path,
+		filePollIntervalKey: "1h",
+	})
+	provider := s.getProvider(cfg)
+
+	password, err := provider.Password()
+	s.NoError(err)
+	s.Equal("first", password)
+
+	// a rotated secret is not observed until the poll interval elapses or the session is rebuilt with a new provider
+	s.Require().NoError(os.WriteFile(path, []byte("second-password\n"), 0600))
+	password, err = provider.Password()
+	s.NoError(err)
+	s.Equal("first", password)
+
+	password, err = s.getProvider(cfg).Password()
+	s.NoError(err)
+	s.Equal("second-password", password)
+}
+
+func (s *credentialsPluginTestSuite) TestFileProviderMissingPath() {
+	plugin, err := LookupProvider(fileProviderName)
+	s.Require().NoError(err)
+
+	_, err = plugin.GetProvider(newConfig(fileProviderName, nil))
+	s.Error(err)
+}
+
+func (s *credentialsPluginTestSuite) TestCommandProvider() {
+	provider := s.getProvider(newConfig(commandProviderName, map[string]string{commandKey: "echo secret"}))
+
+	password, err := provider.Password()
+	s.NoError(err)
+	s.Equal("secret", password)
+}
+
+func (s *credentialsPluginTestSuite) TestCommandProviderFailure() {
+	provider := s.getProvider(newConfig(commandProviderName, map[string]string{commandKey: "false"}))
+
+	_, err := provider.Password()
+	s.Error(err)
+}
+
+func (s *credentialsPluginTestSuite) TestAuthenticator() {
+	s.T().Setenv("TEST_YUGABYTE_PASSWORD", "secret")
+	provider := s.getProvider(newConfig(envProviderName, map[string]string{envVarKey: "TEST_YUGABYTE_PASSWORD"}))
+
+	authenticator := NewAuthenticator("yugabyte", nil, provider)
+	resp, _, err := authenticator.Challenge([]byte("org.apache.cassandra.auth.PasswordAuthenticator"))
+	s.NoError(err)
+	s.Equal("\x00yugabyte\x00secret", string(resp))
+}
@@ -0,0 +1,76 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package credentials
+
+import (
+	"errors"
+	"fmt"
+	ybconfig "github.com/manetu/temporal-yugabyte/driver/config"
+	"os"
+)
+
+const (
+	envProviderName    = "env"
+	envVarKey          = "name"
+	defaultPasswordEnv = "YUGABYTE_PASSWORD"
+)
+
+func init() {
+	RegisterProvider(envProviderName, NewEnvProviderPlugin())
+}
+
+type (
+	EnvProviderPlugin struct {
+	}
+
+	envProvider struct {
+		name string
+	}
+)
+
+func NewEnvProviderPlugin() ProviderPlugin {
+	return &EnvProviderPlugin{}
+}
+
+// GetProvider returns a provider which reads the password from an environment variable, YUGABYTE_PASSWORD
+// unless the "name" option says otherwise
+func (plugin *EnvProviderPlugin) GetProvider(cfg *ybconfig.Yugabyte) (Provider, error) {
+	if cfg.Credentials == nil {
+		return nil, errors.New("there is no credentials configuration in yugabyte configuration")
+	}
+
+	name := cfg.Credentials.Options[envVarKey]
+	if name == "" {
+		name = defaultPasswordEnv
+	}
+
+	return &envProvider{name: name}, nil
+}
+
+func (p *envProvider) Password() (string, error) {
+	password, ok := os.LookupEnv(p.name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %q is not set", p.name)
+	}
+	return password, nil
+}
@@ -0,0 +1,117 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package credentials
+
+import (
+	"errors"
+	"fmt"
+	ybconfig "github.com/manetu/temporal-yugabyte/driver/config"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	fileProviderName        = "file"
+	filePathKey             = "path"
+	filePollIntervalKey     = "pollInterval"
+	defaultFilePollInterval = 10 * time.Second
+)
+
+func init() {
+	RegisterProvider(fileProviderName, NewFileProviderPlugin())
+}
+
+type (
+	FileProviderPlugin struct {
+	}
+
+	fileProvider struct {
+		path         string
+		pollInterval time.Duration
+
+		sync.Mutex
+		password    string
+		modTime     time.Time
+		size        int64
+		lastChecked time.Time
+	}
+)
+
+func NewFileProviderPlugin() ProviderPlugin {
+	return &FileProviderPlugin{}
+}
+
+// GetProvider returns a provider which reads the password from a file such as a mounted Kubernetes secret.  The
+// file is checked for changes at most once per "pollInterval" (default 10s) and re-read whenever its modification
+// time or size changes, so a rotated secret is picked up by the next connection to authenticate.
+func (plugin *FileProviderPlugin) GetProvider(cfg *ybconfig.Yugabyte) (Provider, error) {
+	if cfg.Credentials == nil {
+		return nil, errors.New("there is no credentials configuration in yugabyte configuration")
+	}
+
+	opts := cfg.Credentials.Options
+	path := opts[filePathKey]
+	if path == "" {
+		return nil, errors.New("detected no path key or empty value for file credential provider options")
+	}
+
+	pollInterval, err := durationOption(opts, filePollIntervalKey, defaultFilePollInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fileProvider{
+		path:         path,
+		pollInterval: pollInterval,
+	}, nil
+}
+
+func (p *fileProvider) Password() (string, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	now := time.Now()
+	if !p.lastChecked.IsZero() && now.Sub(p.lastChecked) < p.pollInterval {
+		return p.password, nil
+	}
+
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return "", fmt.Errorf("unable to stat credentials file: %w", err)
+	}
+
+	if p.lastChecked.IsZero() || !info.ModTime().Equal(p.modTime) || info.Size() != p.size {
+		contents, err := os.ReadFile(p.path)
+		if err != nil {
+			return "", fmt.Errorf("unable to read credentials file: %w", err)
+		}
+		p.password = strings.TrimRight(string(contents), "\r\n")
+		p.modTime = info.ModTime()
+		p.size = info.Size()
+	}
+	p.lastChecked = now
+
+	return p.password, nil
+}
@@ -35,6 +35,7 @@ import (
 type (
 	Batch struct {
 		session *session
+		scope   *statementScope
 
 		gocqlBatch *gocql.Batch
 	}
@@ -49,10 +50,12 @@ const (
 
 func newBatch(
 	session *session,
+	scope *statementScope,
 	gocqlBatch *gocql.Batch,
 ) *Batch {
 	return &Batch{
 		session:    session,
+		scope:      scope,
 		gocqlBatch: gocqlBatch,
 	}
 }
@@ -62,12 +65,28 @@ func (b *Batch) Query(stmt string, args ...interface{}) {
 }
 
 func (b *Batch) WithContext(ctx context.Context) *Batch {
-	return newBatch(b.session, b.gocqlBatch.WithContext(ctx))
+	return newBatch(b.session, b.scope, b.gocqlBatch.WithContext(ctx))
 }
 
 func (b *Batch) WithTimestamp(timestamp int64) *Batch {
 	b.gocqlBatch.WithTimestamp(timestamp)
-	return newBatch(b.session, b.gocqlBatch)
+	return newBatch(b.session, b.scope, b.gocqlBatch)
+}
+
+func (b *Batch) statementInfo() statementInfo {
+	stmts := make([]string, 0, len(b.gocqlBatch.Entries))
+	var values []interface{}
+	for _, entry := range b.gocqlBatch.Entries {
+		stmts = append(stmts, entry.Stmt)
+		values = append(values, entry.Args...)
+	}
+	return statementInfo{
+		kind:       batchStatementKind,
+		keyspace:   b.gocqlBatch.Keyspace(),
+		summary:    summarizeStatements(stmts),
+		statements: len(stmts),
+		values:     values,
+	}
 }
 
 func mustConvertBatchType(batchType BatchType) gocql.BatchType {
@@ -27,13 +27,11 @@ package gocql
 
 import (
 	"crypto/tls"
-	"crypto/x509"
-	"encoding/base64"
 	"errors"
 	"fmt"
 	ybconfig "github.com/manetu/temporal-yugabyte/driver/config"
+	"github.com/manetu/temporal-yugabyte/utils/credentials"
 	"github.com/manetu/temporal-yugabyte/utils/translator"
-	"os"
 	"strings"
 	"time"
 
@@ -68,7 +66,17 @@ func ConfigureYugabyteCluster(cfg ybconfig.Yugabyte, cluster *gocql.ClusterConfi
 	if cfg.Port > 0 {
 		cluster.Port = cfg.Port
 	}
-	if cfg.User != "" && cfg.Password != "" {
+	if cfg.User != "" && cfg.Credentials != nil {
+		plugin, err := credentials.LookupProvider(cfg.Credentials.Provider)
+		if err != nil {
+			return err
+		}
+		provider, err := plugin.GetProvider(&cfg)
+		if err != nil {
+			return fmt.Errorf("unable to create credential provider: %w", err)
+		}
+		cluster.Authenticator = credentials.NewAuthenticator(cfg.User, cfg.AllowedAuthenticators, provider)
+	} else if cfg.User != "" && cfg.Password != "" {
 		cluster.Authenticator = gocql.PasswordAuthenticator{
 			Username:              cfg.User,
 			Password:              cfg.Password,
@@ -81,6 +89,7 @@ func ConfigureYugabyteCluster(cfg ybconfig.Yugabyte, cluster *gocql.ClusterConfi
 	if cfg.Datacenter != "" {
 		cluster.HostFilter = gocql.DataCentreHostFilter(cfg.Datacenter)
 	}
+	var reloader *certificateReloader
 	if cfg.TLS != nil && cfg.TLS.Enabled {
 		if cfg.TLS.CertData != "" && cfg.TLS.CertFile != "" {
 			return errors.New("only one of certData or certFile properties should be specified")
@@ -94,57 +103,33 @@ func ConfigureYugabyteCluster(cfg ybconfig.Yugabyte, cluster *gocql.ClusterConfi
 			return errors.New("only one of caData or caFile properties should be specified")
 		}
 
-		cluster.SslOpts = &gocql.SslOptions{
-			CaPath:                 cfg.TLS.CaFile,
-			EnableHostVerification: cfg.TLS.EnableHostVerification,
-			Config:                 auth.NewTLSConfigForServer(cfg.TLS.ServerName, cfg.TLS.EnableHostVerification),
-		}
-
-		var certBytes []byte
-		var keyBytes []byte
-		var err error
-
-		if cfg.TLS.CertFile != "" {
-			certBytes, err = os.ReadFile(cfg.TLS.CertFile)
+		if usesCertificateFiles(cfg.TLS) {
+			// material read from files may be rotated on disk, so it is served to each new connection by the reloader
+			var err error
+			reloader, err = newCertificateReloader(cfg.TLS)
 			if err != nil {
-				return fmt.Errorf("error reading client certificate file: %w", err)
+				return err
 			}
-		} else if cfg.TLS.CertData != "" {
-			certBytes, err = base64.StdEncoding.DecodeString(cfg.TLS.CertData)
-			if err != nil {
-				return fmt.Errorf("client certificate could not be decoded: %w", err)
+		} else {
+			cluster.SslOpts = &gocql.SslOptions{
+				EnableHostVerification: cfg.TLS.EnableHostVerification,
+				Config:                 auth.NewTLSConfigForServer(cfg.TLS.ServerName, cfg.TLS.EnableHostVerification),
 			}
-		}
 
-		if cfg.TLS.KeyFile != "" {
-			keyBytes, err = os.ReadFile(cfg.TLS.KeyFile)
+			clientCert, err := readClientCertificate(cfg.TLS)
 			if err != nil {
-				return fmt.Errorf("error reading client certificate private key file: %w", err)
+				return err
 			}
-		} else if cfg.TLS.KeyData != "" {
-			keyBytes, err = base64.StdEncoding.DecodeString(cfg.TLS.KeyData)
-			if err != nil {
-				return fmt.Errorf("client certificate private key could not be decoded: %w", err)
+			if clientCert != nil {
+				cluster.SslOpts.Certificates = []tls.Certificate{*clientCert}
 			}
-		}
 
-		if len(certBytes) > 0 {
-			clientCert, err := tls.X509KeyPair(certBytes, keyBytes)
+			rootCAs, _, err := readRootCAs(cfg.TLS)
 			if err != nil {
-				return fmt.Errorf("unable to generate x509 key pair: %w", err)
+				return err
 			}
-
-			cluster.SslOpts.Certificates = []tls.Certificate{clientCert}
-		}
-
-		if cfg.TLS.CaData != "" {
-			cluster.SslOpts.RootCAs = x509.NewCertPool()
-			pem, err := base64.StdEncoding.DecodeString(cfg.TLS.CaData)
-			if err != nil {
-				return fmt.Errorf("caData could not be decoded: %w", err)
-			}
-			if !cluster.SslOpts.RootCAs.AppendCertsFromPEM(pem) {
-				return errors.New("failed to load decoded CA Cert as PEM")
+			if rootCAs != nil {
+				cluster.SslOpts.RootCAs = rootCAs
 			}
 		}
 	}
@@ -169,8 +154,8 @@ func ConfigureYugabyteCluster(cfg ybconfig.Yugabyte, cluster *gocql.ClusterConfi
 	}
 
 	cluster.ProtoVersion = 4
-	cluster.Consistency = gocql.LocalQuorum
-	cluster.SerialConsistency = gocql.LocalSerial
+	cluster.Consistency = cfg.Consistency.GetConsistency()
+	cluster.SerialConsistency = cfg.Consistency.GetSerialConsistency()
 	cluster.DisableInitialHostLookup = cfg.DisableInitialHostLookup
 
 	cluster.ReconnectionPolicy = &gocql.ExponentialReconnectionPolicy{
@@ -184,6 +169,11 @@ func ConfigureYugabyteCluster(cfg ybconfig.Yugabyte, cluster *gocql.ClusterConfi
 
 	cluster.PoolConfig.HostSelectionPolicy = gocql.YBPartitionAwareHostPolicy(gocql.RoundRobinHostPolicy())
 
+	if reloader != nil {
+		reloader.setDialer(cluster)
+		cluster.HostDialer = reloader
+	}
+
 	if cfg.AddressTranslator != nil && cfg.AddressTranslator.Translator != "" {
 		addressTranslator, err := translator.LookupTranslator(cfg.AddressTranslator.Translator)
 		if err != nil {
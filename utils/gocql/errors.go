@@ -37,6 +37,101 @@ import (
 	"go.temporal.io/server/common/persistence"
 )
 
+type (
+	ybErrorKind int
+
+	// ybErrorRule maps YugabyteDB failures, identified by case-insensitive fragments of their message, onto the
+	// Temporal error type that drives the appropriate retry and backoff behaviour
+	ybErrorRule struct {
+		name     string
+		messages []string
+		kind     ybErrorKind
+	}
+)
+
+const (
+	// ybUnavailable failures were not applied and may be retried promptly
+	ybUnavailable ybErrorKind = iota
+	// ybTimeout failures have an unknown outcome
+	ybTimeout
+	// ybOverloaded failures were rejected to shed load and should be retried with backoff
+	ybOverloaded
+)
+
+// ybErrorRules is evaluated in order, so more specific messages must precede more general ones
+var ybErrorRules = []ybErrorRule{
+	{
+		name:     "transaction conflict",
+		messages: transactionConflictMessages,
+		kind:     ybUnavailable,
+	},
+	{
+		name:     "transaction expired",
+		messages: []string{"transaction expired", "transaction metadata missing"},
+		kind:     ybUnavailable,
+	},
+	{
+		name:     "memory pressure",
+		messages: []string{"soft memory limit exceeded", "memory pressure", "hard memory limit"},
+		kind:     ybOverloaded,
+	},
+	{
+		name:     "service unavailable",
+		messages: []string{"service unavailable", "service queue is full"},
+		kind:     ybOverloaded,
+	},
+	{
+		name:     "tablet leader not ready",
+		messages: []string{"leader not ready", "not the leader", "leader_not_ready_to_serve", "leader is not ready"},
+		kind:     ybUnavailable,
+	},
+	{
+		name:     "tablet split",
+		messages: []string{"tablet split", "tablet is splitting", "tablet_split"},
+		kind:     ybUnavailable,
+	},
+	{
+		name:     "clock skew",
+		messages: []string{"clock skew"},
+		kind:     ybUnavailable,
+	},
+	{
+		name:     "timed out",
+		messages: []string{"timed out", "passed its deadline"},
+		kind:     ybTimeout,
+	},
+}
+
+// convertYugabyteError returns the Temporal error for a recognised YugabyteDB failure, or nil
+func convertYugabyteError(
+	operation string,
+	err error,
+) error {
+	text := strings.ToLower(err.Error())
+	for _, rule := range ybErrorRules {
+		for _, fragment := range rule.messages {
+			if !strings.Contains(text, fragment) {
+				continue
+			}
+
+			msg := fmt.Sprintf("operation %v encountered %v: %v", operation, rule.name, err.Error())
+			switch rule.kind {
+			case ybTimeout:
+				return &persistence.TimeoutError{Msg: msg}
+			case ybOverloaded:
+				return &serviceerror.ResourceExhausted{
+					Cause:   enumspb.RESOURCE_EXHAUSTED_CAUSE_SYSTEM_OVERLOADED,
+					Scope:   enumspb.RESOURCE_EXHAUSTED_SCOPE_SYSTEM,
+					Message: msg,
+				}
+			default:
+				return serviceerror.NewUnavailable(msg)
+			}
+		}
+	}
+	return nil
+}
+
 func ConvertError(
 	operation string,
 	err error,
@@ -57,6 +152,10 @@ func ConvertError(
 		return &persistence.TimeoutError{Msg: fmt.Sprintf("operation %v encountered %v", operation, cqlTimeoutErr.Error())}
 	}
 
+	if ybErr := convertYugabyteError(operation, err); ybErr != nil {
+		return ybErr
+	}
+
 	var cqlRequestErr gocql.RequestError
 	if errors.As(err, &cqlRequestErr) {
 		if cqlRequestErr.Code() == gocql.ErrCodeOverloaded {
@@ -0,0 +1,139 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package gocql
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yugabyte/gocql"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/server/common/persistence"
+)
+
+func TestConvertError(t *testing.T) {
+	type verifyFn func(*testing.T, error)
+
+	unavailable := func(t *testing.T, err error) {
+		var target *serviceerror.Unavailable
+		assert.ErrorAs(t, err, &target)
+	}
+	timeout := func(t *testing.T, err error) {
+		var target *persistence.TimeoutError
+		assert.ErrorAs(t, err, &target)
+	}
+	overloaded := func(t *testing.T, err error) {
+		var target *serviceerror.ResourceExhausted
+		if assert.ErrorAs(t, err, &target) {
+			assert.Equal(t, enumspb.RESOURCE_EXHAUSTED_CAUSE_SYSTEM_OVERLOADED, target.Cause)
+			assert.Equal(t, enumspb.RESOURCE_EXHAUSTED_SCOPE_SYSTEM, target.Scope)
+		}
+	}
+
+	tests := map[string]struct {
+		err    error
+		verify verifyFn
+	}{
+		"leaderNotReady": {
+			err:    testRequestError{code: gocql.ErrCodeServer, message: "Leader not ready to serve requests. (yb/tserver/tablet_service.cc:1852): Tablet 3b5e4a9f2c1d4e8fb7a6c5d4e3f2a1b0 peer 8e7d6c5b4a3f2e1d0c9b8a7f6e5d4c3b"},
+			verify: unavailable,
+		},
+		"notTheLeader": {
+			err:    testRequestError{code: gocql.ErrCodeServer, message: "Not the leader (yb/consensus/consensus.cc:111): Not the leader. Local UUID: 8e7d6c5b, Raft Config: {...}"},
+			verify: unavailable,
+		},
+		"tabletSplit": {
+			err:    testRequestError{code: gocql.ErrCodeServer, message: "Tablet split has been applied (yb/tablet/tablet.cc:3901): tablet 3b5e4a9f2c1d4e8f"},
+			verify: unavailable,
+		},
+		"transactionExpired": {
+			err:    testRequestError{code: gocql.ErrCodeServer, message: "Execution Error. Transaction expired or aborted by a conflict: 40001"},
+			verify: unavailable,
+		},
+		"transactionAborted": {
+			err:    testRequestError{code: gocql.ErrCodeServer, message: "Operation failed. Try again: Transaction aborted: 7f4e2f5e-1b4c-4e2a-9c1e-2a3b4c5d6e7f"},
+			verify: unavailable,
+		},
+		"clockSkew": {
+			err:    testRequestError{code: gocql.ErrCodeServer, message: "Too big clock skew is detected: 0.612s, while max allowed is: 0.500s"},
+			verify: unavailable,
+		},
+		"serviceUnavailable": {
+			err:    testRequestError{code: gocql.ErrCodeServer, message: "Service unavailable (yb/rpc/service_pool.cc:223): Write request on yb.tserver.TabletServerService from 10.0.0.1:40216 dropped due to backpressure. The service queue is full, it has 5000 items."},
+			verify: overloaded,
+		},
+		"softMemoryLimit": {
+			err:    testRequestError{code: gocql.ErrCodeServer, message: "Service unavailable (yb/tablet/tablet_peer.cc:412): Rejecting Write request: Soft memory limit exceeded (at 91.42% of capacity)"},
+			verify: overloaded,
+		},
+		"timedOut": {
+			err:    testRequestError{code: gocql.ErrCodeServer, message: "Timed out (yb/rpc/outbound_call.cc:602): Write RPC (request call id 12345) to 10.0.0.2:9100 timed out after 60.000s"},
+			verify: timeout,
+		},
+		"wrapped": {
+			err:    fmt.Errorf("wrapped: %w", testRequestError{code: gocql.ErrCodeServer, message: "Restart read required at: { read: { physical: 1718000000000000 } }"}),
+			verify: unavailable,
+		},
+		"timeoutNoResponse": {
+			err:    gocql.ErrTimeoutNoResponse,
+			verify: timeout,
+		},
+		"overloadedCode": {
+			err:    testRequestError{code: gocql.ErrCodeOverloaded, message: "overloaded"},
+			verify: overloaded,
+		},
+		"storageGuardrail": {
+			err: testRequestError{code: gocql.ErrCodeInvalid, message: "Disk usage exceeds failure threshold"},
+			verify: func(t *testing.T, err error) {
+				var target *serviceerror.ResourceExhausted
+				if assert.ErrorAs(t, err, &target) {
+					assert.Equal(t, enumspb.RESOURCE_EXHAUSTED_CAUSE_PERSISTENCE_STORAGE_LIMIT, target.Cause)
+				}
+			},
+		},
+		"notFound": {
+			err: gocql.ErrNotFound,
+			verify: func(t *testing.T, err error) {
+				var target *serviceerror.NotFound
+				assert.ErrorAs(t, err, &target)
+			},
+		},
+		"unknown": {
+			err:    errors.New("something unexpected"),
+			verify: unavailable,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ConvertError("TestOperation", tt.err)
+			assert.Contains(t, err.Error(), "TestOperation")
+			tt.verify(t, err)
+		})
+	}
+
+	assert.NoError(t, ConvertError("TestOperation", nil))
+}
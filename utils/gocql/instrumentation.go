@@ -0,0 +1,201 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package gocql
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/yugabyte/gocql"
+	"go.opentelemetry.io/otel/trace"
+	"go.temporal.io/server/common/metrics"
+)
+
+const (
+	unknownOperation = "Unknown"
+	unknownStore     = "unknown"
+
+	storeTagName     = "store"
+	statementTagName = "statement"
+
+	queryStatementKind = "query"
+	txnStatementKind   = "txn"
+	batchStatementKind = "batch"
+	iterStatementKind  = "iter"
+)
+
+type (
+	operationContextKey struct{}
+
+	// statementScope holds the settings and instrumentation applied to the statements issued on behalf of a store
+	statementScope struct {
+		store             string
+		consistency       *gocql.Consistency
+		serialConsistency *gocql.SerialConsistency
+		metricsHandler    metrics.Handler
+		slowQueries       *slowQueryLogger
+		// handlers caches metricsHandler tagged for each operation and statement kind
+		handlers sync.Map
+	}
+
+	handlerKey struct {
+		operation string
+		kind      string
+	}
+
+	// observation tracks a single statement from the moment it is issued until its outcome is known
+	observation struct {
+		ctx         context.Context
+		operation   string
+		store       string
+		info        statementInfo
+		handler     metrics.Handler
+		span        trace.Span
+		slowQueries *slowQueryLogger
+		start       time.Time
+	}
+)
+
+func newStatementScope(
+	store string,
+	metricsHandler metrics.Handler,
+	slowQueries *slowQueryLogger,
+) *statementScope {
+	return &statementScope{
+		store:          store,
+		metricsHandler: metricsHandler.WithTags(metrics.StringTag(storeTagName, store)),
+		slowQueries:    slowQueries,
+	}
+}
+
+// WithOperation names the persistence operation on whose behalf statements are executed with the returned
+// context.  Each store method sets it on entry, so statements issued from the goroutines it starts carry it too;
+// statements executed without it are tagged as unknownOperation.
+func WithOperation(ctx context.Context, operation string) context.Context {
+	return context.WithValue(ctx, operationContextKey{}, operation)
+}
+
+func operationName(ctx context.Context) string {
+	if ctx != nil {
+		if operation, ok := ctx.Value(operationContextKey{}).(string); ok {
+			return operation
+		}
+	}
+	return unknownOperation
+}
+
+// applyQuery sets the consistency levels of the scope, if any, on a query
+func (sc *statementScope) applyQuery(q *gocql.Query) {
+	if sc == nil {
+		return
+	}
+	if sc.consistency != nil {
+		q.Consistency(*sc.consistency)
+	}
+	if sc.serialConsistency != nil {
+		q.SerialConsistency(*sc.serialConsistency)
+	}
+}
+
+// applyBatch sets the consistency levels of the scope, if any, on a batch
+func (sc *statementScope) applyBatch(b *gocql.Batch) {
+	if sc == nil {
+		return
+	}
+	if sc.consistency != nil {
+		b.SetConsistency(*sc.consistency)
+	}
+	if sc.serialConsistency != nil {
+		b.SerialConsistency(*sc.serialConsistency)
+	}
+}
+
+func (sc *statementScope) observe(
+	ctx context.Context,
+	info statementInfo,
+) *observation {
+	if sc == nil {
+		return nil
+	}
+
+	operation := operationName(ctx)
+	return &observation{
+		ctx:         ctx,
+		operation:   operation,
+		store:       sc.store,
+		info:        info,
+		handler:     sc.handler(operation, info.kind),
+		span:        startSpan(ctx, operation, sc.store, info),
+		slowQueries: sc.slowQueries,
+		start:       time.Now(),
+	}
+}
+
+// handler returns the metrics handler of the scope tagged with the operation and statement kind
+func (sc *statementScope) handler(operation string, kind string) metrics.Handler {
+	key := handlerKey{operation: operation, kind: kind}
+	if h, ok := sc.handlers.Load(key); ok {
+		return h.(metrics.Handler)
+	}
+	h, _ := sc.handlers.LoadOrStore(key, sc.metricsHandler.WithTags(
+		metrics.OperationTag(operation),
+		metrics.StringTag(statementTagName, kind),
+	))
+	return h.(metrics.Handler)
+}
+
+// metricsHandler returns the handler tagged with the operation, store and statement kind under observation
+func (o *observation) metricsHandler() metrics.Handler {
+	if o == nil {
+		return metrics.NoopMetricsHandler
+	}
+	return o.handler
+}
+
+// done records the latency and outcome of the statement, ends its span and logs it if slow.  applied is nil for
+// statements without conditions.
+func (o *observation) done(err error, applied *bool) {
+	if o == nil {
+		return
+	}
+	latency := time.Since(o.start)
+	endSpan(o.span, err, applied)
+	StatementLatency.With(o.handler).Record(latency)
+	o.slowQueries.check(o, latency, err)
+
+	switch {
+	case err == nil:
+		if applied != nil && !*applied {
+			StatementCASNotApplied.With(o.handler).Record(1)
+		}
+	case errors.Is(err, gocql.ErrNotFound):
+		// an expected outcome of point reads
+	default:
+		StatementErrors.With(o.handler).Record(1)
+		if IsTransactionConflict(err) {
+			StatementTransactionConflicts.With(o.handler).Record(1)
+		}
+	}
+}
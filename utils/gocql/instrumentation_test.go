@@ -0,0 +1,131 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package gocql
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yugabyte/gocql"
+	"go.temporal.io/server/common/metrics"
+	"go.temporal.io/server/common/metrics/metricstest"
+)
+
+func TestOperationName(t *testing.T) {
+	assert.Equal(t, unknownOperation, operationName(context.Background()))
+	assert.Equal(t, "GetTasks", operationName(WithOperation(context.Background(), "GetTasks")))
+}
+
+func TestOperationOfStatementFromGoroutine(t *testing.T) {
+	handler := metricstest.NewCaptureHandler()
+	capture := handler.StartCapture()
+	defer handler.StopCapture(capture)
+
+	scope := newStatementScope("execution", handler, nil)
+	q := newQuery(nil, scope, queryStatementKind, &gocql.Query{}).
+		WithContext(WithOperation(context.Background(), "GetWorkflowExecution")).(*query)
+
+	// as the reads of a store method fanned out by PostQueryValidation
+	var wg sync.WaitGroup
+	wg.Add(3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			defer wg.Done()
+			scope.observe(q.gocqlQuery.Context(), statementInfo{kind: q.kind}).done(nil, nil)
+		}()
+	}
+	wg.Wait()
+
+	recordings := capture.Snapshot()[StatementLatency.Name()]
+	require.Len(t, recordings, 3)
+	for _, recording := range recordings {
+		assert.Equal(t, "GetWorkflowExecution", recording.Tags[metrics.OperationTagName])
+	}
+}
+
+func TestStatementScopeCachesHandlers(t *testing.T) {
+	scope := newStatementScope("execution", metricstest.NewCaptureHandler(), nil)
+
+	h := scope.handler("GetWorkflowExecution", queryStatementKind)
+	assert.Same(t, h, scope.handler("GetWorkflowExecution", queryStatementKind))
+	assert.NotSame(t, h, scope.handler("GetWorkflowExecution", iterStatementKind))
+	assert.NotSame(t, h, scope.handler("UpdateWorkflowExecution", queryStatementKind))
+}
+
+func TestObservationRecordsOutcome(t *testing.T) {
+	notApplied := false
+	tests := map[string]struct {
+		err      error
+		applied  *bool
+		expected []string
+	}{
+		"success": {},
+		"not found": {
+			err: gocql.ErrNotFound,
+		},
+		"not applied": {
+			applied:  &notApplied,
+			expected: []string{StatementCASNotApplied.Name()},
+		},
+		"error": {
+			err:      errors.New("boom"),
+			expected: []string{StatementErrors.Name()},
+		},
+		"conflict": {
+			err:      testRequestError{code: gocql.ErrCodeServer, message: "Transaction aborted: kAborted"},
+			expected: []string{StatementErrors.Name(), StatementTransactionConflicts.Name()},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			handler := metricstest.NewCaptureHandler()
+			capture := handler.StartCapture()
+			defer handler.StopCapture(capture)
+
+			scope := newStatementScope("execution", handler, nil)
+			scope.observe(WithOperation(context.Background(), "UpdateWorkflowExecution"), statementInfo{kind: txnStatementKind}).done(tc.err, tc.applied)
+
+			snapshot := capture.Snapshot()
+			require.Len(t, snapshot[StatementLatency.Name()], 1)
+			assert.Equal(t, map[string]string{
+				storeTagName:             "execution",
+				metrics.OperationTagName: "UpdateWorkflowExecution",
+				statementTagName:         txnStatementKind,
+			}, snapshot[StatementLatency.Name()][0].Tags)
+
+			for _, name := range []string{StatementErrors.Name(), StatementCASNotApplied.Name(), StatementTransactionConflicts.Name()} {
+				if slices.Contains(tc.expected, name) {
+					assert.Len(t, snapshot[name], 1, name)
+				} else {
+					assert.Empty(t, snapshot[name], name)
+				}
+			}
+		})
+	}
+}
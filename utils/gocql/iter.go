@@ -30,14 +30,16 @@ import (
 )
 
 type iter struct {
-	session   *session
-	gocqlIter *gocql.Iter
+	session     *session
+	observation *observation
+	gocqlIter   *gocql.Iter
 }
 
-func newIter(session *session, gocqlIter *gocql.Iter) *iter {
+func newIter(session *session, observation *observation, gocqlIter *gocql.Iter) *iter {
 	return &iter{
-		session:   session,
-		gocqlIter: gocqlIter,
+		session:     session,
+		observation: observation,
+		gocqlIter:   gocqlIter,
 	}
 }
 
@@ -56,5 +58,8 @@ func (it *iter) PageState() []byte {
 func (it *iter) Close() (retError error) {
 	defer func() { it.session.handleError(retError) }()
 
-	return it.gocqlIter.Close()
+	// the statement is observed from the first page fetch until the caller is done with the rows
+	err := it.gocqlIter.Close()
+	it.observation.done(err, nil)
+	return err
 }
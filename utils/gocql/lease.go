@@ -0,0 +1,108 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package gocql
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+var _ Session = (*sessionLease)(nil)
+
+type (
+	// SessionOwner owns a session shared by many stores.  Each store holds a lease on the session, and closing a
+	// store releases its lease without affecting the others.  The session itself is closed only by the owner.
+	SessionOwner struct {
+		session Session
+
+		sync.Mutex
+		leases int
+		closed bool
+	}
+
+	// sessionLease is a view of a shared session whose Close releases the lease rather than closing the session
+	sessionLease struct {
+		Session
+		owner    *SessionOwner
+		released atomic.Bool
+	}
+)
+
+// NewSessionOwner takes ownership of session
+func NewSessionOwner(session Session) *SessionOwner {
+	return &SessionOwner{
+		session: session,
+	}
+}
+
+// Session returns the owned session, from which views such as those of ForStore may be derived before being leased
+func (o *SessionOwner) Session() Session {
+	return o.session
+}
+
+// Lease returns view, which must share the connections of the owned session, as a Session whose Close releases
+// the lease.  Releasing a lease more than once has no effect.
+func (o *SessionOwner) Lease(view Session) Session {
+	o.Lock()
+	defer o.Unlock()
+
+	o.leases++
+	return &sessionLease{
+		Session: view,
+		owner:   o,
+	}
+}
+
+// Leases returns the number of leases which have not been released
+func (o *SessionOwner) Leases() int {
+	o.Lock()
+	defer o.Unlock()
+
+	return o.leases
+}
+
+// Close closes the owned session, regardless of any outstanding leases.  Closing the owner more than once has no
+// effect.
+func (o *SessionOwner) Close() {
+	o.Lock()
+	defer o.Unlock()
+
+	if o.closed {
+		return
+	}
+	o.closed = true
+	o.session.Close()
+}
+
+func (o *SessionOwner) release() {
+	o.Lock()
+	defer o.Unlock()
+
+	o.leases--
+}
+
+func (l *sessionLease) Close() {
+	if l.released.CompareAndSwap(false, true) {
+		l.owner.release()
+	}
+}
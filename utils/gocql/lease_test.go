@@ -0,0 +1,66 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package gocql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type closeCountingSession struct {
+	closed int
+}
+
+func (s *closeCountingSession) Query(string, ...interface{}) Query         { return nil }
+func (s *closeCountingSession) NewTxn() *Txn                               { return nil }
+func (s *closeCountingSession) NewBatch(BatchType) *Batch                  { return nil }
+func (s *closeCountingSession) ExecuteBatch(*Batch) error                  { return nil }
+func (s *closeCountingSession) AwaitSchemaAgreement(context.Context) error { return nil }
+func (s *closeCountingSession) MapExecuteBatchCAS(*Batch, map[string]interface{}) (bool, Iter, error) {
+	return false, nil, nil
+}
+func (s *closeCountingSession) Close() { s.closed++ }
+
+func TestSessionLease(t *testing.T) {
+	session := &closeCountingSession{}
+	owner := NewSessionOwner(session)
+
+	first := owner.Lease(owner.Session())
+	second := owner.Lease(owner.Session())
+	assert.Equal(t, 2, owner.Leases())
+
+	first.Close()
+	first.Close()
+	assert.Equal(t, 1, owner.Leases())
+	assert.Zero(t, session.closed)
+
+	second.Close()
+	assert.Zero(t, owner.Leases())
+	assert.Zero(t, session.closed)
+
+	owner.Close()
+	owner.Close()
+	assert.Equal(t, 1, session.closed)
+}
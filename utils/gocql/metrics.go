@@ -0,0 +1,63 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package gocql
+
+import (
+	"go.temporal.io/server/common/metrics"
+)
+
+// Metrics emitted by the gocql wrapper in addition to the Cassandra session metrics defined by Temporal.  Statement
+// metrics are tagged with the persistence operation, the store and the kind of statement.
+var (
+	StatementLatency = metrics.NewTimerDef(
+		"yugabyte_statement_latency",
+		metrics.WithDescription("Latency of YCQL statements, transactions, batches and iterators including retries"),
+	)
+	StatementErrors = metrics.NewCounterDef(
+		"yugabyte_statement_errors",
+		metrics.WithDescription("YCQL statements which failed, excluding reads which found no rows"),
+	)
+	StatementCASNotApplied = metrics.NewCounterDef(
+		"yugabyte_statement_cas_not_applied",
+		metrics.WithDescription("Conditional statements and transactions whose condition was not satisfied"),
+	)
+	StatementTransactionConflicts = metrics.NewCounterDef(
+		"yugabyte_statement_transaction_conflicts",
+		metrics.WithDescription("Statements which finally failed because of a YCQL transaction conflict"),
+	)
+	StatementRetries = metrics.NewCounterDef(
+		"yugabyte_statement_retries",
+		metrics.WithDescription("Statements retried after a transaction conflict or transient error, tagged by reason"),
+	)
+	StatementRetriesExhausted = metrics.NewCounterDef(
+		"yugabyte_statement_retries_exhausted",
+		metrics.WithDescription("Statements which still failed with a retryable error after the final attempt, tagged by reason"),
+	)
+
+	TLSCertificateExpiry = metrics.NewGaugeDef(
+		"yugabyte_tls_certificate_expiry_seconds",
+		metrics.WithDescription("Seconds until the earliest expiry of the Yugabyte client certificate or CA bundle, tagged by certificate"),
+	)
+	TLSCertificateReloads        = metrics.NewCounterDef("yugabyte_tls_certificate_reloads")
+	TLSCertificateReloadFailures = metrics.NewCounterDef("yugabyte_tls_certificate_reload_failures")
+)
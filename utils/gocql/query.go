@@ -36,16 +36,29 @@ var _ Query = (*query)(nil)
 type (
 	query struct {
 		session    *session
+		scope      *statementScope
+		kind       string
 		gocqlQuery *gocql.Query
+
+		// summary and statements describe the statements of a transaction, pageSize any explicit page size and
+		// values the bound values
+		summary    string
+		statements int
+		pageSize   int
+		values     []interface{}
 	}
 )
 
 func newQuery(
 	session *session,
+	scope *statementScope,
+	kind string,
 	gocqlQuery *gocql.Query,
 ) *query {
 	return &query{
 		session:    session,
+		scope:      scope,
+		kind:       kind,
 		gocqlQuery: gocqlQuery,
 	}
 }
@@ -53,7 +66,9 @@ func newQuery(
 func (q *query) Exec() (retError error) {
 	defer func() { q.session.handleError(retError) }()
 
-	return q.gocqlQuery.Exec()
+	return q.execute(nil, func() error {
+		return q.gocqlQuery.Exec()
+	})
 }
 
 func (q *query) Scan(
@@ -61,7 +76,9 @@ func (q *query) Scan(
 ) (retError error) {
 	defer func() { q.session.handleError(retError) }()
 
-	return q.gocqlQuery.Scan(dest...)
+	return q.execute(nil, func() error {
+		return q.gocqlQuery.Scan(dest...)
+	})
 }
 
 func (q *query) ScanCAS(
@@ -69,7 +86,13 @@ func (q *query) ScanCAS(
 ) (_ bool, retError error) {
 	defer func() { q.session.handleError(retError) }()
 
-	return q.gocqlQuery.ScanCAS(dest...)
+	var applied bool
+	err := q.execute(&applied, func() error {
+		var err error
+		applied, err = q.gocqlQuery.ScanCAS(dest...)
+		return err
+	})
+	return applied, err
 }
 
 func (q *query) MapScan(
@@ -77,7 +100,9 @@ func (q *query) MapScan(
 ) (retError error) {
 	defer func() { q.session.handleError(retError) }()
 
-	return q.gocqlQuery.MapScan(m)
+	return q.execute(nil, func() error {
+		return q.gocqlQuery.MapScan(m)
+	})
 }
 
 func (q *query) MapScanCAS(
@@ -85,32 +110,77 @@ func (q *query) MapScanCAS(
 ) (_ bool, retError error) {
 	defer func() { q.session.handleError(retError) }()
 
-	return q.gocqlQuery.MapScanCAS(dest)
+	var applied bool
+	err := q.execute(&applied, func() error {
+		var err error
+		applied, err = q.gocqlQuery.MapScanCAS(dest)
+		return err
+	})
+	return applied, err
+}
+
+// execute observes op and runs it under the session's RetryPolicy.  applied, when not nil, receives the outcome of
+// a conditional statement once op completes.  Iterators are not retried as their errors only surface once rows have
+// already been consumed.
+func (q *query) execute(applied *bool, op func() error) error {
+	o := q.scope.observe(q.gocqlQuery.Context(), q.statementInfo(q.kind))
+	idempotent := q.gocqlQuery.IsIdempotent() || isIdempotentStatement(q.gocqlQuery.Statement())
+	err := q.session.retryPolicy.execute(q.gocqlQuery.Context(), idempotent, o.metricsHandler(), op)
+	o.done(err, applied)
+	return err
 }
 
 func (q *query) Iter() Iter {
+	o := q.scope.observe(q.gocqlQuery.Context(), q.statementInfo(iterStatementKind))
 	iter := q.gocqlQuery.Iter()
-	return newIter(q.session, iter)
+	return newIter(q.session, o, iter)
+}
+
+func (q *query) statementInfo(kind string) statementInfo {
+	info := statementInfo{
+		kind:       kind,
+		keyspace:   q.gocqlQuery.Keyspace(),
+		summary:    q.summary,
+		statements: q.statements,
+		pageSize:   q.pageSize,
+		values:     q.values,
+	}
+	if info.summary == "" {
+		info.summary = summarizeStatement(q.gocqlQuery.Statement())
+	}
+	if info.statements == 0 {
+		info.statements = 1
+	}
+	return info
+}
+
+// with returns a copy of the query wrapping gocqlQuery
+func (q *query) with(gocqlQuery *gocql.Query) *query {
+	q2 := *q
+	q2.gocqlQuery = gocqlQuery
+	return &q2
 }
 
 func (q *query) PageSize(n int) Query {
 	q.gocqlQuery.PageSize(n)
-	return newQuery(q.session, q.gocqlQuery)
+	q2 := q.with(q.gocqlQuery)
+	q2.pageSize = n
+	return q2
 }
 
 func (q *query) PageState(state []byte) Query {
 	q.gocqlQuery.PageState(state)
-	return newQuery(q.session, q.gocqlQuery)
+	return q.with(q.gocqlQuery)
 }
 
 func (q *query) Consistency(c Consistency) Query {
 	q.gocqlQuery.Consistency(mustConvertConsistency(c))
-	return newQuery(q.session, q.gocqlQuery)
+	return q.with(q.gocqlQuery)
 }
 
 func (q *query) WithTimestamp(timestamp int64) Query {
 	q.gocqlQuery.WithTimestamp(timestamp)
-	return newQuery(q.session, q.gocqlQuery)
+	return q.with(q.gocqlQuery)
 }
 
 func (q *query) WithContext(ctx context.Context) Query {
@@ -118,10 +188,12 @@ func (q *query) WithContext(ctx context.Context) Query {
 	if q2 == nil {
 		return nil
 	}
-	return newQuery(q.session, q2)
+	return q.with(q2)
 }
 
 func (q *query) Bind(v ...interface{}) Query {
 	q.gocqlQuery.Bind(v...)
-	return newQuery(q.session, q.gocqlQuery)
+	q2 := q.with(q.gocqlQuery)
+	q2.values = v
+	return q2
 }
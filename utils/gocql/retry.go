@@ -0,0 +1,210 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package gocql
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
+	"time"
+
+	ybconfig "github.com/manetu/temporal-yugabyte/driver/config"
+	"github.com/yugabyte/gocql"
+	"go.temporal.io/server/common/metrics"
+)
+
+const (
+	defaultRetryMaxAttempts     = 3
+	defaultRetryInitialInterval = 20 * time.Millisecond
+	defaultRetryMaxInterval     = time.Second
+
+	conflictRetryTagValue  = "conflict"
+	transientRetryTagValue = "transient"
+)
+
+// transactionConflictMessages identifies YugabyteDB transaction conflicts.  The transaction was not applied, so the
+// statement may be retried regardless of whether it is idempotent.
+var transactionConflictMessages = []string{
+	"transaction aborted",
+	"restart read required",
+	"conflicts with higher priority transaction",
+	"conflicts with committed transaction",
+}
+
+type (
+	// RetryPolicy retries statements which failed with transaction conflicts or transient errors, backing off
+	// exponentially with jitter between attempts.  A nil RetryPolicy performs a single attempt.
+	RetryPolicy struct {
+		maxAttempts     int
+		initialInterval time.Duration
+		maxInterval     time.Duration
+	}
+)
+
+// NewRetryPolicy returns a RetryPolicy from the configuration, applying defaults for anything not set
+func NewRetryPolicy(cfg *ybconfig.YugabyteRetryPolicy) *RetryPolicy {
+	p := &RetryPolicy{
+		maxAttempts:     defaultRetryMaxAttempts,
+		initialInterval: defaultRetryInitialInterval,
+		maxInterval:     defaultRetryMaxInterval,
+	}
+	if cfg == nil {
+		return p
+	}
+
+	if cfg.MaxAttempts > 0 {
+		p.maxAttempts = cfg.MaxAttempts
+	}
+	if cfg.InitialInterval > 0 {
+		p.initialInterval = cfg.InitialInterval
+	}
+	if cfg.MaxInterval > 0 {
+		p.maxInterval = cfg.MaxInterval
+	}
+	if p.maxInterval < p.initialInterval {
+		p.maxInterval = p.initialInterval
+	}
+
+	return p
+}
+
+// execute runs op until it succeeds, fails with an error which may not be retried or exhausts the attempts
+func (p *RetryPolicy) execute(
+	ctx context.Context,
+	idempotent bool,
+	metricsHandler metrics.Handler,
+	op func() error,
+) error {
+	if p == nil {
+		return op()
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := op()
+		if err == nil {
+			return nil
+		}
+
+		reason := retryReason(err, idempotent)
+		if reason == "" {
+			return err
+		}
+
+		handler := metricsHandler.WithTags(metrics.StringTag("reason", reason))
+		if attempt >= p.maxAttempts {
+			if p.maxAttempts > 1 {
+				StatementRetriesExhausted.With(handler).Record(1)
+			}
+			return err
+		}
+
+		StatementRetries.With(handler).Record(1)
+		if !sleep(ctx, p.backoff(attempt)) {
+			return err
+		}
+	}
+}
+
+// backoff returns the delay before the given retry: exponential growth capped at maxInterval, of which the
+// upper half is randomized so that competing transactions do not collide again
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.initialInterval
+	for i := 1; i < attempt && d < p.maxInterval; i++ {
+		d *= 2
+	}
+	if d > p.maxInterval {
+		d = p.maxInterval
+	}
+
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// retryReason returns the metric tag value describing why the error may be retried, or "" if it may not
+func retryReason(err error, idempotent bool) string {
+	if IsTransactionConflict(err) {
+		return conflictRetryTagValue
+	}
+	if idempotent && isTransientError(err) {
+		return transientRetryTagValue
+	}
+	return ""
+}
+
+// IsTransactionConflict returns true if the error reports that a YugabyteDB transaction was aborted because it
+// conflicted with another transaction
+func IsTransactionConflict(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, conflict := range transactionConflictMessages {
+		if strings.Contains(msg, conflict) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTransientError returns true for failures which may succeed if an idempotent statement is attempted again
+func isTransientError(err error) bool {
+	if errors.Is(err, gocql.ErrTimeoutNoResponse) {
+		return true
+	}
+
+	var cqlRequestErr gocql.RequestError
+	if errors.As(err, &cqlRequestErr) {
+		switch cqlRequestErr.Code() {
+		case gocql.ErrCodeUnavailable,
+			gocql.ErrCodeOverloaded,
+			gocql.ErrCodeReadTimeout,
+			gocql.ErrCodeWriteTimeout:
+			return true
+		}
+	}
+
+	return false
+}
+
+// isIdempotentStatement returns true for statements which may safely be executed more than once
+func isIdempotentStatement(stmt string) bool {
+	return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(stmt)), "SELECT ")
+}
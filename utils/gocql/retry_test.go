@@ -0,0 +1,197 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package gocql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	ybconfig "github.com/manetu/temporal-yugabyte/driver/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/yugabyte/gocql"
+	"go.temporal.io/server/common/metrics"
+)
+
+type testRequestError struct {
+	code    int
+	message string
+}
+
+func (e testRequestError) Code() int       { return e.code }
+func (e testRequestError) Message() string { return e.message }
+func (e testRequestError) Error() string   { return e.message }
+
+func newTestRetryPolicy(maxAttempts int) *RetryPolicy {
+	return NewRetryPolicy(&ybconfig.YugabyteRetryPolicy{
+		MaxAttempts:     maxAttempts,
+		InitialInterval: time.Millisecond,
+		MaxInterval:     2 * time.Millisecond,
+	})
+}
+
+func TestIsTransactionConflict(t *testing.T) {
+	tests := map[string]struct {
+		err      error
+		conflict bool
+	}{
+		"aborted": {
+			err:      testRequestError{code: gocql.ErrCodeServer, message: "Operation failed. Try again: Transaction aborted: 7f4e2f5e-1b4c-4e2a-9c1e-2a3b4c5d6e7f"},
+			conflict: true,
+		},
+		"restartRead": {
+			err:      testRequestError{code: gocql.ErrCodeServer, message: "Restart read required at: { read: { physical: 1718000000000000 } local_limit: { physical: 1718000000000500 } }"},
+			conflict: true,
+		},
+		"higherPriority": {
+			err:      testRequestError{code: gocql.ErrCodeServer, message: "Execution Error. Conflicts with higher priority transaction: 3c5d1e2f-0a1b-4c2d-8e3f-4a5b6c7d8e9f"},
+			conflict: true,
+		},
+		"condition": {
+			err:      testRequestError{code: gocql.ErrCodeInvalid, message: "Condition on table executions was not satisfied."},
+			conflict: false,
+		},
+		"nil": {
+			err:      nil,
+			conflict: false,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.conflict, IsTransactionConflict(tt.err))
+		})
+	}
+}
+
+func TestRetryPolicyRetriesConflicts(t *testing.T) {
+	policy := newTestRetryPolicy(3)
+	conflict := testRequestError{code: gocql.ErrCodeServer, message: "Transaction aborted: 7f4e2f5e"}
+
+	attempts := 0
+	err := policy.execute(context.Background(), false, metrics.NoopMetricsHandler, func() error {
+		attempts++
+		if attempts < 3 {
+			return conflict
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestRetryPolicyExhausted(t *testing.T) {
+	policy := newTestRetryPolicy(2)
+	conflict := testRequestError{code: gocql.ErrCodeServer, message: "Restart read required at: { read: 1 }"}
+
+	attempts := 0
+	err := policy.execute(context.Background(), false, metrics.NoopMetricsHandler, func() error {
+		attempts++
+		return conflict
+	})
+	assert.Equal(t, conflict, err)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestRetryPolicyTransientRequiresIdempotence(t *testing.T) {
+	policy := newTestRetryPolicy(3)
+
+	for _, idempotent := range []bool{false, true} {
+		attempts := 0
+		err := policy.execute(context.Background(), idempotent, metrics.NoopMetricsHandler, func() error {
+			attempts++
+			return gocql.ErrTimeoutNoResponse
+		})
+		assert.ErrorIs(t, err, gocql.ErrTimeoutNoResponse)
+		if idempotent {
+			assert.Equal(t, 3, attempts)
+		} else {
+			assert.Equal(t, 1, attempts)
+		}
+	}
+}
+
+func TestRetryPolicyDoesNotRetryPermanentErrors(t *testing.T) {
+	policy := newTestRetryPolicy(3)
+	permanent := errors.New("Invalid query")
+
+	attempts := 0
+	err := policy.execute(context.Background(), true, metrics.NoopMetricsHandler, func() error {
+		attempts++
+		return permanent
+	})
+	assert.Equal(t, permanent, err)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestRetryPolicyStopsWhenContextDone(t *testing.T) {
+	policy := NewRetryPolicy(&ybconfig.YugabyteRetryPolicy{
+		MaxAttempts:     5,
+		InitialInterval: time.Hour,
+		MaxInterval:     time.Hour,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	attempts := 0
+	err := policy.execute(ctx, false, metrics.NoopMetricsHandler, func() error {
+		attempts++
+		return testRequestError{code: gocql.ErrCodeServer, message: "Transaction aborted"}
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := NewRetryPolicy(&ybconfig.YugabyteRetryPolicy{
+		MaxAttempts:     10,
+		InitialInterval: 10 * time.Millisecond,
+		MaxInterval:     50 * time.Millisecond,
+	})
+
+	for attempt, ceiling := range map[int]time.Duration{
+		1: 10 * time.Millisecond,
+		2: 20 * time.Millisecond,
+		3: 40 * time.Millisecond,
+		4: 50 * time.Millisecond,
+		9: 50 * time.Millisecond,
+	} {
+		for i := 0; i < 100; i++ {
+			d := policy.backoff(attempt)
+			assert.GreaterOrEqual(t, d, ceiling/2)
+			assert.LessOrEqual(t, d, ceiling)
+		}
+	}
+}
+
+func TestNilRetryPolicy(t *testing.T) {
+	var policy *RetryPolicy
+
+	attempts := 0
+	_ = policy.execute(context.Background(), true, nil, func() error {
+		attempts++
+		return gocql.ErrTimeoutNoResponse
+	})
+	assert.Equal(t, 1, attempts)
+}
@@ -27,6 +27,7 @@ package gocql
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -59,20 +60,33 @@ type (
 		sync.Mutex
 		sessionInitTime time.Time
 		metricsHandler  metrics.Handler
+
+		certificates atomic.Pointer[certificateReloader]
+		shutdownCh   chan struct{}
+
+		retryPolicy *RetryPolicy
+		slowQueries *slowQueryLogger
+		scope       *statementScope
 	}
+
+	// SessionOption configures optional behaviour of a session created by NewSession
+	SessionOption func(*session)
 )
 
+// WithRetryPolicy replaces the default RetryPolicy of the session
+func WithRetryPolicy(policy *RetryPolicy) SessionOption {
+	return func(s *session) {
+		s.retryPolicy = policy
+	}
+}
+
 func NewSession(
 	newClusterConfigFunc func() (*gocql.ClusterConfig, error),
 	logger log.Logger,
 	metricsHandler metrics.Handler,
+	opts ...SessionOption,
 ) (*session, error) {
 
-	gocqlSession, err := initSession(logger, newClusterConfigFunc, metricsHandler)
-	if err != nil {
-		return nil, err
-	}
-
 	session := &session{
 		status:               common.DaemonStatusStarted,
 		newClusterConfigFunc: newClusterConfigFunc,
@@ -80,11 +94,57 @@ func NewSession(
 		metricsHandler:       metricsHandler,
 
 		sessionInitTime: time.Now().UTC(),
+		shutdownCh:      make(chan struct{}),
+		retryPolicy:     NewRetryPolicy(nil),
+	}
+	for _, opt := range opts {
+		opt(session)
 	}
+	session.scope = newStatementScope(unknownStore, metricsHandler, session.slowQueries)
+
+	gocqlSession, err := initSession(logger, session.newClusterConfig, metricsHandler)
+	if err != nil {
+		return nil, err
+	}
+
 	session.Value.Store(gocqlSession)
+	if session.certificates.Load() != nil {
+		go session.watchCertificates()
+	}
 	return session, nil
 }
 
+// newClusterConfig creates the cluster configuration for a new gocql session, retaining the TLS certificate
+// reloader, if any, so that rotated certificates are picked up for the lifetime of that session
+func (s *session) newClusterConfig() (*gocql.ClusterConfig, error) {
+	cluster, err := s.newClusterConfigFunc()
+	if err != nil {
+		return nil, err
+	}
+
+	if reloader := certificateReloaderOf(cluster); reloader != nil {
+		reloader.reportExpiry(s.metricsHandler)
+		s.certificates.Store(reloader)
+	}
+	return cluster, nil
+}
+
+func (s *session) watchCertificates() {
+	ticker := time.NewTicker(certificateWatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.shutdownCh:
+			return
+		case <-ticker.C:
+			if reloader := s.certificates.Load(); reloader != nil {
+				reloader.check(s.logger, s.metricsHandler)
+			}
+		}
+	}
+}
+
 func (s *session) refresh() {
 	if atomic.LoadInt32(&s.status) != common.DaemonStatusStarted {
 		return
@@ -101,7 +161,7 @@ func (s *session) refresh() {
 		return
 	}
 
-	newSession, err := initSession(s.logger, s.newClusterConfigFunc, s.metricsHandler)
+	newSession, err := initSession(s.logger, s.newClusterConfig, s.metricsHandler)
 	if err != nil {
 		s.logger.Error("gocql wrapper: unable to refresh gocql session", tag.Error(err))
 		handler := s.metricsHandler.WithTags(metrics.FailureTag(refreshErrorTagValue))
@@ -136,37 +196,47 @@ func initSession(
 func (s *session) Query(
 	stmt string,
 	values ...interface{},
+) Query {
+	return s.newQuery(s.scope, stmt, values...)
+}
+
+func (s *session) newQuery(
+	scope *statementScope,
+	stmt string,
+	values ...interface{},
 ) Query {
 	q := s.Value.Load().(*gocql.Session).Query(stmt, values...)
 	if q == nil {
 		return nil
 	}
 
-	return &query{
-		session:    s,
-		gocqlQuery: q,
-	}
+	scope.applyQuery(q)
+	wrapped := newQuery(s, scope, queryStatementKind, q)
+	wrapped.values = values
+	return wrapped
 }
 
 func (s *session) NewTxn() *Txn {
-	return &Txn{
-		session: s,
-		stmt:    make([]string, 0),
-		args:    make([]interface{}, 0),
-	}
+	return newTxn(s, s.scope)
 }
 
 func (s *session) NewBatch(
 	batchType BatchType,
+) *Batch {
+	return s.newBatch(s.scope, batchType)
+}
+
+func (s *session) newBatch(
+	scope *statementScope,
+	batchType BatchType,
 ) *Batch {
 	b := s.Value.Load().(*gocql.Session).NewBatch(mustConvertBatchType(batchType))
 	if b == nil {
 		return nil
 	}
-	return &Batch{
-		session:    s,
-		gocqlBatch: b,
-	}
+
+	scope.applyBatch(b)
+	return newBatch(s, scope, b)
 }
 
 func (s *session) ExecuteBatch(
@@ -174,7 +244,12 @@ func (s *session) ExecuteBatch(
 ) (retError error) {
 	defer func() { s.handleError(retError) }()
 
-	return s.Value.Load().(*gocql.Session).ExecuteBatch(b.gocqlBatch)
+	o := b.scope.observe(b.gocqlBatch.Context(), b.statementInfo())
+	err := s.retryPolicy.execute(b.gocqlBatch.Context(), b.gocqlBatch.IsIdempotent(), o.metricsHandler(), func() error {
+		return s.Value.Load().(*gocql.Session).ExecuteBatch(b.gocqlBatch)
+	})
+	o.done(err, nil)
+	return err
 }
 
 func (s *session) MapExecuteBatchCAS(
@@ -183,7 +258,24 @@ func (s *session) MapExecuteBatchCAS(
 ) (_ bool, _ Iter, retError error) {
 	defer func() { s.handleError(retError) }()
 
-	applied, iter, err := s.Value.Load().(*gocql.Session).MapExecuteBatchCAS(b.gocqlBatch, previous)
+	var applied bool
+	var iter *gocql.Iter
+	o := b.scope.observe(b.gocqlBatch.Context(), b.statementInfo())
+	err := s.retryPolicy.execute(b.gocqlBatch.Context(), false, o.metricsHandler(), func() error {
+		// a failed attempt may have scanned part of a row into previous
+		clear(previous)
+		var err error
+		applied, iter, err = s.Value.Load().(*gocql.Session).MapExecuteBatchCAS(b.gocqlBatch, previous)
+		if err != nil && iter != nil {
+			// gocql leaves the iter of a failed scan open; release it before the batch is executed again
+			if closeErr := iter.Close(); closeErr != nil {
+				err = closeErr
+			}
+			iter = nil
+		}
+		return err
+	})
+	o.done(err, &applied)
 	return applied, iter, err
 }
 
@@ -203,6 +295,9 @@ func (s *session) Close() {
 	) {
 		return
 	}
+	if s.shutdownCh != nil {
+		close(s.shutdownCh)
+	}
 	s.Value.Load().(*gocql.Session).Close()
 }
 
@@ -214,6 +309,14 @@ func (s *session) handleError(
 		gocql.ErrSessionClosed:
 		s.refresh()
 	default:
-		// noop
+		if isAuthenticationError(err) {
+			// rebuilding the session re-reads credentials from the configured provider
+			s.refresh()
+		}
 	}
 }
+
+func isAuthenticationError(err error) bool {
+	var reqErr gocql.RequestError
+	return errors.As(err, &reqErr) && reqErr.Code() == gocql.ErrCodeCredentials
+}
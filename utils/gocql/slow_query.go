@@ -0,0 +1,150 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package gocql
+
+import (
+	"context"
+	"time"
+
+	ybconfig "github.com/manetu/temporal-yugabyte/driver/config"
+	"go.temporal.io/server/common/log"
+	"go.temporal.io/server/common/log/tag"
+)
+
+const (
+	latencyThreshold      = "latency"
+	statementsThreshold   = "statements"
+	payloadBytesThreshold = "payloadBytes"
+)
+
+type (
+	logTagsContextKey struct{}
+
+	// slowQueryLogger logs statements, transactions and batches which exceed any of the configured thresholds.  Only
+	// the statement templates and the size of the bound values are logged, never the values themselves.
+	slowQueryLogger struct {
+		logger       log.Logger
+		latency      time.Duration
+		statements   int
+		payloadBytes int
+	}
+)
+
+// WithSlowQueryLogging logs statements exceeding the thresholds of cfg through the logger of the session
+func WithSlowQueryLogging(cfg *ybconfig.YugabyteSlowQueryLogging) SessionOption {
+	return func(s *session) {
+		s.slowQueries = newSlowQueryLogger(s.logger, cfg)
+	}
+}
+
+func newSlowQueryLogger(logger log.Logger, cfg *ybconfig.YugabyteSlowQueryLogging) *slowQueryLogger {
+	if cfg == nil || (cfg.Latency <= 0 && cfg.Statements <= 0 && cfg.PayloadBytes <= 0) {
+		return nil
+	}
+
+	return &slowQueryLogger{
+		logger:       logger,
+		latency:      cfg.Latency,
+		statements:   cfg.Statements,
+		payloadBytes: cfg.PayloadBytes,
+	}
+}
+
+// WithLogTags attaches tags identifying the subject of a persistence request, such as its shard and workflow, to
+// the log entries of any slow statements executed with the returned context
+func WithLogTags(ctx context.Context, tags ...tag.Tag) context.Context {
+	existing := logTags(ctx)
+	merged := make([]tag.Tag, 0, len(existing)+len(tags))
+	merged = append(merged, existing...)
+	merged = append(merged, tags...)
+	return context.WithValue(ctx, logTagsContextKey{}, merged)
+}
+
+func logTags(ctx context.Context) []tag.Tag {
+	if ctx == nil {
+		return nil
+	}
+	tags, _ := ctx.Value(logTagsContextKey{}).([]tag.Tag)
+	return tags
+}
+
+func (l *slowQueryLogger) check(o *observation, latency time.Duration, err error) {
+	if l == nil {
+		return
+	}
+
+	var exceeded []string
+	if l.latency > 0 && latency >= l.latency {
+		exceeded = append(exceeded, latencyThreshold)
+	}
+	if l.statements > 0 && o.info.statements >= l.statements {
+		exceeded = append(exceeded, statementsThreshold)
+	}
+
+	var payloadBytes int
+	if l.payloadBytes > 0 || len(exceeded) > 0 {
+		payloadBytes = payloadSize(o.info.values)
+		if l.payloadBytes > 0 && payloadBytes >= l.payloadBytes {
+			exceeded = append(exceeded, payloadBytesThreshold)
+		}
+	}
+	if len(exceeded) == 0 {
+		return
+	}
+
+	tags := []tag.Tag{
+		tag.Operation(o.operation),
+		tag.NewStringTag("store", o.store),
+		tag.NewStringTag("statement", o.info.kind),
+		tag.NewStringTag("statement-summary", o.info.summary),
+		tag.NewStringTag("keyspace", o.info.keyspace),
+		tag.NewInt("statement-count", o.info.statements),
+		tag.NewInt("payload-bytes", payloadBytes),
+		tag.NewDurationTag("latency", latency),
+		tag.NewStringsTag("thresholds-exceeded", exceeded),
+	}
+	tags = append(tags, logTags(o.ctx)...)
+	if err != nil {
+		tags = append(tags, tag.Error(err))
+	}
+	l.logger.Warn("Yugabyte statement exceeded slow query thresholds", tags...)
+}
+
+// payloadSize approximates the size of bound values by the length of their blobs and strings, which dominate the
+// payload of every persistence operation
+func payloadSize(values []interface{}) int {
+	var size int
+	for _, v := range values {
+		switch t := v.(type) {
+		case []byte:
+			size += len(t)
+		case string:
+			size += len(t)
+		case []string:
+			for _, s := range t {
+				size += len(s)
+			}
+		}
+	}
+	return size
+}
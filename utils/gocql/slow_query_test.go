@@ -0,0 +1,115 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package gocql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	ybconfig "github.com/manetu/temporal-yugabyte/driver/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/server/common/log"
+	"go.temporal.io/server/common/log/tag"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSlowQueryLogger(t *testing.T) {
+	ctx := WithLogTags(context.Background(), tag.ShardID(7))
+	ctx = WithLogTags(ctx, tag.WorkflowNamespaceID("ns"), tag.WorkflowID("wf"))
+
+	tests := map[string]struct {
+		latency  time.Duration
+		info     statementInfo
+		exceeded []interface{}
+	}{
+		"fast": {
+			latency: time.Millisecond,
+			info:    statementInfo{kind: queryStatementKind, statements: 1},
+		},
+		"slow": {
+			latency:  time.Second,
+			info:     statementInfo{kind: queryStatementKind, statements: 1},
+			exceeded: []interface{}{latencyThreshold},
+		},
+		"largeTxn": {
+			latency:  time.Millisecond,
+			info:     statementInfo{kind: txnStatementKind, statements: 20},
+			exceeded: []interface{}{statementsThreshold},
+		},
+		"largePayload": {
+			latency: time.Millisecond,
+			info: statementInfo{
+				kind:       txnStatementKind,
+				statements: 2,
+				values:     []interface{}{int32(7), make([]byte, 600), "secret-workflow-state", make([]byte, 500)},
+			},
+			exceeded: []interface{}{payloadBytesThreshold},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			core, logs := observer.New(zap.WarnLevel)
+			l := newSlowQueryLogger(log.NewZapLogger(zap.New(core)), &ybconfig.YugabyteSlowQueryLogging{
+				Latency:      500 * time.Millisecond,
+				Statements:   10,
+				PayloadBytes: 1024,
+			})
+
+			l.check(&observation{
+				ctx:       ctx,
+				operation: "UpdateWorkflowExecution",
+				store:     "execution",
+				info:      tc.info,
+			}, tc.latency, nil)
+
+			if tc.exceeded == nil {
+				assert.Zero(t, logs.Len())
+				return
+			}
+
+			require.Equal(t, 1, logs.Len())
+			fields := logs.All()[0].ContextMap()
+			assert.Equal(t, tc.exceeded, fields["thresholds-exceeded"])
+			assert.Equal(t, "UpdateWorkflowExecution", fields["operation"])
+			assert.Equal(t, int32(7), fields["shard-id"])
+			assert.Equal(t, "ns", fields["wf-namespace-id"])
+			assert.Equal(t, "wf", fields["wf-id"])
+			for _, value := range fields {
+				assert.NotEqual(t, "secret-workflow-state", value)
+			}
+		})
+	}
+}
+
+func TestSlowQueryLoggerDisabled(t *testing.T) {
+	assert.Nil(t, newSlowQueryLogger(log.NewNoopLogger(), nil))
+	assert.Nil(t, newSlowQueryLogger(log.NewNoopLogger(), &ybconfig.YugabyteSlowQueryLogging{}))
+}
+
+func TestPayloadSize(t *testing.T) {
+	assert.Equal(t, 8, payloadSize([]interface{}{[]byte("abc"), "de", []string{"f", "gh"}, 42, nil}))
+}
@@ -0,0 +1,82 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package gocql
+
+import (
+	"github.com/yugabyte/gocql"
+)
+
+var _ Session = (*storeSession)(nil)
+
+type (
+	// storeSession shares the connections and lifecycle of a session while issuing every statement at the
+	// consistency levels of a particular store, and attributing its metrics to that store
+	storeSession struct {
+		*session
+		scope *statementScope
+	}
+)
+
+// ForStore returns a Session which issues all queries, transactions and batches on behalf of the named store
+// at the given consistency levels.  Sessions not created by NewSession are returned unchanged.
+func ForStore(
+	s Session,
+	store string,
+	consistency gocql.Consistency,
+	serialConsistency gocql.SerialConsistency,
+) Session {
+	var base *session
+	switch t := s.(type) {
+	case *session:
+		base = t
+	case *storeSession:
+		base = t.session
+	default:
+		return s
+	}
+
+	scope := newStatementScope(store, base.metricsHandler, base.slowQueries)
+	scope.consistency = &consistency
+	scope.serialConsistency = &serialConsistency
+	return &storeSession{
+		session: base,
+		scope:   scope,
+	}
+}
+
+func (s *storeSession) Query(
+	stmt string,
+	values ...interface{},
+) Query {
+	return s.session.newQuery(s.scope, stmt, values...)
+}
+
+func (s *storeSession) NewTxn() *Txn {
+	return newTxn(s.session, s.scope)
+}
+
+func (s *storeSession) NewBatch(
+	batchType BatchType,
+) *Batch {
+	return s.session.newBatch(s.scope, batchType)
+}
@@ -0,0 +1,385 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package gocql
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yugabyte/gocql"
+	"go.temporal.io/server/common/auth"
+	"go.temporal.io/server/common/log"
+	"go.temporal.io/server/common/log/tag"
+	"go.temporal.io/server/common/metrics"
+)
+
+const (
+	certificateWatchInterval = 30 * time.Second
+
+	clientCertificateTagValue = "client"
+	caCertificateTagValue     = "ca"
+)
+
+var _ gocql.HostDialer = (*certificateReloader)(nil)
+
+type (
+	// certificateReloader holds the client certificate and CA pool of a TLS configuration which refers to files.
+	// It is installed as the cluster's HostDialer so that every new connection handshakes with the material
+	// current at that time, and the session periodically calls check() to pick up files rotated on disk.
+	certificateReloader struct {
+		cfg    *auth.TLS
+		dialer gocql.Dialer
+
+		sync.RWMutex
+		certificate *tls.Certificate
+		rootCAs     *x509.CertPool
+		caCerts     []*x509.Certificate
+		stamps      map[string]fileStamp
+	}
+
+	fileStamp struct {
+		modTime time.Time
+		size    int64
+	}
+)
+
+// usesCertificateFiles returns true if any TLS material is read from files, and hence may be rotated
+func usesCertificateFiles(cfg *auth.TLS) bool {
+	return cfg.CertFile != "" || cfg.KeyFile != "" || cfg.CaFile != ""
+}
+
+func newCertificateReloader(cfg *auth.TLS) (*certificateReloader, error) {
+	r := &certificateReloader{cfg: cfg}
+	if _, err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// reload re-reads the TLS material if any of its files have changed since they were last read.  The material
+// in use is retained if the new files cannot be loaded, e.g. when only one of a certificate/key pair was written.
+func (r *certificateReloader) reload() (bool, error) {
+	stamps, err := r.fileStamps()
+	if err != nil {
+		return false, err
+	}
+
+	r.RLock()
+	changed := r.stamps == nil || !equalStamps(r.stamps, stamps)
+	r.RUnlock()
+	if !changed {
+		return false, nil
+	}
+
+	certificate, err := readClientCertificate(r.cfg)
+	if err != nil {
+		return false, err
+	}
+
+	rootCAs, caCerts, err := readRootCAs(r.cfg)
+	if err != nil {
+		return false, err
+	}
+
+	r.Lock()
+	defer r.Unlock()
+	r.certificate = certificate
+	r.rootCAs = rootCAs
+	r.caCerts = caCerts
+	r.stamps = stamps
+
+	return true, nil
+}
+
+func (r *certificateReloader) fileStamps() (map[string]fileStamp, error) {
+	stamps := make(map[string]fileStamp)
+	for _, path := range []string{r.cfg.CertFile, r.cfg.KeyFile, r.cfg.CaFile} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to stat TLS file: %w", err)
+		}
+		stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+	}
+	return stamps, nil
+}
+
+func equalStamps(a, b map[string]fileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, stamp := range a {
+		if other, ok := b[path]; !ok || !other.modTime.Equal(stamp.modTime) || other.size != stamp.size {
+			return false
+		}
+	}
+	return true
+}
+
+// check reloads rotated material and reports certificate expiry
+func (r *certificateReloader) check(logger log.Logger, metricsHandler metrics.Handler) {
+	reloaded, err := r.reload()
+	if err != nil {
+		logger.Error("gocql wrapper: unable to reload TLS certificates", tag.Error(err))
+		TLSCertificateReloadFailures.With(metricsHandler).Record(1)
+	} else if reloaded {
+		logger.Info("gocql wrapper: reloaded rotated TLS certificates")
+		TLSCertificateReloads.With(metricsHandler).Record(1)
+	}
+	r.reportExpiry(metricsHandler)
+}
+
+func (r *certificateReloader) reportExpiry(metricsHandler metrics.Handler) {
+	r.RLock()
+	defer r.RUnlock()
+
+	now := time.Now()
+	if r.certificate != nil && r.certificate.Leaf != nil {
+		TLSCertificateExpiry.With(metricsHandler).Record(
+			r.certificate.Leaf.NotAfter.Sub(now).Seconds(),
+			metrics.StringTag("certificate", clientCertificateTagValue),
+		)
+	}
+
+	var earliest time.Time
+	for _, cert := range r.caCerts {
+		if earliest.IsZero() || cert.NotAfter.Before(earliest) {
+			earliest = cert.NotAfter
+		}
+	}
+	if !earliest.IsZero() {
+		TLSCertificateExpiry.With(metricsHandler).Record(
+			earliest.Sub(now).Seconds(),
+			metrics.StringTag("certificate", caCertificateTagValue),
+		)
+	}
+}
+
+func (r *certificateReloader) getClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	if r.certificate == nil {
+		// no client certificate is configured, continue the handshake without one
+		return &tls.Certificate{}, nil
+	}
+	return r.certificate, nil
+}
+
+// verifyPeerCertificate verifies the server chain against the current CA pool, replacing the verification the
+// tls package would otherwise perform against a pool fixed when the connection pool was created
+func (r *certificateReloader) verifyPeerCertificate(serverName string) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return errors.New("tls: server presented no certificates")
+		}
+
+		certs := make([]*x509.Certificate, 0, len(rawCerts))
+		for _, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return fmt.Errorf("tls: failed to parse server certificate: %w", err)
+			}
+			certs = append(certs, cert)
+		}
+
+		r.RLock()
+		rootCAs := r.rootCAs
+		r.RUnlock()
+
+		opts := x509.VerifyOptions{
+			Roots:         rootCAs,
+			DNSName:       serverName,
+			Intermediates: x509.NewCertPool(),
+		}
+		for _, cert := range certs[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+
+		_, err := certs[0].Verify(opts)
+		return err
+	}
+}
+
+// DialHost mirrors the gocql default host dialer, building a TLS configuration per connection from the
+// current certificate material
+func (r *certificateReloader) DialHost(ctx context.Context, host *gocql.HostInfo) (*gocql.DialedHost, error) {
+	ip := host.ConnectAddress()
+	port := host.Port()
+
+	if ip == nil || ip.IsUnspecified() {
+		return nil, fmt.Errorf("host missing connect ip address: %v", ip)
+	} else if port == 0 {
+		return nil, fmt.Errorf("host missing port: %v", port)
+	}
+
+	conn, err := r.dialer.DialContext(ctx, "tcp", host.ConnectAddressAndPort())
+	if err != nil {
+		return nil, err
+	}
+
+	addr := host.HostnameAndPort()
+	return gocql.WrapTLS(ctx, conn, addr, r.tlsConfigForAddr(addr))
+}
+
+func (r *certificateReloader) tlsConfigForAddr(addr string) *tls.Config {
+	config := auth.NewTLSConfigForServer(r.cfg.ServerName, r.cfg.EnableHostVerification)
+	config.GetClientCertificate = r.getClientCertificate
+
+	if r.cfg.EnableHostVerification {
+		serverName := config.ServerName
+		if serverName == "" {
+			serverName = addr
+			if colonPos := strings.LastIndex(addr, ":"); colonPos != -1 {
+				serverName = addr[:colonPos]
+			}
+			config.ServerName = serverName
+		}
+		// verification is performed by verifyPeerCertificate against the CA pool current at handshake time
+		config.InsecureSkipVerify = true
+		config.VerifyPeerCertificate = r.verifyPeerCertificate(serverName)
+	}
+
+	return config
+}
+
+// setDialer configures the dialer used for the underlying connection the same way gocql would
+func (r *certificateReloader) setDialer(cluster *gocql.ClusterConfig) {
+	if cluster.Dialer != nil {
+		r.dialer = cluster.Dialer
+		return
+	}
+
+	d := &net.Dialer{
+		Timeout: cluster.ConnectTimeout,
+	}
+	if cluster.SocketKeepalive > 0 {
+		d.KeepAlive = cluster.SocketKeepalive
+	}
+	r.dialer = d
+}
+
+func certificateReloaderOf(cluster *gocql.ClusterConfig) *certificateReloader {
+	if cluster == nil {
+		return nil
+	}
+	r, _ := cluster.HostDialer.(*certificateReloader)
+	return r
+}
+
+func readClientCertificate(cfg *auth.TLS) (*tls.Certificate, error) {
+	var certBytes []byte
+	var keyBytes []byte
+	var err error
+
+	if cfg.CertFile != "" {
+		certBytes, err = os.ReadFile(cfg.CertFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading client certificate file: %w", err)
+		}
+	} else if cfg.CertData != "" {
+		certBytes, err = base64.StdEncoding.DecodeString(cfg.CertData)
+		if err != nil {
+			return nil, fmt.Errorf("client certificate could not be decoded: %w", err)
+		}
+	}
+
+	if cfg.KeyFile != "" {
+		keyBytes, err = os.ReadFile(cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading client certificate private key file: %w", err)
+		}
+	} else if cfg.KeyData != "" {
+		keyBytes, err = base64.StdEncoding.DecodeString(cfg.KeyData)
+		if err != nil {
+			return nil, fmt.Errorf("client certificate private key could not be decoded: %w", err)
+		}
+	}
+
+	if len(certBytes) == 0 {
+		return nil, nil
+	}
+
+	clientCert, err := tls.X509KeyPair(certBytes, keyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate x509 key pair: %w", err)
+	}
+	if clientCert.Leaf == nil && len(clientCert.Certificate) > 0 {
+		clientCert.Leaf, _ = x509.ParseCertificate(clientCert.Certificate[0])
+	}
+
+	return &clientCert, nil
+}
+
+func readRootCAs(cfg *auth.TLS) (*x509.CertPool, []*x509.Certificate, error) {
+	var caBytes []byte
+	var err error
+
+	if cfg.CaFile != "" {
+		caBytes, err = os.ReadFile(cfg.CaFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error reading CA file: %w", err)
+		}
+	} else if cfg.CaData != "" {
+		caBytes, err = base64.StdEncoding.DecodeString(cfg.CaData)
+		if err != nil {
+			return nil, nil, fmt.Errorf("caData could not be decoded: %w", err)
+		}
+	} else {
+		return nil, nil, nil
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caBytes) {
+		return nil, nil, errors.New("failed to load decoded CA Cert as PEM")
+	}
+
+	return pool, parseCertificates(caBytes), nil
+}
+
+func parseCertificates(data []byte) []*x509.Certificate {
+	var certs []*x509.Certificate
+	for len(data) > 0 {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+			certs = append(certs, cert)
+		}
+	}
+	return certs
+}
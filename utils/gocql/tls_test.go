@@ -0,0 +1,194 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package gocql
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ybconfig "github.com/manetu/temporal-yugabyte/driver/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/server/common/auth"
+)
+
+type testCertificate struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func newTestCertificate(t *testing.T, commonName string, parent *testCertificate, isCA bool) *testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName},
+		DNSNames:              []string{commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+
+	parentCert, parentKey := template, key
+	if parent != nil {
+		parentCert, parentKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCert, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return &testCertificate{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func writeTestCertificates(t *testing.T, dir string, generation int, ca, client *testCertificate) *auth.TLS {
+	cfg := &auth.TLS{
+		Enabled:                true,
+		EnableHostVerification: true,
+		CaFile:                 filepath.Join(dir, "ca.crt"),
+		CertFile:               filepath.Join(dir, "tls.crt"),
+		KeyFile:                filepath.Join(dir, "tls.key"),
+	}
+	require.NoError(t, os.WriteFile(cfg.CaFile, ca.certPEM, 0600))
+	require.NoError(t, os.WriteFile(cfg.CertFile, client.certPEM, 0600))
+	require.NoError(t, os.WriteFile(cfg.KeyFile, client.keyPEM, 0600))
+
+	// ensure rewritten files are observed as changed even on filesystems with coarse timestamps
+	stamp := time.Now().Add(time.Duration(generation) * time.Minute)
+	for _, path := range []string{cfg.CaFile, cfg.CertFile, cfg.KeyFile} {
+		require.NoError(t, os.Chtimes(path, stamp, stamp))
+	}
+	return cfg
+}
+
+func TestCertificateReloader(t *testing.T) {
+	dir := t.TempDir()
+
+	ca1 := newTestCertificate(t, "ca-1", nil, true)
+	client1 := newTestCertificate(t, "client-1", ca1, false)
+	server1 := newTestCertificate(t, "yb.example.com", ca1, false)
+
+	cfg := writeTestCertificates(t, dir, 0, ca1, client1)
+	reloader, err := newCertificateReloader(cfg)
+	require.NoError(t, err)
+
+	cert, err := reloader.getClientCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, client1.cert.Raw, cert.Certificate[0])
+
+	verify := reloader.verifyPeerCertificate("yb.example.com")
+	assert.NoError(t, verify([][]byte{server1.cert.Raw}, nil))
+	assert.Error(t, reloader.verifyPeerCertificate("other.example.com")([][]byte{server1.cert.Raw}, nil))
+
+	reloaded, err := reloader.reload()
+	require.NoError(t, err)
+	assert.False(t, reloaded)
+
+	// rotate both the client certificate and the CA
+	ca2 := newTestCertificate(t, "ca-2", nil, true)
+	client2 := newTestCertificate(t, "client-2", ca2, false)
+	server2 := newTestCertificate(t, "yb.example.com", ca2, false)
+	writeTestCertificates(t, dir, 1, ca2, client2)
+
+	reloaded, err = reloader.reload()
+	require.NoError(t, err)
+	assert.True(t, reloaded)
+
+	cert, err = reloader.getClientCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, client2.cert.Raw, cert.Certificate[0])
+	assert.NoError(t, verify([][]byte{server2.cert.Raw}, nil))
+	assert.Error(t, verify([][]byte{server1.cert.Raw}, nil))
+}
+
+func TestCertificateReloaderKeepsMaterialOnFailure(t *testing.T) {
+	dir := t.TempDir()
+
+	ca := newTestCertificate(t, "ca", nil, true)
+	client := newTestCertificate(t, "client", ca, false)
+	cfg := writeTestCertificates(t, dir, 0, ca, client)
+
+	reloader, err := newCertificateReloader(cfg)
+	require.NoError(t, err)
+
+	// a certificate written without its matching key must not replace the working pair
+	other := newTestCertificate(t, "other", ca, false)
+	require.NoError(t, os.WriteFile(cfg.CertFile, other.certPEM, 0600))
+	stamp := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(cfg.CertFile, stamp, stamp))
+
+	_, err = reloader.reload()
+	assert.Error(t, err)
+
+	cert, err := reloader.getClientCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, client.cert.Raw, cert.Certificate[0])
+}
+
+func TestCertificateReloaderTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	ca := newTestCertificate(t, "ca", nil, true)
+	client := newTestCertificate(t, "client", ca, false)
+	cfg := writeTestCertificates(t, dir, 0, ca, client)
+
+	cluster, err := NewYugabyteCluster(ybconfig.Yugabyte{TLS: cfg}, nil)
+	require.NoError(t, err)
+
+	reloader := certificateReloaderOf(cluster)
+	require.NotNil(t, reloader)
+	assert.Nil(t, cluster.SslOpts)
+
+	config := reloader.tlsConfigForAddr("yb-1.example.com:9042")
+	assert.Equal(t, "yb-1.example.com", config.ServerName)
+	assert.True(t, config.InsecureSkipVerify)
+	assert.NotNil(t, config.VerifyPeerCertificate)
+	assert.NotNil(t, config.GetClientCertificate)
+}
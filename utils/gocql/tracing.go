@@ -0,0 +1,184 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package gocql
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/yugabyte/gocql"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tracerName = "github.com/manetu/temporal-yugabyte/utils/gocql"
+
+	dbSystem = "yugabytedb"
+)
+
+// Span attributes follow the OpenTelemetry database conventions where one exists
+const (
+	dbSystemKey              = attribute.Key("db.system")
+	dbOperationKey           = attribute.Key("db.operation.name")
+	dbNamespaceKey           = attribute.Key("db.namespace")
+	dbQuerySummaryKey        = attribute.Key("db.query.summary")
+	dbBatchSizeKey           = attribute.Key("db.operation.batch.size")
+	dbStoreKey               = attribute.Key("db.yugabyte.store")
+	dbStatementKindKey       = attribute.Key("db.yugabyte.statement")
+	dbPageSizeKey            = attribute.Key("db.yugabyte.page_size")
+	dbCASAppliedKey          = attribute.Key("db.yugabyte.cas.applied")
+	dbTransactionConflictKey = attribute.Key("db.yugabyte.transaction_conflict")
+)
+
+type (
+	// statementInfo describes a statement, transaction or batch for the purpose of instrumentation
+	statementInfo struct {
+		kind       string
+		keyspace   string
+		summary    string
+		statements int
+		pageSize   int
+		values     []interface{}
+	}
+)
+
+// startSpan starts a client span for the statement as a child of the span in ctx.  The tracer is taken from the
+// parent span so that statements are traced with the provider of the request being served, falling back to the
+// global provider.
+func startSpan(
+	ctx context.Context,
+	operation string,
+	store string,
+	info statementInfo,
+) trace.Span {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	provider := otel.GetTracerProvider()
+	if parent := trace.SpanFromContext(ctx); parent.SpanContext().IsValid() {
+		provider = parent.TracerProvider()
+	}
+
+	attrs := []attribute.KeyValue{
+		dbSystemKey.String(dbSystem),
+		dbOperationKey.String(operation),
+		dbStoreKey.String(store),
+		dbStatementKindKey.String(info.kind),
+	}
+	if info.keyspace != "" {
+		attrs = append(attrs, dbNamespaceKey.String(info.keyspace))
+	}
+	if info.summary != "" {
+		attrs = append(attrs, dbQuerySummaryKey.String(info.summary))
+	}
+	if info.statements > 1 {
+		attrs = append(attrs, dbBatchSizeKey.Int(info.statements))
+	}
+	if info.pageSize > 0 {
+		attrs = append(attrs, dbPageSizeKey.Int(info.pageSize))
+	}
+
+	_, span := provider.Tracer(tracerName).Start(ctx, operation+" "+info.kind,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	return span
+}
+
+// endSpan records the outcome of the statement on its span and ends it
+func endSpan(span trace.Span, err error, applied *bool) {
+	switch {
+	case err == nil:
+		if applied != nil {
+			span.SetAttributes(dbCASAppliedKey.Bool(*applied))
+		}
+	case errors.Is(err, gocql.ErrNotFound):
+		// an expected outcome of point reads
+	default:
+		if IsTransactionConflict(err) {
+			span.SetAttributes(dbTransactionConflictKey.Bool(true))
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// summarizeStatement names a statement template by its verb and table, e.g. "UPDATE executions", without any of its
+// values
+func summarizeStatement(stmt string) string {
+	fields := strings.Fields(stmt)
+	if len(fields) == 0 {
+		return ""
+	}
+
+	verb := strings.ToUpper(fields[0])
+	var tableAfter string
+	switch verb {
+	case "SELECT", "DELETE":
+		tableAfter = "FROM"
+	case "INSERT":
+		tableAfter = "INTO"
+	case "UPDATE":
+		if len(fields) > 1 {
+			return verb + " " + tableName(fields[1])
+		}
+		return verb
+	default:
+		return verb
+	}
+
+	for i := 1; i < len(fields)-1; i++ {
+		if strings.EqualFold(fields[i], tableAfter) {
+			return verb + " " + tableName(fields[i+1])
+		}
+	}
+	return verb
+}
+
+// summarizeStatements names a transaction or batch by the distinct templates it contains, in order
+func summarizeStatements(stmts []string) string {
+	summaries := make([]string, 0, len(stmts))
+	seen := make(map[string]struct{}, len(stmts))
+	for _, stmt := range stmts {
+		summary := summarizeStatement(stmt)
+		if _, ok := seen[summary]; ok || summary == "" {
+			continue
+		}
+		seen[summary] = struct{}{}
+		summaries = append(summaries, summary)
+	}
+	return strings.Join(summaries, "; ")
+}
+
+func tableName(field string) string {
+	if i := strings.IndexAny(field, "(;"); i != -1 {
+		field = field[:i]
+	}
+	return field
+}
@@ -0,0 +1,130 @@
+// The MIT License
+//
+// Copyright (c) 2025 Manetu Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package gocql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.temporal.io/server/common/metrics"
+)
+
+func TestSummarizeStatement(t *testing.T) {
+	tests := map[string]struct {
+		stmt     string
+		expected string
+	}{
+		"select": {
+			stmt:     "SELECT range_id FROM shards WHERE shard_id = ?",
+			expected: "SELECT shards",
+		},
+		"insert": {
+			stmt:     "INSERT INTO executions (shard_id, namespace_id) VALUES(?, ?) IF NOT EXISTS",
+			expected: "INSERT executions",
+		},
+		"insertWithoutSpace": {
+			stmt:     "INSERT INTO executions(shard_id, namespace_id) VALUES(?, ?)",
+			expected: "INSERT executions",
+		},
+		"update": {
+			stmt:     "update shards SET range_id = ? WHERE shard_id = ? IF range_id = ?",
+			expected: "UPDATE shards",
+		},
+		"delete": {
+			stmt:     "DELETE FROM tasks WHERE namespace_id = ?",
+			expected: "DELETE tasks",
+		},
+		"other": {
+			stmt:     "BEGIN TRANSACTION",
+			expected: "BEGIN",
+		},
+		"empty": {},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, summarizeStatement(tc.stmt))
+		})
+	}
+}
+
+func TestSummarizeStatements(t *testing.T) {
+	assert.Equal(t, "UPDATE executions; INSERT history_node", summarizeStatements([]string{
+		"UPDATE executions SET next_event_id = ? WHERE shard_id = ?",
+		"INSERT INTO history_node (tree_id) VALUES (?)",
+		"UPDATE executions SET state = ? WHERE shard_id = ?",
+	}))
+}
+
+func TestStatementSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "UpdateWorkflowExecution")
+
+	scope := newStatementScope("execution", metrics.NoopMetricsHandler, nil)
+	applied := false
+	scope.observe(WithOperation(ctx, "UpdateWorkflowExecution"), statementInfo{
+		kind:       txnStatementKind,
+		keyspace:   "temporal",
+		summary:    "UPDATE executions",
+		statements: 2,
+	}).done(nil, &applied)
+
+	scope.observe(WithOperation(ctx, "GetTasks"), statementInfo{
+		kind:     iterStatementKind,
+		summary:  "SELECT tasks",
+		pageSize: 100,
+	}).done(errors.New("boom"), nil)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+
+	txn := spans[0]
+	assert.Equal(t, "UpdateWorkflowExecution txn", txn.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), txn.Parent().SpanID())
+	assert.Subset(t, txn.Attributes(), []attribute.KeyValue{
+		dbOperationKey.String("UpdateWorkflowExecution"),
+		dbStoreKey.String("execution"),
+		dbNamespaceKey.String("temporal"),
+		dbQuerySummaryKey.String("UPDATE executions"),
+		dbBatchSizeKey.Int(2),
+		dbCASAppliedKey.Bool(false),
+	})
+	assert.Equal(t, codes.Unset, txn.Status().Code)
+
+	iter := spans[1]
+	assert.Equal(t, "GetTasks iter", iter.Name())
+	assert.Subset(t, iter.Attributes(), []attribute.KeyValue{
+		dbPageSizeKey.Int(100),
+	})
+	assert.Equal(t, codes.Error, iter.Status().Code)
+	assert.Len(t, iter.Events(), 1)
+}
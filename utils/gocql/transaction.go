@@ -25,42 +25,88 @@ package gocql
 import (
 	"context"
 	"strings"
+
+	"github.com/yugabyte/gocql"
 )
 
 type (
 	Txn struct {
 		session *session
+		scope   *statementScope
 		ctx     context.Context
 		stmt    []string
 		args    []interface{}
+		commit  []func()
 	}
 )
 
+func newTxn(
+	session *session,
+	scope *statementScope,
+) *Txn {
+	return &Txn{
+		session: session,
+		scope:   scope,
+		stmt:    make([]string, 0),
+		args:    make([]interface{}, 0),
+	}
+}
+
 func (b *Txn) Query(stmt string, args ...interface{}) {
 	b.stmt = append(b.stmt, stmt)
 	b.args = append(b.args, args...)
 }
 
+// OnCommit registers fn to be called once the transaction is known to have been applied
+func (b *Txn) OnCommit(fn func()) {
+	b.commit = append(b.commit, fn)
+}
+
+func (b *Txn) committed() {
+	for _, fn := range b.commit {
+		fn()
+	}
+}
+
 func (b *Txn) WithContext(ctx context.Context) *Txn {
 	b.ctx = ctx
 	return b
 }
 
 func (b *Txn) intoQuery() Query {
-	return b.session.Query("BEGIN TRANSACTION "+strings.Join(b.stmt, "; ")+"; END TRANSACTION;", b.args...).WithContext(b.ctx)
+	q := b.session.Value.Load().(*gocql.Session).Query("BEGIN TRANSACTION "+strings.Join(b.stmt, "; ")+"; END TRANSACTION;", b.args...)
+	b.scope.applyQuery(q)
+
+	txn := newQuery(b.session, b.scope, txnStatementKind, q)
+	txn.summary = summarizeStatements(b.stmt)
+	txn.statements = len(b.stmt)
+	txn.values = b.args
+	return txn.WithContext(b.ctx)
 }
 
 func (b *Txn) Exec() error {
 	q := b.intoQuery()
-	return q.Exec()
+	if err := q.Exec(); err != nil {
+		return err
+	}
+	b.committed()
+	return nil
 }
 
 func (b *Txn) ScanCAS(dest ...interface{}) (applied bool, err error) {
 	q := b.intoQuery()
-	return q.ScanCAS(dest...)
+	applied, err = q.ScanCAS(dest...)
+	if applied && err == nil {
+		b.committed()
+	}
+	return applied, err
 }
 
 func (b *Txn) MapScanCAS(dest map[string]interface{}) (applied bool, err error) {
 	q := b.intoQuery()
-	return q.MapScanCAS(dest)
+	applied, err = q.MapScanCAS(dest)
+	if applied && err == nil {
+		b.committed()
+	}
+	return applied, err
 }